@@ -0,0 +1,87 @@
+package audio
+
+import "math"
+
+const (
+	// clipLevel is the absolute sample value treated as clipped.
+	clipLevel = math.MaxInt16 - 1
+	// silenceFloorDBFS is lower than the VAD default so very quiet but real
+	// recordings are not mistaken for silence.
+	silenceFloorDBFS = -60
+)
+
+// Quality holds diagnostics describing how usable a recording is for transcription.
+type Quality struct {
+	RMSDBFS       float64 `json:"rms_dbfs" example:"-24.3"`        // Average level over the whole recording
+	PeakDBFS      float64 `json:"peak_dbfs" example:"-3.1"`        // Loudest sample
+	ClippingRatio float64 `json:"clipping_ratio" example:"0.0001"` // Fraction of samples at full scale
+	SNRDB         float64 `json:"snr_db" example:"28.5"`           // Speech level above the estimated noise floor, 0 if there is no quiet stretch to estimate it from
+	SpeechRatio   float64 `json:"speech_ratio" example:"0.72"`     // Fraction of analysis windows containing speech
+	Silent        bool    `json:"silent" example:"false"`          // True when nothing is louder than the silence floor
+}
+
+// Analyze computes quality diagnostics from the samples.
+// Speech and noise are separated with the same adaptive threshold the VAD uses.
+func Analyze(p *PCM) Quality {
+	q := Quality{
+		RMSDBFS:  rmsDBFS(p.Samples),
+		PeakDBFS: minDBFS,
+	}
+
+	var peakSample, clipped int
+	for _, s := range p.Samples {
+		v := abs(int(s))
+		peakSample = max(peakSample, v)
+		if v >= clipLevel {
+			clipped++
+		}
+	}
+	if len(p.Samples) > 0 {
+		q.PeakDBFS = toDBFS(float64(peakSample) / math.MaxInt16)
+		q.ClippingRatio = float64(clipped) / float64(len(p.Samples))
+	}
+
+	opts := DefaultVADOptions()
+	opts.FloorDBFS = silenceFloorDBFS
+	levels := FrameLevels(p, opts.FrameDuration)
+	if len(levels) == 0 {
+		q.Silent = true
+		return q
+	}
+
+	floor := noiseFloor(levels)
+	speechPower, speechFrames := speechLevels(levels, speechThreshold(levels, opts))
+	if speechFrames == 0 {
+		// Without quiet stretches, as in a clip that is speech from start to
+		// end, there is no noise floor to compare with. Anything above the
+		// absolute floor is speech then, and the SNR is unknown.
+		speechPower, speechFrames = speechLevels(levels, opts.FloorDBFS)
+		floor = math.NaN()
+	}
+
+	q.SpeechRatio = float64(speechFrames) / float64(len(levels))
+	q.Silent = speechFrames == 0
+	if speechFrames > 0 && !math.IsNaN(floor) {
+		q.SNRDB = 10*math.Log10(speechPower/float64(speechFrames)) - floor
+	}
+
+	return q
+}
+
+// speechLevels sums the power of the frames at or above threshold and counts them.
+func speechLevels(levels []float64, threshold float64) (power float64, frames int) {
+	for _, level := range levels {
+		if level >= threshold {
+			power += math.Pow(10, level/10)
+			frames++
+		}
+	}
+	return power, frames
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,66 @@
+package audio
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+// noise returns white noise with the given peak amplitude.
+func noise(d time.Duration, amplitude float64) []int16 {
+	r := rand.New(rand.NewPCG(1, 2))
+	samples := make([]int16, int(d*testRate/time.Second))
+	for i := range samples {
+		samples[i] = int16(amplitude * (2*r.Float64() - 1))
+	}
+	return samples
+}
+
+func TestAnalyzeSilence(t *testing.T) {
+	// -15 dBFS RMS is a sine peak of about 5800
+	steady := 5800.0
+	tests := []struct {
+		name       string
+		pcm        *PCM
+		silent     bool
+		speechOver float64 // SpeechRatio must exceed this
+	}{
+		{"empty", recording(), true, -1},
+		{"digital silence", recording(silence(3 * time.Second)), true, -1},
+		{"noise below the floor", recording(noise(3*time.Second, 20)), true, -1},
+		{"steady tone", recording(tone(3*time.Second, steady)), false, 0.99},
+		{"speech without pauses", recording(tone(time.Second, 3000), tone(time.Second, 9000), tone(time.Second, 5000)), false, 0.99},
+		{"speech with pauses", speech(4, time.Second, time.Second), false, 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := Analyze(tt.pcm)
+			if q.Silent != tt.silent {
+				t.Errorf("Silent = %v, want %v (%+v)", q.Silent, tt.silent, q)
+			}
+			if q.SpeechRatio <= tt.speechOver {
+				t.Errorf("SpeechRatio = %v, want more than %v", q.SpeechRatio, tt.speechOver)
+			}
+		})
+	}
+}
+
+func TestAnalyzeLevels(t *testing.T) {
+	p := speech(4, time.Second, time.Second)
+	p.Samples = append(p.Samples, math.MaxInt16, math.MinInt16+1)
+	q := Analyze(p)
+
+	if q.PeakDBFS != 0 {
+		t.Errorf("PeakDBFS = %v, want 0", q.PeakDBFS)
+	}
+	if want := 2 / float64(len(p.Samples)); q.ClippingRatio != want {
+		t.Errorf("ClippingRatio = %v, want %v", q.ClippingRatio, want)
+	}
+	if q.SNRDB < 60 {
+		t.Errorf("SNRDB = %v, want the tone far above the digital silence", q.SNRDB)
+	}
+	if q.RMSDBFS >= q.PeakDBFS || q.RMSDBFS < -30 {
+		t.Errorf("RMSDBFS = %v", q.RMSDBFS)
+	}
+}
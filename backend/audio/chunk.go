@@ -0,0 +1,72 @@
+package audio
+
+import "time"
+
+// Chunk is a piece of a longer recording prepared for separate transcription.
+type Chunk struct {
+	PCM    *PCM          // Chunk audio, including any leading overlap
+	Offset time.Duration // Position of the first frame in the original recording
+	Cut    time.Duration // Position where this chunk's own region starts (after the overlap)
+}
+
+// SplitOptions controls how a recording is divided into chunks.
+type SplitOptions struct {
+	MaxDuration time.Duration // Upper bound on each chunk, overlap included
+	Overlap     time.Duration // Audio repeated from the previous chunk on hard cuts
+	VAD         VADOptions    // Pause detection settings
+}
+
+// Split divides audio into chunks no longer than opts.MaxDuration.
+// Cuts are placed in the middle of the latest detected pause inside each window,
+// so words are not split. When a window has no usable pause the audio is cut
+// hard and the next chunk repeats opts.Overlap of audio, which the caller is
+// expected to deduplicate using Chunk.Cut.
+func Split(p *PCM, opts SplitOptions) []Chunk {
+	maxFrames := p.FrameAt(opts.MaxDuration)
+	// A limit shorter than a frame can't make progress, so it disables splitting
+	if maxFrames <= 0 || p.Frames() <= maxFrames {
+		return []Chunk{{PCM: p}}
+	}
+
+	// Never let the overlap eat more than half of a chunk
+	overlapFrames := min(p.FrameAt(opts.Overlap), maxFrames/2)
+	// Ignore pauses too close to the chunk start, they would produce tiny chunks
+	minFrames := maxFrames / 3
+	pauses := DetectPauses(p, opts.VAD)
+
+	var chunks []Chunk
+	start, cut := 0, 0
+	for {
+		end := start + maxFrames
+		if end >= p.Frames() {
+			chunks = append(chunks, newChunk(p, start, p.Frames(), cut))
+			return chunks
+		}
+
+		next, hard := end, true
+		for _, pause := range pauses {
+			mid := pause.Mid()
+			if mid > end {
+				break
+			}
+			if mid > cut+minFrames && mid > start {
+				next, hard = mid, false
+			}
+		}
+
+		chunks = append(chunks, newChunk(p, start, next, cut))
+		cut = next
+		start = next
+		if hard {
+			start = next - overlapFrames
+		}
+	}
+}
+
+func newChunk(p *PCM, start, end, cut int) Chunk {
+	return Chunk{
+		PCM:    p.Slice(start, end),
+		Offset: p.OffsetOf(start),
+		Cut:    p.OffsetOf(cut),
+	}
+}
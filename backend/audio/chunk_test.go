@@ -0,0 +1,120 @@
+package audio
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+const testRate = 16000
+
+// tone returns a 440 Hz tone of the given length and peak amplitude.
+func tone(d time.Duration, amplitude float64) []int16 {
+	samples := make([]int16, int(d*testRate/time.Second))
+	for i := range samples {
+		samples[i] = int16(amplitude * math.Sin(2*math.Pi*440*float64(i)/testRate))
+	}
+	return samples
+}
+
+// silence returns d of digital silence.
+func silence(d time.Duration) []int16 {
+	return make([]int16, int(d*testRate/time.Second))
+}
+
+// recording concatenates pieces into mono 16 kHz audio.
+func recording(pieces ...[]int16) *PCM {
+	p := &PCM{SampleRate: testRate, Channels: 1}
+	for _, piece := range pieces {
+		p.Samples = append(p.Samples, piece...)
+	}
+	return p
+}
+
+// speech alternates n bursts of tone with pauses, starting with a tone.
+func speech(n int, burst, pause time.Duration) *PCM {
+	var pieces [][]int16
+	for i := range n {
+		if i > 0 {
+			pieces = append(pieces, silence(pause))
+		}
+		pieces = append(pieces, tone(burst, 8000))
+	}
+	return recording(pieces...)
+}
+
+func TestSplitSingleChunk(t *testing.T) {
+	p := speech(3, time.Second, 500*time.Millisecond)
+	tests := []struct {
+		name string
+		max  time.Duration
+	}{
+		{"disabled", 0},
+		{"negative", -time.Second},
+		{"shorter than the audio limit", time.Minute},
+		{"shorter than a frame", 10 * time.Microsecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := Split(p, SplitOptions{MaxDuration: tt.max, Overlap: 500 * time.Millisecond})
+			if len(chunks) != 1 || chunks[0].PCM != p {
+				t.Fatalf("got %d chunks, want the whole recording", len(chunks))
+			}
+		})
+	}
+}
+
+func TestSplitAtPauses(t *testing.T) {
+	p := speech(8, 3*time.Second, 600*time.Millisecond)
+	max := 10 * time.Second
+	chunks := Split(p, SplitOptions{MaxDuration: max, Overlap: time.Second})
+	if len(chunks) < 3 {
+		t.Fatalf("got %d chunks, want at least 3", len(chunks))
+	}
+
+	var total time.Duration
+	for i, c := range chunks {
+		if d := c.PCM.Duration(); d > max {
+			t.Errorf("chunk %d is %s, longer than %s", i, d, max)
+		}
+		if c.Offset != c.Cut {
+			t.Errorf("chunk %d overlaps by %s, cuts at pauses need no overlap", i, c.Cut-c.Offset)
+		}
+		if i > 0 && c.Cut != chunks[i-1].Offset+chunks[i-1].PCM.Duration() {
+			t.Errorf("chunk %d starts at %s, previous chunk ends at %s", i, c.Cut, chunks[i-1].Offset+chunks[i-1].PCM.Duration())
+		}
+		// Every cut must fall inside a pause, never in a burst
+		burst := (c.Cut % (3600 * time.Millisecond))
+		if i > 0 && burst < 3*time.Second {
+			t.Errorf("chunk %d cut at %s, inside a burst", i, c.Cut)
+		}
+		total += c.PCM.Duration()
+	}
+	if total != p.Duration() {
+		t.Errorf("chunks add up to %s, want %s", total, p.Duration())
+	}
+}
+
+func TestSplitHardCutsOverlap(t *testing.T) {
+	p := recording(tone(25*time.Second, 8000))
+	max, overlap := 10*time.Second, time.Second
+	chunks := Split(p, SplitOptions{MaxDuration: max, Overlap: overlap})
+	if len(chunks) != 3 {
+		t.Fatalf("got %d chunks, want 3", len(chunks))
+	}
+	for i, c := range chunks {
+		if d := c.PCM.Duration(); d > max {
+			t.Errorf("chunk %d is %s, longer than %s", i, d, max)
+		}
+		if i == 0 {
+			continue
+		}
+		if c.Cut-c.Offset != overlap {
+			t.Errorf("chunk %d overlaps by %s, want %s", i, c.Cut-c.Offset, overlap)
+		}
+	}
+	last := chunks[len(chunks)-1]
+	if end := last.Offset + last.PCM.Duration(); end != p.Duration() {
+		t.Errorf("last chunk ends at %s, want %s", end, p.Duration())
+	}
+}
@@ -0,0 +1,72 @@
+package audio
+
+import "math"
+
+// butterworthQ gives a maximally flat passband for second-order filters.
+const butterworthQ = 1 / math.Sqrt2
+
+// biquad is a second-order IIR filter in direct form I.
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+}
+
+// newHighPass returns an RBJ cookbook high-pass filter with Butterworth Q.
+func newHighPass(cutoffHz float64, sampleRate int) biquad {
+	w0 := 2 * math.Pi * cutoffHz / float64(sampleRate)
+	alpha := math.Sin(w0) / (2 * butterworthQ)
+	cos := math.Cos(w0)
+	a0 := 1 + alpha
+	return biquad{
+		b0: (1 + cos) / 2 / a0,
+		b1: -(1 + cos) / a0,
+		b2: (1 + cos) / 2 / a0,
+		a1: -2 * cos / a0,
+		a2: (1 - alpha) / a0,
+	}
+}
+
+// newKWeighting returns the two ITU-R BS.1770 K-weighting stages
+// (high-shelf then high-pass) computed for the given sample rate.
+func newKWeighting(sampleRate int) [2]biquad {
+	fs := float64(sampleRate)
+
+	// Stage 1: high shelf modelling the acoustic effect of the head
+	f0, gain, q := 1681.974450955533, 3.999843853973347, 0.7071752369554196
+	k := math.Tan(math.Pi * f0 / fs)
+	vh := math.Pow(10, gain/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/q + k*k
+	shelf := biquad{
+		b0: (vh + vb*k/q + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/q + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+
+	// Stage 2: RLB high-pass
+	f0, q = 38.13547087602444, 0.5003270373238773
+	k = math.Tan(math.Pi * f0 / fs)
+	a0 = 1 + k/q + k*k
+	highPass := biquad{
+		b0: 1 / a0,
+		b1: -2 / a0,
+		b2: 1 / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+
+	return [2]biquad{shelf, highPass}
+}
+
+// apply filters one channel of interleaved samples in place.
+func (f biquad) apply(samples []float64, channel, channels int) {
+	var x1, x2, y1, y2 float64
+	for i := channel; i < len(samples); i += channels {
+		x := samples[i]
+		y := f.b0*x + f.b1*x1 + f.b2*x2 - f.a1*y1 - f.a2*y2
+		x2, x1 = x1, x
+		y2, y1 = y1, y
+		samples[i] = y
+	}
+}
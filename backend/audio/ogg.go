@@ -0,0 +1,46 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// opusGranuleRate is the fixed granule position clock of Ogg Opus streams.
+const opusGranuleRate = 48000
+
+// OggDuration reads the duration of an Ogg Vorbis or Ogg Opus file from its
+// headers and the granule position of the last page, without decoding audio.
+func OggDuration(data []byte) (time.Duration, error) {
+	if len(data) < 28 || string(data[0:4]) != "OggS" {
+		return 0, fmt.Errorf("not an Ogg file")
+	}
+
+	// The first page carries the codec identification packet
+	segments := int(data[26])
+	packet := data[27+segments:]
+
+	var rate, preSkip int64
+	switch {
+	case len(packet) >= 16 && string(packet[0:7]) == "\x01vorbis":
+		rate = int64(binary.LittleEndian.Uint32(packet[12:16]))
+	case len(packet) >= 12 && string(packet[0:8]) == "OpusHead":
+		rate = opusGranuleRate
+		preSkip = int64(binary.LittleEndian.Uint16(packet[10:12]))
+	default:
+		return 0, fmt.Errorf("unsupported Ogg codec")
+	}
+	if rate <= 0 {
+		return 0, fmt.Errorf("invalid Ogg sample rate: %d", rate)
+	}
+
+	last := bytes.LastIndex(data, []byte("OggS"))
+	if last < 0 || last+14 > len(data) {
+		return 0, fmt.Errorf("truncated Ogg file")
+	}
+	granule := int64(binary.LittleEndian.Uint64(data[last+6 : last+14]))
+	samples := max(0, granule-preSkip)
+
+	return time.Duration(samples) * time.Second / time.Duration(rate), nil
+}
@@ -0,0 +1,90 @@
+// Package audio provides decoding, segmentation and analysis of PCM audio
+// used by the Silence backend before it is sent to transcription providers.
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// PCM holds decoded 16-bit audio samples together with their format.
+// Samples are interleaved when there is more than one channel.
+type PCM struct {
+	Samples    []int16 // Interleaved signed 16-bit samples
+	SampleRate int     // Sample rate in Hz (e.g., 16000)
+	Channels   int     // Number of channels (1 = mono, 2 = stereo)
+}
+
+// DecodePCM16LE decodes raw signed 16-bit little-endian PCM data.
+// A trailing odd byte or incomplete frame is ignored.
+func DecodePCM16LE(data []byte, sampleRate, channels int) (*PCM, error) {
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate: %d", sampleRate)
+	}
+	if channels <= 0 {
+		return nil, fmt.Errorf("invalid channel count: %d", channels)
+	}
+
+	frames := len(data) / (2 * channels)
+	samples := make([]int16, frames*channels)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(data[i*2:]))
+	}
+
+	return &PCM{
+		Samples:    samples,
+		SampleRate: sampleRate,
+		Channels:   channels,
+	}, nil
+}
+
+// Frames returns the number of sample frames (samples per channel).
+func (p *PCM) Frames() int {
+	if p.Channels <= 0 {
+		return 0
+	}
+	return len(p.Samples) / p.Channels
+}
+
+// Duration returns the exact playback duration of the audio.
+func (p *PCM) Duration() time.Duration {
+	if p.SampleRate <= 0 {
+		return 0
+	}
+	return time.Duration(p.Frames()) * time.Second / time.Duration(p.SampleRate)
+}
+
+// FrameAt converts a time offset into a frame index, clamped to the audio length.
+func (p *PCM) FrameAt(offset time.Duration) int {
+	frame := int(offset * time.Duration(p.SampleRate) / time.Second)
+	return max(0, min(frame, p.Frames()))
+}
+
+// OffsetOf converts a frame index into a time offset.
+func (p *PCM) OffsetOf(frame int) time.Duration {
+	if p.SampleRate <= 0 {
+		return 0
+	}
+	return time.Duration(frame) * time.Second / time.Duration(p.SampleRate)
+}
+
+// Slice returns the frames in [start, end) as a new PCM sharing the underlying samples.
+func (p *PCM) Slice(start, end int) *PCM {
+	start = max(0, min(start, p.Frames()))
+	end = max(start, min(end, p.Frames()))
+	return &PCM{
+		Samples:    p.Samples[start*p.Channels : end*p.Channels],
+		SampleRate: p.SampleRate,
+		Channels:   p.Channels,
+	}
+}
+
+// Bytes encodes the samples as raw signed 16-bit little-endian PCM.
+func (p *PCM) Bytes() []byte {
+	data := make([]byte, len(p.Samples)*2)
+	for i, s := range p.Samples {
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(s))
+	}
+	return data
+}
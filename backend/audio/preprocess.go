@@ -0,0 +1,296 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// NormalizeMode selects how loudness normalization measures the signal.
+type NormalizeMode string
+
+const (
+	// NormalizePeak scales so the loudest sample hits the target level.
+	NormalizePeak NormalizeMode = "peak"
+	// NormalizeRMS scales so the average power hits the target level.
+	NormalizeRMS NormalizeMode = "rms"
+	// NormalizeLoudness scales so the gated, K-weighted loudness (EBU R128 style) hits the target level.
+	NormalizeLoudness NormalizeMode = "loudness"
+)
+
+// Default targets per normalization mode, in dBFS (LUFS for loudness).
+var defaultNormalizeTargets = map[NormalizeMode]float64{
+	NormalizePeak:     -1,
+	NormalizeRMS:      -20,
+	NormalizeLoudness: -23,
+}
+
+const (
+	defaultHighPassHz = 80
+	defaultGateDBFS   = -50
+	// maxNormalizeGainDB caps amplification so near-silent recordings don't turn into pure noise.
+	maxNormalizeGainDB = 30
+	// gateWindowMs is the noise gate analysis window.
+	gateWindowMs = 10
+)
+
+// PreprocessConfig describes an ordered preprocessing chain.
+// Steps run in a fixed order: DC removal, high-pass, noise gate, normalization.
+// Zero values disable the corresponding step.
+type PreprocessConfig struct {
+	RemoveDC        bool          // Subtract the mean of each channel
+	HighPassHz      float64       // High-pass cutoff in Hz, removes hum and rumble
+	GateDBFS        float64       // Mute windows quieter than this level
+	Normalize       NormalizeMode // Normalization measurement, empty to disable
+	NormalizeTarget float64       // Target level for Normalize; 0 selects the mode default
+}
+
+// Enabled reports whether any preprocessing step is configured.
+func (c PreprocessConfig) Enabled() bool {
+	return c.RemoveDC || c.HighPassHz > 0 || c.GateDBFS < 0 || c.Normalize != ""
+}
+
+// ParsePreprocessConfig parses a comma-separated list of steps, for example
+// "dc,highpass=80,gate=-50,normalize=loudness:-23". Steps without a value use
+// their defaults, "default" enables dc,highpass,normalize=loudness and
+// "none" or an empty string disables preprocessing.
+func ParsePreprocessConfig(spec string) (PreprocessConfig, error) {
+	var cfg PreprocessConfig
+	for _, step := range strings.Split(spec, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(step), "=")
+		switch strings.ToLower(name) {
+		case "", "none":
+		case "default":
+			cfg.RemoveDC = true
+			cfg.HighPassHz = defaultHighPassHz
+			cfg.Normalize = NormalizeLoudness
+		case "dc":
+			cfg.RemoveDC = true
+		case "highpass":
+			cfg.HighPassHz = defaultHighPassHz
+			if value != "" {
+				hz, err := strconv.ParseFloat(value, 64)
+				if err != nil || hz <= 0 {
+					return cfg, fmt.Errorf("invalid highpass cutoff: %q", value)
+				}
+				cfg.HighPassHz = hz
+			}
+		case "gate":
+			cfg.GateDBFS = defaultGateDBFS
+			if value != "" {
+				level, err := strconv.ParseFloat(value, 64)
+				if err != nil || level >= 0 {
+					return cfg, fmt.Errorf("invalid gate level: %q, must be negative dBFS", value)
+				}
+				cfg.GateDBFS = level
+			}
+		case "normalize":
+			mode, target, _ := strings.Cut(value, ":")
+			cfg.Normalize = NormalizeMode(strings.ToLower(mode))
+			if cfg.Normalize == "" {
+				cfg.Normalize = NormalizeLoudness
+			}
+			if _, ok := defaultNormalizeTargets[cfg.Normalize]; !ok {
+				return cfg, fmt.Errorf("invalid normalize mode: %q, valid options: peak, rms, loudness", mode)
+			}
+			if target != "" {
+				level, err := strconv.ParseFloat(target, 64)
+				if err != nil || level >= 0 {
+					return cfg, fmt.Errorf("invalid normalize target: %q, must be negative", target)
+				}
+				cfg.NormalizeTarget = level
+			}
+		default:
+			return cfg, fmt.Errorf("unknown preprocessing step: %q", name)
+		}
+	}
+	return cfg, nil
+}
+
+// Preprocess runs the configured chain and returns new audio.
+// The input is never modified, so the original recording can still be stored.
+func Preprocess(p *PCM, cfg PreprocessConfig) *PCM {
+	samples := make([]float64, len(p.Samples))
+	for i, s := range p.Samples {
+		samples[i] = float64(s) / math.MaxInt16
+	}
+
+	if cfg.RemoveDC {
+		removeDC(samples, p.Channels)
+	}
+	if cfg.HighPassHz > 0 && cfg.HighPassHz < float64(p.SampleRate)/2 {
+		hp := newHighPass(cfg.HighPassHz, p.SampleRate)
+		for ch := range p.Channels {
+			hp.apply(samples, ch, p.Channels)
+		}
+	}
+	if cfg.GateDBFS < 0 {
+		noiseGate(samples, p.Channels, max(1, p.SampleRate*gateWindowMs/1000), cfg.GateDBFS)
+	}
+	if cfg.Normalize != "" {
+		normalize(samples, p.SampleRate, p.Channels, cfg.Normalize, cfg.NormalizeTarget)
+	}
+
+	out := make([]int16, len(samples))
+	for i, v := range samples {
+		out[i] = floatToInt16(v)
+	}
+	return &PCM{
+		Samples:    out,
+		SampleRate: p.SampleRate,
+		Channels:   p.Channels,
+	}
+}
+
+// removeDC subtracts the per-channel mean.
+func removeDC(samples []float64, channels int) {
+	for ch := range channels {
+		var sum float64
+		var n int
+		for i := ch; i < len(samples); i += channels {
+			sum += samples[i]
+			n++
+		}
+		if n == 0 {
+			continue
+		}
+		mean := sum / float64(n)
+		for i := ch; i < len(samples); i += channels {
+			samples[i] -= mean
+		}
+	}
+}
+
+// noiseGate mutes windows below the threshold. Gain is ramped linearly
+// across each window so opening and closing the gate doesn't click.
+func noiseGate(samples []float64, channels, window int, thresholdDBFS float64) {
+	frames := len(samples) / channels
+	prevGain := 1.0
+	for start := 0; start < frames; start += window {
+		end := min(start+window, frames)
+		gain := 1.0
+		if toDBFS(rms(samples[start*channels:end*channels])) < thresholdDBFS {
+			gain = 0
+		}
+		for f := start; f < end; f++ {
+			g := prevGain + (gain-prevGain)*float64(f-start+1)/float64(end-start)
+			for ch := range channels {
+				samples[f*channels+ch] *= g
+			}
+		}
+		prevGain = gain
+	}
+}
+
+// normalize applies a single gain so the measured level reaches the target.
+func normalize(samples []float64, sampleRate, channels int, mode NormalizeMode, target float64) {
+	if target == 0 {
+		target = defaultNormalizeTargets[mode]
+	}
+
+	var level float64
+	switch mode {
+	case NormalizePeak:
+		level = toDBFS(peak(samples))
+	case NormalizeRMS:
+		level = toDBFS(rms(samples))
+	case NormalizeLoudness:
+		level = integratedLoudness(samples, sampleRate, channels)
+	}
+	if level <= minDBFS {
+		return
+	}
+
+	gainDB := min(target-level, maxNormalizeGainDB)
+	if mode != NormalizePeak {
+		// Never push the peak past full scale, clipping hurts recognition more than low volume
+		gainDB = min(gainDB, -toDBFS(peak(samples))-0.1)
+	}
+
+	gain := math.Pow(10, gainDB/20)
+	for i := range samples {
+		samples[i] *= gain
+	}
+}
+
+// integratedLoudness measures gated K-weighted loudness following ITU-R BS.1770:
+// 400ms blocks with 75% overlap, an absolute gate at -70 LUFS and a relative gate 10 LU below.
+func integratedLoudness(samples []float64, sampleRate, channels int) float64 {
+	weighted := append([]float64(nil), samples...)
+	for _, stage := range newKWeighting(sampleRate) {
+		for ch := range channels {
+			stage.apply(weighted, ch, channels)
+		}
+	}
+
+	frames := len(weighted) / channels
+	block := sampleRate * 400 / 1000
+	step := block / 4
+	if frames < block {
+		block, step = frames, max(1, frames)
+	}
+
+	var powers []float64
+	for start := 0; start+block <= frames; start += step {
+		// Mean square summed over channels, unit weights for mono/stereo
+		power := meanSquare(weighted[start*channels:(start+block)*channels]) * float64(channels)
+		if loudnessOf(power) > -70 {
+			powers = append(powers, power)
+		}
+	}
+	if len(powers) == 0 {
+		return minDBFS
+	}
+
+	relativeGate := loudnessOf(average(powers)) - 10
+	var gated []float64
+	for _, power := range powers {
+		if loudnessOf(power) > relativeGate {
+			gated = append(gated, power)
+		}
+	}
+	if len(gated) == 0 {
+		return minDBFS
+	}
+	return loudnessOf(average(gated))
+}
+
+// loudnessOf converts a K-weighted mean square power into LUFS.
+func loudnessOf(power float64) float64 {
+	if power <= 0 {
+		return minDBFS
+	}
+	return -0.691 + 10*math.Log10(power)
+}
+
+func meanSquare(samples []float64) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range samples {
+		sum += v * v
+	}
+	return sum / float64(len(samples))
+}
+
+func rms(samples []float64) float64 {
+	return math.Sqrt(meanSquare(samples))
+}
+
+func peak(samples []float64) float64 {
+	var p float64
+	for _, v := range samples {
+		p = max(p, math.Abs(v))
+	}
+	return p
+}
+
+func average(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
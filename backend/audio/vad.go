@@ -0,0 +1,146 @@
+package audio
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// VADOptions controls energy-based voice activity detection.
+type VADOptions struct {
+	FrameDuration time.Duration // Analysis window length (default 20ms)
+	MinSilence    time.Duration // Shortest gap that counts as a pause (default 300ms)
+	FloorDBFS     float64       // Frames quieter than this are always silence (default -50 dBFS)
+	MarginDB      float64       // Speech must exceed the estimated noise floor by this much (default 10 dB)
+}
+
+// DefaultVADOptions returns detection settings tuned for dictation over laptop microphones.
+func DefaultVADOptions() VADOptions {
+	return VADOptions{
+		FrameDuration: 20 * time.Millisecond,
+		MinSilence:    300 * time.Millisecond,
+		FloorDBFS:     -50,
+		MarginDB:      10,
+	}
+}
+
+// withDefaults fills unset options from DefaultVADOptions.
+func (opts VADOptions) withDefaults() VADOptions {
+	defaults := DefaultVADOptions()
+	if opts.FrameDuration <= 0 {
+		opts.FrameDuration = defaults.FrameDuration
+	}
+	if opts.MinSilence <= 0 {
+		opts.MinSilence = defaults.MinSilence
+	}
+	if opts.FloorDBFS == 0 {
+		opts.FloorDBFS = defaults.FloorDBFS
+	}
+	if opts.MarginDB == 0 {
+		opts.MarginDB = defaults.MarginDB
+	}
+	return opts
+}
+
+// Pause is a run of silence, expressed in sample frames [Start, End).
+type Pause struct {
+	Start int
+	End   int
+}
+
+// Mid returns the frame in the middle of the pause, the safest place to cut.
+func (p Pause) Mid() int {
+	return (p.Start + p.End) / 2
+}
+
+// Len returns the pause length in frames.
+func (p Pause) Len() int {
+	return p.End - p.Start
+}
+
+// FrameLevels returns the RMS level in dBFS of consecutive analysis windows.
+// All channels are mixed into the measurement.
+func FrameLevels(p *PCM, window time.Duration) []float64 {
+	size := max(1, p.FrameAt(window))
+	frames := p.Frames()
+	levels := make([]float64, 0, frames/size+1)
+	for start := 0; start < frames; start += size {
+		end := min(start+size, frames)
+		levels = append(levels, rmsDBFS(p.Samples[start*p.Channels:end*p.Channels]))
+	}
+	return levels
+}
+
+// DetectPauses finds silent stretches in the audio using an adaptive energy threshold.
+// The threshold sits MarginDB above the 10th percentile of frame levels, so the
+// detector follows the background noise of each recording instead of a fixed level.
+func DetectPauses(p *PCM, opts VADOptions) []Pause {
+	opts = opts.withDefaults()
+
+	levels := FrameLevels(p, opts.FrameDuration)
+	if len(levels) == 0 {
+		return nil
+	}
+
+	threshold := speechThreshold(levels, opts)
+	size := max(1, p.FrameAt(opts.FrameDuration))
+	minFrames := p.FrameAt(opts.MinSilence)
+
+	var pauses []Pause
+	runStart := -1
+	flush := func(end int) {
+		if runStart >= 0 && end-runStart >= minFrames {
+			pauses = append(pauses, Pause{Start: runStart, End: end})
+		}
+		runStart = -1
+	}
+	for i, level := range levels {
+		if level < threshold {
+			if runStart < 0 {
+				runStart = i * size
+			}
+			continue
+		}
+		flush(i * size)
+	}
+	flush(p.Frames())
+
+	return pauses
+}
+
+// speechThreshold returns the level above which a frame counts as speech.
+func speechThreshold(levels []float64, opts VADOptions) float64 {
+	return max(opts.FloorDBFS, noiseFloor(levels)+opts.MarginDB)
+}
+
+// noiseFloor estimates background noise as the 10th percentile of frame levels.
+func noiseFloor(levels []float64) float64 {
+	sorted := slices.Clone(levels)
+	slices.Sort(sorted)
+	return sorted[len(sorted)/10]
+}
+
+// rmsDBFS returns the RMS level of samples relative to full scale.
+// Digital silence is reported as -120 dBFS rather than negative infinity.
+func rmsDBFS(samples []int16) float64 {
+	if len(samples) == 0 {
+		return minDBFS
+	}
+	var sum float64
+	for _, s := range samples {
+		v := float64(s) / math.MaxInt16
+		sum += v * v
+	}
+	return toDBFS(math.Sqrt(sum / float64(len(samples))))
+}
+
+// minDBFS is the level reported for digital silence.
+const minDBFS = -120.0
+
+// toDBFS converts a linear amplitude in [0, 1] to dBFS.
+func toDBFS(amplitude float64) float64 {
+	if amplitude <= 0 {
+		return minDBFS
+	}
+	return max(minDBFS, 20*math.Log10(amplitude))
+}
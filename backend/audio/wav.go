@@ -0,0 +1,126 @@
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// WAV format tags from the fmt chunk.
+const (
+	wavFormatPCM        = 1
+	wavFormatFloat      = 3
+	wavFormatExtensible = 0xFFFE
+)
+
+// ParseWAV decodes a RIFF/WAVE file into 16-bit PCM.
+// Unlike a fixed 44-byte header skip, it walks the chunk list so files with
+// LIST/fact chunks are handled. Integer PCM of 8, 16, 24 and 32 bits and
+// 32-bit float are supported; everything is converted to 16-bit samples.
+func ParseWAV(data []byte) (*PCM, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("not a valid WAV file")
+	}
+
+	var (
+		format        uint16
+		channels      int
+		sampleRate    int
+		bitsPerSample int
+		haveFmt       bool
+	)
+
+	pos := 12
+	for pos+8 <= len(data) {
+		id := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		body := data[pos+8:]
+		if size > len(body) {
+			// Streaming writers often leave the data size unset; take what is there.
+			size = len(body)
+		}
+		body = body[:size]
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, fmt.Errorf("WAV fmt chunk too small: %d bytes", size)
+			}
+			format = binary.LittleEndian.Uint16(body[0:2])
+			channels = int(binary.LittleEndian.Uint16(body[2:4]))
+			sampleRate = int(binary.LittleEndian.Uint32(body[4:8]))
+			bitsPerSample = int(binary.LittleEndian.Uint16(body[14:16]))
+			if format == wavFormatExtensible && size >= 26 {
+				format = binary.LittleEndian.Uint16(body[24:26])
+			}
+			haveFmt = true
+		case "data":
+			if !haveFmt {
+				return nil, fmt.Errorf("WAV data chunk before fmt chunk")
+			}
+			samples, err := decodeWAVSamples(body, format, bitsPerSample)
+			if err != nil {
+				return nil, err
+			}
+			if channels <= 0 || sampleRate <= 0 {
+				return nil, fmt.Errorf("invalid WAV format: %d channels at %d Hz", channels, sampleRate)
+			}
+			samples = samples[:len(samples)/channels*channels]
+			return &PCM{
+				Samples:    samples,
+				SampleRate: sampleRate,
+				Channels:   channels,
+			}, nil
+		}
+
+		// Chunks are padded to an even size
+		pos += 8 + size + size%2
+	}
+
+	return nil, fmt.Errorf("WAV file has no data chunk")
+}
+
+// decodeWAVSamples converts the raw data chunk into 16-bit samples.
+func decodeWAVSamples(body []byte, format uint16, bitsPerSample int) ([]int16, error) {
+	switch {
+	case format == wavFormatPCM && bitsPerSample == 8:
+		samples := make([]int16, len(body))
+		for i, b := range body {
+			samples[i] = int16(int(b)-128) << 8
+		}
+		return samples, nil
+	case format == wavFormatPCM && bitsPerSample == 16:
+		samples := make([]int16, len(body)/2)
+		for i := range samples {
+			samples[i] = int16(binary.LittleEndian.Uint16(body[i*2:]))
+		}
+		return samples, nil
+	case format == wavFormatPCM && bitsPerSample == 24:
+		samples := make([]int16, len(body)/3)
+		for i := range samples {
+			samples[i] = int16(uint16(body[i*3+1]) | uint16(body[i*3+2])<<8)
+		}
+		return samples, nil
+	case format == wavFormatPCM && bitsPerSample == 32:
+		samples := make([]int16, len(body)/4)
+		for i := range samples {
+			samples[i] = int16(binary.LittleEndian.Uint32(body[i*4:]) >> 16)
+		}
+		return samples, nil
+	case format == wavFormatFloat && bitsPerSample == 32:
+		samples := make([]int16, len(body)/4)
+		for i := range samples {
+			f := math.Float32frombits(binary.LittleEndian.Uint32(body[i*4:]))
+			samples[i] = floatToInt16(float64(f))
+		}
+		return samples, nil
+	default:
+		return nil, fmt.Errorf("unsupported WAV encoding: format %d, %d bits", format, bitsPerSample)
+	}
+}
+
+// floatToInt16 converts a sample in [-1, 1] to 16-bit, clipping out-of-range values.
+func floatToInt16(f float64) int16 {
+	f = math.Round(f * math.MaxInt16)
+	return int16(max(math.MinInt16, min(math.MaxInt16, f)))
+}
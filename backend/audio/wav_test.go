@@ -0,0 +1,151 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// wavFile builds a WAV file from a fmt chunk body and data, with extra
+// chunks placed between them.
+func wavFile(format, channels uint16, rate uint32, bits uint16, data []byte, extra ...[]byte) []byte {
+	var fmtBody bytes.Buffer
+	blockAlign := channels * bits / 8
+	for _, v := range []any{format, channels, rate, rate * uint32(blockAlign), blockAlign, bits} {
+		binary.Write(&fmtBody, binary.LittleEndian, v)
+	}
+
+	var body bytes.Buffer
+	body.WriteString("WAVE")
+	writeChunk(&body, "fmt ", fmtBody.Bytes())
+	for _, chunk := range extra {
+		body.Write(chunk)
+	}
+	writeChunk(&body, "data", data)
+
+	var file bytes.Buffer
+	file.WriteString("RIFF")
+	binary.Write(&file, binary.LittleEndian, uint32(body.Len()))
+	file.Write(body.Bytes())
+	return file.Bytes()
+}
+
+func writeChunk(w *bytes.Buffer, id string, body []byte) {
+	w.WriteString(id)
+	binary.Write(w, binary.LittleEndian, uint32(len(body)))
+	w.Write(body)
+	if len(body)%2 == 1 {
+		w.WriteByte(0)
+	}
+}
+
+func chunk(id string, body []byte) []byte {
+	var b bytes.Buffer
+	writeChunk(&b, id, body)
+	return b.Bytes()
+}
+
+func le16(samples ...int16) []byte {
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, samples)
+	return b.Bytes()
+}
+
+func TestParseWAV(t *testing.T) {
+	float32s := func(values ...float32) []byte {
+		var b bytes.Buffer
+		binary.Write(&b, binary.LittleEndian, values)
+		return b.Bytes()
+	}
+
+	tests := []struct {
+		name     string
+		file     []byte
+		want     []int16
+		rate     int
+		channels int
+	}{
+		{
+			name: "16-bit mono",
+			file: wavFile(wavFormatPCM, 1, 16000, 16, le16(0, 1000, -1000, math.MaxInt16)),
+			want: []int16{0, 1000, -1000, math.MaxInt16}, rate: 16000, channels: 1,
+		},
+		{
+			name: "LIST chunk before data, odd size padded",
+			file: wavFile(wavFormatPCM, 1, 16000, 16, le16(5, 6), chunk("LIST", []byte("INFOabc"))),
+			want: []int16{5, 6}, rate: 16000, channels: 1,
+		},
+		{
+			name: "8-bit unsigned",
+			file: wavFile(wavFormatPCM, 1, 8000, 8, []byte{128, 255, 0}),
+			want: []int16{0, 127 << 8, -128 << 8}, rate: 8000, channels: 1,
+		},
+		{
+			name: "24-bit keeps the top 16 bits",
+			file: wavFile(wavFormatPCM, 1, 48000, 24, []byte{0xff, 0x34, 0x12, 0x00, 0x00, 0x80}),
+			want: []int16{0x1234, math.MinInt16}, rate: 48000, channels: 1,
+		},
+		{
+			name: "32-bit float clips",
+			file: wavFile(wavFormatFloat, 1, 16000, 32, float32s(0, 0.5, -1, 2)),
+			want: []int16{0, 16384, -math.MaxInt16, math.MaxInt16}, rate: 16000, channels: 1,
+		},
+		{
+			name: "stereo drops an incomplete frame",
+			file: wavFile(wavFormatPCM, 2, 44100, 16, le16(1, 2, 3)),
+			want: []int16{1, 2}, rate: 44100, channels: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ParseWAV(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(p.Samples, tt.want) || p.SampleRate != tt.rate || p.Channels != tt.channels {
+				t.Errorf("got %v at %d Hz, %d channels; want %v at %d Hz, %d channels", p.Samples, p.SampleRate, p.Channels, tt.want, tt.rate, tt.channels)
+			}
+		})
+	}
+}
+
+func TestParseWAVDurationExcludesHeader(t *testing.T) {
+	// One and a half seconds of 16 kHz mono: the header must not count as audio
+	p, err := ParseWAV(wavFile(wavFormatPCM, 1, 16000, 16, make([]byte, 16000*2*3/2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Duration(); got != 1500*time.Millisecond {
+		t.Errorf("duration = %s, want 1.5s", got)
+	}
+}
+
+func TestParseWAVErrors(t *testing.T) {
+	dataFirst := []byte("RIFF\x00\x00\x00\x00WAVE")
+	dataFirst = append(dataFirst, chunk("data", le16(1))...)
+
+	tests := []struct {
+		name string
+		file []byte
+		want string
+	}{
+		{"not RIFF", []byte("RIFX\x00\x00\x00\x00WAVE"), "not a valid WAV"},
+		{"too short", []byte("RIFF"), "not a valid WAV"},
+		{"no data chunk", wavFile(wavFormatPCM, 1, 16000, 16, nil)[:36], "no data chunk"},
+		{"data before fmt", dataFirst, "before fmt"},
+		{"unsupported encoding", wavFile(7, 1, 8000, 8, []byte{1}), "unsupported WAV encoding"},
+		{"zero channels", wavFile(wavFormatPCM, 0, 16000, 16, le16(1)), "invalid WAV format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseWAV(tt.file)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"silence-backend/logger"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// Scope is a permission granted to an API key.
+type Scope string
+
+const (
+	// ScopeSpeak allows transcribing audio through /speak.
+	ScopeSpeak Scope = "speak"
+	// ScopeTranscriptsRead allows listing, searching and downloading the app's own transcripts.
+	ScopeTranscriptsRead Scope = "transcripts:read"
+	// ScopeTranscriptsDelete allows deleting the app's own transcripts.
+	ScopeTranscriptsDelete Scope = "transcripts:delete"
+	// ScopeUsageRead allows reading the app's own usage and cost.
+	ScopeUsageRead Scope = "usage:read"
+)
+
+// Scopes lists every scope an API key can be granted.
+var Scopes = []Scope{ScopeSpeak, ScopeTranscriptsRead, ScopeTranscriptsDelete, ScopeUsageRead}
+
+// APIKeysCollection holds hashed API keys, each belonging to a record of the apps collection.
+const APIKeysCollection = "api_keys"
+
+const (
+	// apiKeyPrefix marks Silence API keys, which tells them apart from PocketBase auth tokens.
+	apiKeyPrefix = "slc_"
+	// apiKeySecretLength is the number of random characters after the prefix.
+	apiKeySecretLength = 40
+	// apiKeyDisplayLength is how much of a key is stored in clear to recognise it.
+	apiKeyDisplayLength = len(apiKeyPrefix) + 8
+	// lastUsedInterval limits how often a key's last_used_at is written.
+	lastUsedInterval = time.Minute
+)
+
+// ParseScopes parses a comma-separated list of scopes.
+func ParseScopes(spec string) ([]Scope, error) {
+	var scopes []Scope
+	for _, part := range strings.Split(spec, ",") {
+		scope := Scope(strings.TrimSpace(part))
+		if scope == "" {
+			continue
+		}
+		if !slices.Contains(Scopes, scope) {
+			return nil, fmt.Errorf("invalid scope: %q, valid options: speak, transcripts:read, transcripts:delete, usage:read", scope)
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+	return scopes, nil
+}
+
+// HashAPIKey returns the hex SHA-256 of a key, which is all that is stored.
+// Keys are long random strings, so a fast unsalted hash is enough.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIKey generates a key for an app and stores its hash. The returned
+// plaintext key is not stored anywhere and can't be recovered later.
+func CreateAPIKey(app core.App, appID, name string, scopes []Scope) (*core.Record, string, error) {
+	collection, err := app.FindCollectionByNameOrId(APIKeysCollection)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := apiKeyPrefix + security.RandomString(apiKeySecretLength)
+
+	record := core.NewRecord(collection)
+	record.Set("app", appID)
+	record.Set("name", name)
+	record.Set("prefix", key[:apiKeyDisplayLength])
+	record.Set("key_hash", HashAPIKey(key))
+	record.Set("scopes", scopes)
+
+	if err := app.Save(record); err != nil {
+		return nil, "", fmt.Errorf("save api key: %w", err)
+	}
+
+	return record, key, nil
+}
+
+// RevokeAPIKey marks a key as revoked. Revoked keys are kept for auditing.
+func RevokeAPIKey(app core.App, record *core.Record) error {
+	if !record.GetDateTime("revoked_at").IsZero() {
+		return nil
+	}
+	record.Set("revoked_at", types.NowDateTime())
+	return app.Save(record)
+}
+
+// authorizeAPIKey validates an API key and its scope, returning the key's app.
+// The error is the API error to send back.
+func authorizeAPIKey(e *core.RequestEvent, key string, scope Scope) (*core.Record, error) {
+	record, err := e.App.FindFirstRecordByData(APIKeysCollection, "key_hash", HashAPIKey(key))
+	if err != nil || !record.GetDateTime("revoked_at").IsZero() {
+		return nil, e.UnauthorizedError("The API key is invalid or revoked.", nil)
+	}
+
+	if !slices.Contains(record.GetStringSlice("scopes"), string(scope)) {
+		return nil, e.ForbiddenError(fmt.Sprintf("The API key lacks the %q scope.", scope), nil)
+	}
+
+	appRecord, err := e.App.FindRecordById("apps", record.GetString("app"))
+	if err != nil {
+		return nil, e.UnauthorizedError("The API key is invalid or revoked.", nil)
+	}
+
+	touchAPIKey(e.App, record)
+	return appRecord, nil
+}
+
+// touchAPIKey records when a key was last used, at most once per lastUsedInterval.
+func touchAPIKey(app core.App, record *core.Record) {
+	lastUsed := record.GetDateTime("last_used_at")
+	if !lastUsed.IsZero() && time.Since(lastUsed.Time()) < lastUsedInterval {
+		return
+	}
+
+	record.Set("last_used_at", types.NowDateTime())
+	if err := app.Save(record); err != nil {
+		logger.Error("Failed to update API key last use", "key_id", record.Id, "error", err)
+	}
+}
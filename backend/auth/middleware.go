@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+)
+
+// UsersCollection is the PocketBase auth collection of end users.
+const UsersCollection = "users"
+
+// requestAppKey is where RequireCaller stores the calling app on the request.
+const requestAppKey = "silenceApp"
+
+// RequireCaller lets a request through when it is made by one of:
+//   - a superuser, acting on every app and user;
+//   - a user of the users collection with a PocketBase auth token, acting on
+//     their own transcripts only;
+//   - an app with a valid, unrevoked API key that has the given scope, acting
+//     on that app's transcripts only.
+//
+// Both kinds of credentials are sent as "Authorization: Bearer". The caller is
+// then available through RequestUser and RequestApp.
+func RequireCaller(scope Scope) *hook.Handler[*core.RequestEvent] {
+	return &hook.Handler[*core.RequestEvent]{
+		Id: "silenceRequireCaller",
+		Func: func(e *core.RequestEvent) error {
+			// PocketBase has already resolved auth tokens, API keys are left unresolved
+			if e.Auth != nil {
+				if e.Auth.IsSuperuser() || e.Auth.Collection().Name == UsersCollection {
+					return e.Next()
+				}
+				return e.ForbiddenError("The authorized record is not allowed to perform this action.", nil)
+			}
+
+			key := strings.TrimPrefix(e.Request.Header.Get("Authorization"), "Bearer ")
+			if !strings.HasPrefix(key, apiKeyPrefix) {
+				return e.UnauthorizedError("The request requires a user auth token or a valid API key.", nil)
+			}
+
+			appRecord, err := authorizeAPIKey(e, key, scope)
+			if err != nil {
+				return err
+			}
+
+			e.Set(requestAppKey, appRecord)
+			return e.Next()
+		},
+	}
+}
+
+// RequestApp returns the app whose API key authorized the request, or nil for
+// users, superusers and requests without a key.
+func RequestApp(e *core.RequestEvent) *core.Record {
+	app, _ := e.Get(requestAppKey).(*core.Record)
+	return app
+}
+
+// RequestUser returns the end user who made the request, or nil for API keys
+// and superusers.
+func RequestUser(e *core.RequestEvent) *core.Record {
+	if e.Auth != nil && e.Auth.Collection().Name == UsersCollection {
+		return e.Auth
+	}
+	return nil
+}
@@ -26,6 +26,7 @@ func main() {
 	// Define command-line flags
 	backendURL := flag.String("url", "http://localhost:8090", "Backend URL to send audio to")
 	provider := flag.String("provider", "", "Transcription provider: 'elevenlabs' or 'chutes' (empty for default chain)")
+	apiKey := flag.String("key", os.Getenv("SILENCE_API_KEY"), "App API key sent as a Bearer token (default $SILENCE_API_KEY)")
 	flag.Parse()
 
 	var pcmData []byte
@@ -70,7 +71,7 @@ func main() {
 	}
 
 	// Send to backend API
-	err = sendToBackend(pcmData, *backendURL, *provider)
+	err = sendToBackend(pcmData, *backendURL, *provider, *apiKey)
 	if err != nil {
 		log.Fatal("Failed to send to backend:", err)
 	}
@@ -209,7 +210,7 @@ func readWAVFile(filePath string) ([]byte, error) {
 	return pcmData, nil
 }
 
-func sendToBackend(pcmData []byte, backendURL string, provider string) error {
+func sendToBackend(pcmData []byte, backendURL string, provider string, apiKey string) error {
 	// Create multipart form
 	var buf bytes.Buffer
 	writer := multipart.NewWriter(&buf)
@@ -258,6 +259,9 @@ func sendToBackend(pcmData []byte, backendURL string, provider string) error {
 	}
 
 	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
 
 	// Send request
 	client := &http.Client{Timeout: 30 * time.Second}
@@ -1,76 +1,180 @@
 package compression
 
 import (
-	"bytes"
+	"context"
 	"fmt"
-	"os/exec"
+	"io"
+	"strconv"
+
+	"silence-backend/ffmpeg"
+	"silence-backend/transcription"
 )
 
-// CompressWAVToOGG compresses WAV audio data to OGG format using ffmpeg
-// OGG provides good compression ratio with reasonable quality loss
-func CompressWAVToOGG(wavData []byte) ([]byte, error) {
-	// Create ffmpeg command to convert WAV to OGG with aggressive compression
-	cmd := exec.Command("ffmpeg",
-		"-f", "wav", // Input format: WAV
-		"-i", "pipe:0", // Input from stdin
-		"-f", "ogg", // Output format: OGG
-		"-c:a", "libvorbis", // Audio codec: Vorbis
-		"-q:a", "2", // Quality: 2 (lower = more compression, less quality)
-		"-ac", "1", // Mono audio (reduces size)
-		"-ar", "16000", // Sample rate: 16kHz (reduces size)
-		"pipe:1", // Output to stdout
-	)
-
-	// Set up pipes
-	cmd.Stdin = bytes.NewReader(wavData)
-
-	var outBuf bytes.Buffer
-	var errBuf bytes.Buffer
-	cmd.Stdout = &outBuf
-	cmd.Stderr = &errBuf
-
-	// Execute the command
-	err := cmd.Run()
+// Codec identifies the format audio is stored in.
+type Codec string
+
+const (
+	// CodecOpus stores Opus in an Ogg container, the best quality per bit for speech.
+	CodecOpus Codec = "opus"
+	// CodecVorbis stores Vorbis in an Ogg container.
+	CodecVorbis Codec = "vorbis"
+	// CodecMP3 stores MP3 for maximum player compatibility.
+	CodecMP3 Codec = "mp3"
+	// CodecFLAC stores lossless FLAC at the original sample rate for archival.
+	CodecFLAC Codec = "flac"
+)
+
+// Settings tune the encoder. Empty values select per-codec defaults.
+type Settings struct {
+	Bitrate string // Target bitrate for Opus and MP3, e.g. "24k"
+	Quality string // Vorbis quality (-q:a) or FLAC compression level
+}
+
+// codecSpec describes how ffmpeg produces a codec.
+type codecSpec struct {
+	encoder        string
+	muxer          string
+	contentType    string
+	extension      string
+	lossless       bool
+	defaultBitrate string
+	defaultQuality string
+}
+
+var codecSpecs = map[Codec]codecSpec{
+	CodecOpus:   {encoder: "libopus", muxer: "ogg", contentType: "audio/ogg", extension: ".opus", defaultBitrate: "24k"},
+	CodecVorbis: {encoder: "libvorbis", muxer: "ogg", contentType: "audio/ogg", extension: ".ogg", defaultQuality: "2"},
+	CodecMP3:    {encoder: "libmp3lame", muxer: "mp3", contentType: "audio/mpeg", extension: ".mp3", defaultBitrate: "32k"},
+	CodecFLAC:   {encoder: "flac", muxer: "flac", contentType: "audio/flac", extension: ".flac", lossless: true, defaultQuality: "8"},
+}
+
+// ParseCodec validates a codec name.
+func ParseCodec(name string) (Codec, error) {
+	codec := Codec(name)
+	if _, ok := codecSpecs[codec]; !ok {
+		return "", fmt.Errorf("unsupported codec: %q, valid options: opus, vorbis, mp3, flac", name)
+	}
+	return codec, nil
+}
+
+// ContentType returns the MIME type of audio stored with this codec.
+func (c Codec) ContentType() string {
+	return codecSpecs[c].contentType
+}
+
+// Extension returns the file extension, including the dot, for this codec.
+func (c Codec) Extension() string {
+	return codecSpecs[c].extension
+}
+
+// Compressor encodes uploaded audio for storage.
+type Compressor interface {
+	// Compress streams audio described by metadata from src and writes the encoded result to dst.
+	Compress(ctx context.Context, src io.Reader, dst io.Writer, metadata transcription.AudioMetadata) error
+	// Codec returns the codec the output is encoded with.
+	Codec() Codec
+}
+
+// FFmpegCompressor encodes audio by piping it through the shared ffmpeg runner.
+type FFmpegCompressor struct {
+	runner   *ffmpeg.Runner
+	codec    Codec
+	settings Settings
+}
+
+// NewFFmpegCompressor creates a compressor for the given codec.
+func NewFFmpegCompressor(runner *ffmpeg.Runner, codec Codec, settings Settings) (*FFmpegCompressor, error) {
+	spec, ok := codecSpecs[codec]
+	if !ok {
+		return nil, fmt.Errorf("unsupported codec: %q", codec)
+	}
+	if settings.Bitrate == "" {
+		settings.Bitrate = spec.defaultBitrate
+	}
+	if settings.Quality == "" {
+		settings.Quality = spec.defaultQuality
+	}
+	return &FFmpegCompressor{
+		runner:   runner,
+		codec:    codec,
+		settings: settings,
+	}, nil
+}
+
+// Codec returns the codec the compressor produces.
+func (c *FFmpegCompressor) Codec() Codec {
+	return c.codec
+}
+
+// Compress encodes audio with ffmpeg, streaming src to its stdin and its stdout to dst.
+func (c *FFmpegCompressor) Compress(ctx context.Context, src io.Reader, dst io.Writer, metadata transcription.AudioMetadata) error {
+	args, err := c.args(metadata)
 	if err != nil {
-		return nil, fmt.Errorf("ffmpeg compression failed: %w, stderr: %s", err, errBuf.String())
+		return err
 	}
 
-	return outBuf.Bytes(), nil
+	if err := c.runner.Run(ctx, args, src, dst); err != nil {
+		return fmt.Errorf("ffmpeg compression failed: %w", err)
+	}
+	return nil
 }
 
-// CompressWAVToMP3 compresses WAV audio data to MP3 format using ffmpeg
-// Alternative compression method if OGG is not preferred
-func CompressWAVToMP3(wavData []byte) ([]byte, error) {
-	// Create ffmpeg command to convert WAV to MP3 with aggressive compression
-	cmd := exec.Command("ffmpeg",
-		"-f", "wav", // Input format: WAV
-		"-i", "pipe:0", // Input from stdin
-		"-f", "mp3", // Output format: MP3
-		"-c:a", "libmp3lame", // Audio codec: LAME MP3
-		"-b:a", "32k", // Bitrate: 32kbps (very compressed)
-		"-ac", "1", // Mono audio
-		"-ar", "16000", // Sample rate: 16kHz
-		"pipe:1", // Output to stdout
-	)
-
-	// Set up pipes
-	cmd.Stdin = bytes.NewReader(wavData)
-
-	var outBuf bytes.Buffer
-	var errBuf bytes.Buffer
-	cmd.Stdout = &outBuf
-	cmd.Stderr = &errBuf
-
-	// Execute the command
-	err := cmd.Run()
+// Transcode re-encodes audio that is already stored in any container ffmpeg
+// can probe, such as a previous compressor's output.
+func (c *FFmpegCompressor) Transcode(ctx context.Context, src io.Reader, dst io.Writer) error {
+	args := append([]string{"-i", "pipe:0"}, c.outputArgs()...)
+	if err := c.runner.Run(ctx, args, src, dst); err != nil {
+		return fmt.Errorf("ffmpeg transcoding failed: %w", err)
+	}
+	return nil
+}
+
+// args builds the ffmpeg command line for the input format and codec.
+func (c *FFmpegCompressor) args(metadata transcription.AudioMetadata) ([]string, error) {
+	args, err := inputArgs(metadata)
 	if err != nil {
-		return nil, fmt.Errorf("ffmpeg compression failed: %w, stderr: %s", err, errBuf.String())
+		return nil, err
 	}
+	return append(args, c.outputArgs()...), nil
+}
 
-	return outBuf.Bytes(), nil
+// outputArgs selects the encoder, its settings and the output container.
+func (c *FFmpegCompressor) outputArgs() []string {
+	spec := codecSpecs[c.codec]
+	args := []string{"-c:a", spec.encoder}
+	switch c.codec {
+	case CodecOpus:
+		args = append(args, "-b:a", c.settings.Bitrate, "-application", "voip")
+	case CodecVorbis:
+		args = append(args, "-q:a", c.settings.Quality)
+	case CodecMP3:
+		args = append(args, "-b:a", c.settings.Bitrate)
+	case CodecFLAC:
+		args = append(args, "-compression_level", c.settings.Quality)
+	}
+
+	// Lossy codecs are downmixed to 16kHz mono, which is all speech recognition needs
+	if !spec.lossless {
+		args = append(args, "-ac", "1", "-ar", "16000")
+	}
+
+	return append(args, "-f", spec.muxer, "pipe:1")
 }
 
-// CompressAudio compresses audio data using the default compression method (OGG)
-func CompressAudio(wavData []byte) ([]byte, error) {
-	return CompressWAVToOGG(wavData)
+// inputArgs tells ffmpeg how to read the upload. Raw PCM has no header,
+// so its sample format, rate and channel count must be given explicitly.
+func inputArgs(metadata transcription.AudioMetadata) ([]string, error) {
+	switch metadata.Format {
+	case transcription.AudioFormatPCMLE16:
+		return []string{
+			"-f", "s16le",
+			"-ar", strconv.Itoa(metadata.SampleRate),
+			"-ac", strconv.Itoa(metadata.Channels),
+			"-i", "pipe:0",
+		}, nil
+	case transcription.AudioFormatWAV:
+		return []string{"-f", "wav", "-i", "pipe:0"}, nil
+	default:
+		return nil, fmt.Errorf("unsupported input format for compression: %s", metadata.Format)
+	}
 }
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"strings"
+
+	"silence-backend/logger"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// SearchTable is the FTS5 index over silence.result.
+const SearchTable = "silence_fts"
+
+// RegisterSearchIndex keeps the full-text index in sync with the silence collection.
+// Index failures are logged rather than returned: the record itself is already saved
+// and a stale index entry is better than failing the request.
+func RegisterSearchIndex(app core.App) {
+	app.OnRecordAfterCreateSuccess("silence").BindFunc(func(e *core.RecordEvent) error {
+		indexTranscript(e.App, e.Record)
+		return e.Next()
+	})
+
+	app.OnRecordAfterUpdateSuccess("silence").BindFunc(func(e *core.RecordEvent) error {
+		indexTranscript(e.App, e.Record)
+		return e.Next()
+	})
+
+	app.OnRecordAfterDeleteSuccess("silence").BindFunc(func(e *core.RecordEvent) error {
+		if err := removeFromIndex(e.App, e.Record.Id); err != nil {
+			logger.Error("Failed to remove transcript from search index", "record_id", e.Record.Id, "error", err)
+		}
+		return e.Next()
+	})
+}
+
+// indexTranscript replaces the index entry of a record.
+func indexTranscript(app core.App, record *core.Record) {
+	err := app.RunInTransaction(func(txApp core.App) error {
+		if err := removeFromIndex(txApp, record.Id); err != nil {
+			return err
+		}
+		text := record.GetString("result")
+		if text == "" {
+			return nil
+		}
+		_, err := txApp.DB().Insert(SearchTable, dbx.Params{"id": record.Id, "result": text}).Execute()
+		return err
+	})
+	if err != nil {
+		logger.Error("Failed to index transcript", "record_id", record.Id, "error", err)
+	}
+}
+
+func removeFromIndex(app core.App, id string) error {
+	_, err := app.DB().Delete(SearchTable, dbx.HashExp{"id": id}).Execute()
+	return err
+}
+
+// SearchQuery turns free text into an FTS5 query matching all of its words.
+// Every word is quoted so punctuation in user input can't break the query syntax;
+// a trailing '*' keeps prefix matching ("transcri*").
+func SearchQuery(text string) string {
+	var terms []string
+	for _, word := range strings.Fields(text) {
+		prefix := strings.HasSuffix(word, "*")
+		word = strings.TrimRight(word, "*")
+		if word == "" {
+			continue
+		}
+		term := `"` + strings.ReplaceAll(word, `"`, `""`) + `"`
+		if prefix {
+			term += "*"
+		}
+		terms = append(terms, term)
+	}
+	return strings.Join(terms, " ")
+}
@@ -0,0 +1,31 @@
+package database
+
+import (
+	"silence-backend/logger"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// ConfigureS3 points PocketBase file storage at an S3-compatible bucket.
+// Without a bucket the settings are left alone, so local storage (or S3
+// configured through the dashboard) keeps working.
+func ConfigureS3(app core.App, s3 core.S3Config) error {
+	if s3.Bucket == "" {
+		return nil
+	}
+
+	s3.Enabled = true
+	settings := app.Settings()
+	if settings.S3 == s3 {
+		return nil
+	}
+
+	settings.S3 = s3
+	if err := app.Save(settings); err != nil {
+		logger.Error("Failed to configure S3 storage", "bucket", s3.Bucket, "error", err)
+		return err
+	}
+
+	logger.Info("S3 storage configured", "bucket", s3.Bucket, "endpoint", s3.Endpoint)
+	return nil
+}
@@ -23,9 +23,58 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/speak": {
+        "/admin/apps/{id}/keys": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the API keys of an app, including revoked ones, without their secret values.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List app API keys",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "App ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "API keys",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIKeyListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid auth token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "App not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Accepts audio in multipart/form-data format and returns transcribed text using the configured transcription provider. Supports both PCM and WAV formats.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Generates a new API key for an app. The plaintext key is returned only in this response; store it securely. Send it as 'Authorization: Bearer \u003ckey\u003e'.",
                 "consumes": [
                     "multipart/form-data"
                 ],
@@ -33,81 +82,1661 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Audio"
+                    "Admin"
                 ],
-                "summary": "Transcribe audio",
+                "summary": "Create an app API key",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "Audio file (PCM or WAV format, max 32MB)",
-                        "name": "audio",
-                        "in": "formData",
+                        "type": "string",
+                        "description": "App ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Audio format: 'pcm_s16le_16' or 'wav'. Defaults to 'pcm_s16le_16' for lower latency. Use pcm_s16le_16 for 16-bit PCM at 16kHz, mono, little-endian.",
-                        "name": "file_format",
+                        "description": "Label to recognise the key, e.g. the client using it",
+                        "name": "name",
                         "in": "formData"
                     },
                     {
                         "type": "string",
-                        "description": "ISO-639-1 or ISO-639-3 language code. Use 'auto' or omit for auto-detection. Examples: 'en', 'es', 'fr'",
-                        "name": "language_code",
+                        "description": "Comma-separated scopes: 'speak', 'transcripts:read', 'transcripts:delete', 'usage:read' (default 'speak')",
+                        "name": "scopes",
                         "in": "formData"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Transcription successful",
+                    "201": {
+                        "description": "New API key",
                         "schema": {
-                            "$ref": "#/definitions/handlers.SuccessResponse"
+                            "$ref": "#/definitions/handlers.CreatedAPIKey"
                         }
                     },
                     "400": {
-                        "description": "Bad request (invalid format, empty audio, etc.)",
+                        "description": "Invalid scopes",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid auth token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "App not found",
                         "schema": {
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handlers.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string",
-                    "example": "Invalid audio format"
-                },
-                "timestamp": {
-                    "type": "integer",
-                    "example": 1629840000
+        },
+        "/admin/apps/{id}/keys/{keyId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revokes an API key. It stops working immediately but stays listed for auditing.",
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Revoke an app API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "App ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "API key ID",
+                        "name": "keyId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "API key revoked"
+                    },
+                    "401": {
+                        "description": "Missing or invalid auth token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "App or API key not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "handlers.SuccessResponse": {
-            "type": "object",
-            "properties": {
-                "audio_length": {
-                    "type": "integer",
-                    "example": 15
-                },
-                "language_code": {
-                    "type": "string",
-                    "example": "en"
-                },
-                "text": {
-                    "type": "string",
-                    "example": "Hello world, this is a transcription"
-                },
-                "timestamp": {
-                    "type": "integer",
-                    "example": 1629840000
+        "/admin/apps/{id}/keys/{keyId}/rotate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revokes an API key and returns a replacement with the same name and scopes. The old key stops working immediately.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Rotate an app API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "App ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "API key ID",
+                        "name": "keyId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Replacement API key",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreatedAPIKey"
+                        }
+                    },
+                    "400": {
+                        "description": "API key already revoked",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid auth token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "App or API key not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/audio/backfill": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Queues every transcript in 'audio_failed' status for another attempt at compressing and attaching its audio, using the raw upload kept in persist_failures. Successful retries remove the dead letter and set the status to 'stored'. Stops early when the queue is full; call again to continue.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Retry failed audio storage",
+                "responses": {
+                    "200": {
+                        "description": "Backfill summary",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BackfillResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid auth token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to read dead letters",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/failures/{id}/replay": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sends the stored audio of a failed request to a provider again, with the original language, preprocessing, postprocessing and vocabulary. The app's current vocabulary is added, and its postprocessing applies when the request didn't ask for any. The outcome is saved on the failure as 'last_replay'. With 'save=true', a successful replay is also stored as a transcript and linked to the failure.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Replay a failed transcription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Failure ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provider to replay against: 'elevenlabs' or 'chutes'. Omit to use the default provider chain.",
+                        "name": "provider",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Override the originally requested language code",
+                        "name": "language_code",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Store a successful replay as a transcript",
+                        "name": "save",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Replay outcome, successful or not",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReplayResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid provider, save flag or stored audio",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid auth token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Failure not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/queue": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the depth and counters of the background queue that compresses and stores transcripts. A growing depth means storage can't keep up with requests; failed jobs end up in the persist_failures collection.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Persistence queue metrics",
+                "responses": {
+                    "200": {
+                        "description": "Queue metrics",
+                        "schema": {
+                            "$ref": "#/definitions/queue.Stats"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid auth token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
                 }
             }
+        },
+        "/admin/retention": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Runs the retention policy as a dry run and lists the transcripts, audio files and dead-letter uploads the next scheduled cleanup would delete or downsample. Nothing is modified.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Preview retention cleanup",
+                "responses": {
+                    "200": {
+                        "description": "Dry-run report",
+                        "schema": {
+                            "$ref": "#/definitions/retention.Report"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid auth token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to evaluate retention policy",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/speak": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Accepts audio in multipart/form-data format and returns transcribed text using the configured transcription provider. Supports both PCM and WAV formats. Requires a user auth token or an app API key with the 'speak' scope; the transcript is owned by that user or attributed to that app. Options a request made with an API key leaves out come from the app's transcription profile (default_provider, default_language, vocabulary, preprocess, postprocess, output_format); the profile can also restrict the allowed languages and discard the audio after transcription.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "Audio"
+                ],
+                "summary": "Transcribe audio",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Audio file (PCM or WAV format, max 32MB)",
+                        "name": "audio",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Audio format: 'pcm_s16le_16' or 'wav'. Defaults to 'pcm_s16le_16' for lower latency. Use pcm_s16le_16 for 16-bit PCM at 16kHz, mono, little-endian.",
+                        "name": "file_format",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO-639-1 or ISO-639-3 language code. Use 'auto' for auto-detection; omit for the app's default language or auto-detection. Examples: 'en', 'es', 'fr'",
+                        "name": "language_code",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Transcription provider: 'elevenlabs' or 'chutes'. Omit to use the app's default provider, or the default provider chain with fallback.",
+                        "name": "provider",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Optional name of the calling client, stored with the transcript",
+                        "name": "client",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated preprocessing steps applied before transcription: 'dc', 'highpass[=hz]', 'gate[=dbfs]', 'normalize[=peak|rms|loudness[:target]]', 'default' or 'none'. Omit to use the app's or the server default. The stored audio is never modified.",
+                        "name": "preprocess",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated text processing steps applied after transcription, in order: 'cleanup', 'itn' (spoken numbers, dates and units to written form), 'fillers', 'profanity[=mask]', 'case[=sentence|lower|upper]', 'dictation' (spoken commands such as 'new line', 'comma' or 'scratch that', said on their own between pauses, to formatting; put it before 'itn'), or 'none'; a JSON array of steps also configures 'replace' dictionaries and extra 'dictation' commands. Omit to use the app's or the server default. Each step is reported in the response, along with the provider's raw text.",
+                        "name": "postprocess",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated names and jargon to bias transcription towards, added to the app's vocabulary. Passed to providers that support biasing (ElevenLabs keyterms), otherwise close spellings in the text are corrected to these terms; everyday words are left alone.",
+                        "name": "vocabulary",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Match the transcript against the commands apps declare in their description and return the matched app, command and slots as 'intent'. The matched app's webhook, if it has one, is called before responding.",
+                        "name": "route",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Response format: 'json' (default) or 'text' for the transcript only, as text/plain. Omit to use the app's default.",
+                        "name": "output_format",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Transcription successful",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request (invalid format, language not allowed for the app, empty or silent audio, etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing auth token, or invalid or revoked API key",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key lacks the 'speak' scope",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit, concurrency limit or audio quota exceeded; see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transcripts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns stored transcripts, newest first, without audio. Use 'next_cursor' from the response as 'cursor' to fetch the next page.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transcripts"
+                ],
+                "summary": "List transcripts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only transcripts created at or after this time (RFC 3339 or YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only transcripts created before this time (RFC 3339 or YYYY-MM-DD, exclusive)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Detected language code, e.g. 'en'",
+                        "name": "language",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provider that produced the transcript: 'elevenlabs' or 'chutes'",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the app the transcript is attributed to",
+                        "name": "app",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the user who owns the transcript (ignored for user tokens, which only see their own)",
+                        "name": "owner",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Audio storage status: 'pending_audio', 'stored', 'audio_failed', 'discarded', 'expired' or 'legacy_audio_failed'",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1-100 (default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from a previous response",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "'compact' (default) or 'full' to include request details",
+                        "name": "view",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of transcripts",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TranscriptListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid auth token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transcripts/search": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Full-text search over transcript text, ranked by relevance (BM25, lower rank is better). All words must match; end a word with '*' for prefix matching. The 'snippet' is HTML-escaped text with matches highlighted in \u003cmark\u003e tags. Accepts the same filters as the transcript listing.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transcripts"
+                ],
+                "summary": "Search transcripts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search text, e.g. 'pricing meeting'",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only transcripts created at or after this time (RFC 3339 or YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only transcripts created before this time (RFC 3339 or YYYY-MM-DD, exclusive)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Detected language code, e.g. 'en'",
+                        "name": "language",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provider that produced the transcript: 'elevenlabs' or 'chutes'",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the app the transcript is attributed to",
+                        "name": "app",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the user who owns the transcript (ignored for user tokens, which only see their own)",
+                        "name": "owner",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Audio storage status: 'pending_audio', 'stored', 'audio_failed', 'discarded', 'expired' or 'legacy_audio_failed'",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1-100 (default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of results to skip, from 'next_offset'",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Matching transcripts",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TranscriptSearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing query or invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid auth token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transcripts/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns one transcript with its full request details. The audio is available at 'audio_url'.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transcripts"
+                ],
+                "summary": "Get a transcript",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Transcript ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Transcript",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Transcript"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid auth token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Transcript not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes a transcript together with its stored audio file.",
+                "tags": [
+                    "Transcripts"
+                ],
+                "summary": "Delete a transcript",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Transcript ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Transcript deleted"
+                    },
+                    "401": {
+                        "description": "Missing or invalid auth token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Transcript not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transcripts/{id}/audio": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Streams the stored recording of a transcript with its codec's content type. Supports HTTP Range requests for seeking. Add '?download=1' to force a file download.",
+                "produces": [
+                    "audio/ogg",
+                    "audio/mpeg",
+                    "audio/flac"
+                ],
+                "tags": [
+                    "Transcripts"
+                ],
+                "summary": "Download transcript audio",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Transcript ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Audio file",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "206": {
+                        "description": "Requested byte range",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid auth token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Transcript or audio not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/usage": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Aggregates billable audio and estimated provider cost of successful transcriptions, grouped by any of day, app, user and provider. Costs are estimates from the configured price table. Users see only their own usage and API keys only their app's.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Usage"
+                ],
+                "summary": "Usage and estimated cost",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated grouping: 'day', 'app', 'user', 'provider' (default 'day')",
+                        "name": "group_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only usage at or after this time (RFC 3339 or YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only usage before this time (RFC 3339 or YYYY-MM-DD, exclusive)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only usage of this app",
+                        "name": "app",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only usage of this user",
+                        "name": "user",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only usage of this provider: 'elevenlabs' or 'chutes'",
+                        "name": "provider",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Aggregated usage",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.UsageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid grouping or filter",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid auth token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key lacks the 'usage:read' scope",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "audio.Quality": {
+            "type": "object",
+            "properties": {
+                "clipping_ratio": {
+                    "description": "Fraction of samples at full scale",
+                    "type": "number",
+                    "example": 0.0001
+                },
+                "peak_dbfs": {
+                    "description": "Loudest sample",
+                    "type": "number",
+                    "example": -3.1
+                },
+                "rms_dbfs": {
+                    "description": "Average level over the whole recording",
+                    "type": "number",
+                    "example": -24.3
+                },
+                "silent": {
+                    "description": "True when nothing is louder than the silence floor",
+                    "type": "boolean",
+                    "example": false
+                },
+                "snr_db": {
+                    "description": "Speech level above the estimated noise floor, 0 if there is no quiet stretch to estimate it from",
+                    "type": "number",
+                    "example": 28.5
+                },
+                "speech_ratio": {
+                    "description": "Fraction of analysis windows containing speech",
+                    "type": "number",
+                    "example": 0.72
+                }
+            }
+        },
+        "handlers.APIKey": {
+            "type": "object",
+            "properties": {
+                "app": {
+                    "type": "string",
+                    "example": "k2l3m4n5o6p7q8r"
+                },
+                "created": {
+                    "type": "string",
+                    "example": "2026-10-18 13:31:13.352Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "q1w2e3r4t5y6u7i"
+                },
+                "last_used_at": {
+                    "type": "string",
+                    "example": "2026-10-18 13:31:13.352Z"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "ios client"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "slc_Ab3dEf9h"
+                },
+                "revoked_at": {
+                    "type": "string",
+                    "example": ""
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "speak"
+                    ]
+                }
+            }
+        },
+        "handlers.APIKeyListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.APIKey"
+                    }
+                }
+            }
+        },
+        "handlers.BackfillResponse": {
+            "type": "object",
+            "properties": {
+                "queued": {
+                    "description": "Jobs handed to the persistence queue",
+                    "type": "integer",
+                    "example": 12
+                },
+                "remaining": {
+                    "description": "Failures left for a later call because the queue filled up",
+                    "type": "integer",
+                    "example": 0
+                },
+                "skipped": {
+                    "description": "Failures whose raw audio couldn't be read",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "handlers.CreatedAPIKey": {
+            "type": "object",
+            "properties": {
+                "app": {
+                    "type": "string",
+                    "example": "k2l3m4n5o6p7q8r"
+                },
+                "created": {
+                    "type": "string",
+                    "example": "2026-10-18 13:31:13.352Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "q1w2e3r4t5y6u7i"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "slc_Ab3dEf9hIjKlMnOpQrStUvWxYz0123456789AbCd"
+                },
+                "last_used_at": {
+                    "type": "string",
+                    "example": "2026-10-18 13:31:13.352Z"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "ios client"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "slc_Ab3dEf9h"
+                },
+                "revoked_at": {
+                    "type": "string",
+                    "example": ""
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "speak"
+                    ]
+                }
+            }
+        },
+        "handlers.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Invalid audio format"
+                },
+                "timestamp": {
+                    "type": "integer",
+                    "example": 1629840000
+                }
+            }
+        },
+        "handlers.ReplayResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "all providers failed, last error: provider 2 failed: status 503"
+                },
+                "failed_attempts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/transcription.Attempt"
+                    }
+                },
+                "failure_id": {
+                    "type": "string",
+                    "example": "f8k2l3m4n5o6p7q"
+                },
+                "language_code": {
+                    "type": "string",
+                    "example": "en"
+                },
+                "postprocess": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/postprocess.Applied"
+                    }
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "chutes"
+                },
+                "raw_text": {
+                    "type": "string",
+                    "example": "hello world comma this is a transcription"
+                },
+                "replayed_at": {
+                    "type": "string",
+                    "example": "2026-10-18 13:31:13.352Z"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Hello world, this is a transcription"
+                },
+                "transcribe_ms": {
+                    "type": "integer",
+                    "example": 1840
+                },
+                "transcript_id": {
+                    "type": "string",
+                    "example": "ead6abyjn82q49r"
+                }
+            }
+        },
+        "handlers.SuccessResponse": {
+            "type": "object",
+            "properties": {
+                "audio_length": {
+                    "type": "number",
+                    "example": 14.52
+                },
+                "audio_length_ms": {
+                    "type": "integer",
+                    "example": 14520
+                },
+                "id": {
+                    "type": "string",
+                    "example": "ead6abyjn82q49r"
+                },
+                "intent": {
+                    "description": "App command the text matched, null if none; only with route=true",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/intent.Match"
+                        }
+                    ]
+                },
+                "language_code": {
+                    "type": "string",
+                    "example": "en"
+                },
+                "postprocess": {
+                    "description": "Text processing steps, in the order they ran",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/postprocess.Applied"
+                    }
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "elevenlabs"
+                },
+                "quality": {
+                    "$ref": "#/definitions/audio.Quality"
+                },
+                "raw_text": {
+                    "description": "Provider's text before postprocessing",
+                    "type": "string",
+                    "example": "hello world comma this is a transcription"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Hello world, this is a transcription"
+                },
+                "timestamp": {
+                    "type": "integer",
+                    "example": 1629840000
+                }
+            }
+        },
+        "handlers.Transcript": {
+            "type": "object",
+            "properties": {
+                "app": {
+                    "type": "string",
+                    "example": "k2l3m4n5o6p7q8r"
+                },
+                "audio_url": {
+                    "type": "string",
+                    "example": "/transcripts/ead6abyjn82q49r/audio"
+                },
+                "created": {
+                    "type": "string",
+                    "example": "2026-10-18 13:31:13.352Z"
+                },
+                "details": {
+                    "$ref": "#/definitions/handlers.TranscriptDetails"
+                },
+                "duration_ms": {
+                    "type": "integer",
+                    "example": 14520
+                },
+                "id": {
+                    "type": "string",
+                    "example": "ead6abyjn82q49r"
+                },
+                "language": {
+                    "type": "string",
+                    "example": "en"
+                },
+                "owner": {
+                    "type": "string",
+                    "example": "u9v8w7x6y5z4a3b"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "elevenlabs"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending_audio",
+                        "stored",
+                        "audio_failed",
+                        "discarded",
+                        "expired",
+                        "legacy_audio_failed"
+                    ],
+                    "example": "stored"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Hello world, this is a transcription"
+                }
+            }
+        },
+        "handlers.TranscriptDetails": {
+            "type": "object",
+            "properties": {
+                "channels": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "client": {
+                    "type": "object"
+                },
+                "codec": {
+                    "type": "string",
+                    "example": "opus"
+                },
+                "compress_ms": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "failed_attempts": {
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "input_format": {
+                    "type": "string",
+                    "example": "pcm_s16le_16"
+                },
+                "preprocess": {
+                    "type": "string",
+                    "example": "default"
+                },
+                "quality": {
+                    "type": "object"
+                },
+                "requested_language": {
+                    "type": "string",
+                    "example": "auto"
+                },
+                "sample_rate": {
+                    "type": "integer",
+                    "example": 16000
+                },
+                "size_bytes": {
+                    "type": "integer",
+                    "example": 464640
+                },
+                "transcribe_ms": {
+                    "type": "integer",
+                    "example": 1840
+                },
+                "updated": {
+                    "type": "string",
+                    "example": "2026-10-18 13:31:13.352Z"
+                },
+                "upload_ms": {
+                    "type": "integer",
+                    "example": 35
+                }
+            }
+        },
+        "handlers.TranscriptListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.Transcript"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "MjAyNi0xMC0xOCAxMzozMToxMy4zNTJafGVhZDZhYnlqbjgycTQ5cg"
+                }
+            }
+        },
+        "handlers.TranscriptSearchResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.TranscriptSearchResult"
+                    }
+                },
+                "next_offset": {
+                    "type": "integer",
+                    "example": 20
+                }
+            }
+        },
+        "handlers.TranscriptSearchResult": {
+            "type": "object",
+            "properties": {
+                "app": {
+                    "type": "string",
+                    "example": "k2l3m4n5o6p7q8r"
+                },
+                "audio_url": {
+                    "type": "string",
+                    "example": "/transcripts/ead6abyjn82q49r/audio"
+                },
+                "created": {
+                    "type": "string",
+                    "example": "2026-10-18 13:31:13.352Z"
+                },
+                "details": {
+                    "$ref": "#/definitions/handlers.TranscriptDetails"
+                },
+                "duration_ms": {
+                    "type": "integer",
+                    "example": 14520
+                },
+                "id": {
+                    "type": "string",
+                    "example": "ead6abyjn82q49r"
+                },
+                "language": {
+                    "type": "string",
+                    "example": "en"
+                },
+                "owner": {
+                    "type": "string",
+                    "example": "u9v8w7x6y5z4a3b"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "elevenlabs"
+                },
+                "rank": {
+                    "type": "number",
+                    "example": -4.21
+                },
+                "snippet": {
+                    "type": "string",
+                    "example": "…what did I say about \u003cmark\u003epricing\u003c/mark\u003e last week…"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending_audio",
+                        "stored",
+                        "audio_failed",
+                        "discarded",
+                        "expired",
+                        "legacy_audio_failed"
+                    ],
+                    "example": "stored"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Hello world, this is a transcription"
+                }
+            }
+        },
+        "handlers.UsageResponse": {
+            "type": "object",
+            "properties": {
+                "group_by": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "day",
+                        "provider"
+                    ]
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.UsageRow"
+                    }
+                },
+                "total": {
+                    "$ref": "#/definitions/handlers.UsageRow"
+                }
+            }
+        },
+        "handlers.UsageRow": {
+            "type": "object",
+            "properties": {
+                "app": {
+                    "type": "string",
+                    "example": "k2l3m4n5o6p7q8r"
+                },
+                "audio_seconds": {
+                    "type": "number",
+                    "example": 1830.5
+                },
+                "billable_seconds": {
+                    "type": "number",
+                    "example": 1852
+                },
+                "cost_usd": {
+                    "type": "number",
+                    "example": 0.2057
+                },
+                "day": {
+                    "type": "string",
+                    "example": "2026-10-18"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "elevenlabs"
+                },
+                "requests": {
+                    "description": "Billed provider requests; a chunked transcription makes one per chunk",
+                    "type": "integer",
+                    "example": 42
+                },
+                "user": {
+                    "type": "string",
+                    "example": "u9v8w7x6y5z4a3b"
+                }
+            }
+        },
+        "intent.Match": {
+            "type": "object",
+            "properties": {
+                "app": {
+                    "type": "string",
+                    "example": "k3j4h5g6f7d8s9a"
+                },
+                "app_name": {
+                    "type": "string",
+                    "example": "Tasks"
+                },
+                "command": {
+                    "type": "string",
+                    "example": "create_task"
+                },
+                "score": {
+                    "description": "1 for an exact match",
+                    "type": "number",
+                    "example": 0.93
+                },
+                "slots": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "trigger": {
+                    "type": "string",
+                    "example": "remind me to {task} at {time}"
+                },
+                "webhook": {
+                    "description": "Outcome of calling the app's webhook, if it has one",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/intent.WebhookResult"
+                        }
+                    ]
+                }
+            }
+        },
+        "intent.WebhookResult": {
+            "type": "object",
+            "properties": {
+                "duration_ms": {
+                    "type": "integer",
+                    "example": 84
+                },
+                "error": {
+                    "type": "string",
+                    "example": "webhook returned status 500"
+                },
+                "status_code": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "postprocess.Applied": {
+            "type": "object",
+            "properties": {
+                "changed": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "step": {
+                    "type": "string",
+                    "example": "fillers"
+                },
+                "text": {
+                    "description": "Text after the step, if it changed",
+                    "type": "string",
+                    "example": "So I think we should ship it"
+                }
+            }
+        },
+        "queue.Stats": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Jobs being run or waiting for a retry",
+                    "type": "integer",
+                    "example": 1
+                },
+                "capacity": {
+                    "description": "Maximum depth",
+                    "type": "integer",
+                    "example": 100
+                },
+                "closed": {
+                    "description": "Shutdown has started",
+                    "type": "boolean",
+                    "example": false
+                },
+                "completed": {
+                    "description": "Jobs that succeeded",
+                    "type": "integer",
+                    "example": 1244
+                },
+                "depth": {
+                    "description": "Jobs waiting for a worker",
+                    "type": "integer",
+                    "example": 3
+                },
+                "enqueued": {
+                    "description": "Jobs accepted since start",
+                    "type": "integer",
+                    "example": 1250
+                },
+                "failed": {
+                    "description": "Jobs handed to Fail",
+                    "type": "integer",
+                    "example": 2
+                },
+                "rejected": {
+                    "description": "Jobs refused because the queue was full or shut down",
+                    "type": "integer",
+                    "example": 0
+                },
+                "retries": {
+                    "description": "Extra runs after failures",
+                    "type": "integer",
+                    "example": 7
+                },
+                "workers": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "retention.Item": {
+            "type": "object",
+            "properties": {
+                "app": {
+                    "type": "string",
+                    "example": "k2l3m4n5o6p7q8r"
+                },
+                "created": {
+                    "type": "string",
+                    "example": "2025-09-01 10:00:00.000Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "ead6abyjn82q49r"
+                }
+            }
+        },
+        "retention.Report": {
+            "type": "object",
+            "properties": {
+                "audio_deleted": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/retention.Item"
+                    }
+                },
+                "audio_downsampled": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/retention.Item"
+                    }
+                },
+                "dead_letter_audio_deleted": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/retention.Item"
+                    }
+                },
+                "dry_run": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "failure_audio_deleted": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/retention.Item"
+                    }
+                },
+                "started_at": {
+                    "type": "string",
+                    "example": "2026-10-18 03:30:00.000Z"
+                },
+                "transcripts_deleted": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/retention.Item"
+                    }
+                }
+            }
+        },
+        "transcription.Attempt": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "ElevenLabs API error (status 429): rate limited"
+                },
+                "provider": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/transcription.ProviderName"
+                        }
+                    ],
+                    "example": "elevenlabs"
+                }
+            }
+        },
+        "transcription.ProviderName": {
+            "type": "string",
+            "enum": [
+                "elevenlabs",
+                "chutes"
+            ],
+            "x-enum-varnames": [
+                "ProviderElevenLabs",
+                "ProviderChutes"
+            ]
+        }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "PocketBase user or superuser auth token, or an app API key (\"slc_...\"), optionally prefixed with \"Bearer \"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
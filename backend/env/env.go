@@ -3,6 +3,11 @@ package env
 import (
 	"log"
 	"os"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
 )
 
 type Env struct {
@@ -10,6 +15,56 @@ type Env struct {
 	ChutesAPIToken   string
 	SilenceEmail     string
 	SilencePassword  string
+
+	// Long recordings are split into chunks of at most ChunkMaxDuration
+	ChunkMaxDuration time.Duration
+	ChunkOverlap     time.Duration
+	ChunkConcurrency int
+
+	// Default audio preprocessing chain, e.g. "dc,highpass=80,normalize=loudness"
+	Preprocess string
+
+	// Default text postprocessing pipeline, e.g. "cleanup,fillers,case=sentence"
+	Postprocess string
+
+	// Codec and encoder settings for stored audio
+	StorageCodec   string
+	StorageBitrate string
+	StorageQuality string
+
+	// Shared ffmpeg process pool
+	FFmpegPath         string
+	FFmpegMaxProcesses int
+	FFmpegTimeout      time.Duration
+
+	// Retention in days, 0 keeps data forever. Apps can override both periods.
+	// The audio period also covers uploads kept by failed requests.
+	RetentionAudioDays      int
+	RetentionTextDays       int
+	RetentionAudioAction    string
+	RetentionSchedule       string
+	RetentionDownsampleRate string
+
+	// Background persistence queue for transcripts and audio
+	PersistWorkers         int
+	PersistQueueSize       int
+	PersistMaxAttempts     int
+	PersistRetryBackoff    time.Duration
+	PersistShutdownTimeout time.Duration
+
+	// Default limits per app and user, 0 is unlimited. Apps can override them.
+	LimitRequestsPerMinute   int
+	LimitDailyAudioMinutes   int
+	LimitMonthlyAudioMinutes int
+	LimitMaxConcurrent       int
+
+	// Provider prices as JSON, merged over the defaults, and the optional spend budget in USD
+	PriceTable       string
+	BudgetDailyUSD   float64
+	BudgetMonthlyUSD float64
+
+	// Optional S3-compatible storage for audio files, local storage if S3_BUCKET is empty
+	S3 core.S3Config
 }
 
 func Load() *Env {
@@ -26,10 +81,122 @@ func Load() *Env {
 	silenceEmail := os.Getenv("SILENCE_EMAIL")
 	silencePassword := os.Getenv("SILENCE_PASSWORD")
 
+	// Chunks must hold more than the overlap they repeat, or splitting can't advance
+	chunkMaxDuration := getDuration("CHUNK_MAX_DURATION", 25*time.Second)
+	chunkOverlap := getDuration("CHUNK_OVERLAP", 500*time.Millisecond)
+	if chunkOverlap < 0 {
+		log.Fatal("CHUNK_OVERLAP must not be negative")
+	}
+	if chunkMaxDuration > 0 && chunkMaxDuration <= chunkOverlap {
+		log.Fatalf("CHUNK_MAX_DURATION (%s) must be longer than CHUNK_OVERLAP (%s), or 0 to disable chunking", chunkMaxDuration, chunkOverlap)
+	}
+
 	return &Env{
 		ElevenlabsAPIKey: elevenlabsAPIKey,
 		ChutesAPIToken:   chutesAPIToken,
 		SilenceEmail:     silenceEmail,
 		SilencePassword:  silencePassword,
+		ChunkMaxDuration: chunkMaxDuration,
+		ChunkOverlap:     chunkOverlap,
+		ChunkConcurrency: getInt("CHUNK_CONCURRENCY", 4),
+		Preprocess:       os.Getenv("AUDIO_PREPROCESS"),
+		Postprocess:      os.Getenv("TEXT_POSTPROCESS"),
+		StorageCodec:     getString("STORAGE_CODEC", "vorbis"),
+		StorageBitrate:   os.Getenv("STORAGE_BITRATE"),
+		StorageQuality:   os.Getenv("STORAGE_QUALITY"),
+
+		FFmpegPath:         getString("FFMPEG_PATH", "ffmpeg"),
+		FFmpegMaxProcesses: getInt("FFMPEG_MAX_PROCESSES", runtime.NumCPU()),
+		FFmpegTimeout:      getDuration("FFMPEG_TIMEOUT", 60*time.Second),
+
+		RetentionAudioDays:      getInt("RETENTION_AUDIO_DAYS", 0),
+		RetentionTextDays:       getInt("RETENTION_TEXT_DAYS", 0),
+		RetentionAudioAction:    getString("RETENTION_AUDIO_ACTION", "delete"),
+		RetentionSchedule:       getString("RETENTION_SCHEDULE", "30 3 * * *"),
+		RetentionDownsampleRate: getString("RETENTION_DOWNSAMPLE_BITRATE", "8k"),
+
+		PersistWorkers:         getInt("PERSIST_WORKERS", 2),
+		PersistQueueSize:       getInt("PERSIST_QUEUE_SIZE", 100),
+		PersistMaxAttempts:     getInt("PERSIST_MAX_ATTEMPTS", 5),
+		PersistRetryBackoff:    getDuration("PERSIST_RETRY_BACKOFF", time.Second),
+		PersistShutdownTimeout: getDuration("PERSIST_SHUTDOWN_TIMEOUT", 30*time.Second),
+
+		LimitRequestsPerMinute:   getInt("LIMIT_REQUESTS_PER_MINUTE", 0),
+		LimitDailyAudioMinutes:   getInt("LIMIT_DAILY_AUDIO_MINUTES", 0),
+		LimitMonthlyAudioMinutes: getInt("LIMIT_MONTHLY_AUDIO_MINUTES", 0),
+		LimitMaxConcurrent:       getInt("LIMIT_MAX_CONCURRENT", 0),
+
+		PriceTable:       os.Getenv("PRICE_TABLE"),
+		BudgetDailyUSD:   getFloat("BUDGET_DAILY_USD", 0),
+		BudgetMonthlyUSD: getFloat("BUDGET_MONTHLY_USD", 0),
+
+		S3: core.S3Config{
+			Bucket:         os.Getenv("S3_BUCKET"),
+			Region:         os.Getenv("S3_REGION"),
+			Endpoint:       os.Getenv("S3_ENDPOINT"),
+			AccessKey:      os.Getenv("S3_ACCESS_KEY"),
+			Secret:         os.Getenv("S3_SECRET"),
+			ForcePathStyle: getBool("S3_FORCE_PATH_STYLE", false),
+		},
+	}
+}
+
+// getString reads a string from the environment.
+func getString(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getDuration reads a Go duration string (e.g. "25s") from the environment.
+func getDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("%s must be a duration like 25s: %v", key, err)
+	}
+	return d
+}
+
+// getBool reads a boolean ("true", "1", "false", "0") from the environment.
+func getBool(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Fatalf("%s must be true or false: %v", key, err)
+	}
+	return b
+}
+
+// getInt reads an integer from the environment.
+func getInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Fatalf("%s must be an integer: %v", key, err)
+	}
+	return n
+}
+
+// getFloat reads a decimal number from the environment.
+func getFloat(key string, fallback float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Fatalf("%s must be a number: %v", key, err)
 	}
+	return f
 }
@@ -0,0 +1,188 @@
+// Package ffmpeg runs ffmpeg processes with timeouts and a shared concurrency limit,
+// so bursts of requests cannot fork an unbounded number of encoders.
+package ffmpeg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// maxStderr bounds how much ffmpeg diagnostic output is kept for error reports.
+const maxStderr = 4096
+
+// Config controls the shared runner.
+type Config struct {
+	Binary       string        // Path or name of the ffmpeg executable (default "ffmpeg")
+	MaxProcesses int           // Maximum ffmpeg processes running at once (default 1)
+	Timeout      time.Duration // Upper bound on a single run, including time spent waiting for a slot (default 60s)
+}
+
+// Runner executes ffmpeg with a semaphore-limited process pool.
+// It is safe for concurrent use.
+type Runner struct {
+	binary  string
+	timeout time.Duration
+	slots   chan struct{}
+	version atomic.Value
+
+	running atomic.Int64
+	waiting atomic.Int64
+}
+
+// Error describes a failed ffmpeg run.
+type Error struct {
+	Args     []string      // Arguments passed to ffmpeg
+	ExitCode int           // Process exit code, -1 if it never exited normally
+	Stderr   string        // Tail of ffmpeg's diagnostic output
+	Duration time.Duration // Time from process start to failure
+	TimedOut bool          // True when the run was killed because its context expired
+	Err      error         // Underlying error
+}
+
+func (e *Error) Error() string {
+	reason := e.Err.Error()
+	if e.TimedOut {
+		reason = "timed out"
+	}
+	msg := fmt.Sprintf("ffmpeg failed (exit code %d): %s", e.ExitCode, reason)
+	if e.Stderr != "" {
+		msg += ", stderr: " + e.Stderr
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NewRunner creates a runner. Zero config values select defaults.
+func NewRunner(cfg Config) *Runner {
+	if cfg.Binary == "" {
+		cfg.Binary = "ffmpeg"
+	}
+	if cfg.MaxProcesses <= 0 {
+		cfg.MaxProcesses = 1
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 60 * time.Second
+	}
+	return &Runner{
+		binary:  cfg.Binary,
+		timeout: cfg.Timeout,
+		slots:   make(chan struct{}, cfg.MaxProcesses),
+	}
+}
+
+// Check verifies that ffmpeg can be executed and returns its version string.
+func (r *Runner) Check(ctx context.Context) (string, error) {
+	path, err := exec.LookPath(r.binary)
+	if err != nil {
+		return "", fmt.Errorf("ffmpeg not found: %w", err)
+	}
+
+	var out bytes.Buffer
+	if err := r.Run(ctx, []string{"-version"}, nil, &out); err != nil {
+		return "", err
+	}
+
+	// First line looks like "ffmpeg version 6.1.1 Copyright ..."
+	line, _, _ := strings.Cut(out.String(), "\n")
+	version := strings.TrimPrefix(line, "ffmpeg version ")
+	version, _, _ = strings.Cut(version, " ")
+	r.version.Store(version)
+
+	return fmt.Sprintf("%s (%s)", version, path), nil
+}
+
+// Version returns the version found by the last successful Check, or "".
+func (r *Runner) Version() string {
+	v, _ := r.version.Load().(string)
+	return v
+}
+
+// Running returns the number of ffmpeg processes currently executing.
+func (r *Runner) Running() int {
+	return int(r.running.Load())
+}
+
+// Waiting returns the number of runs queued for a free process slot.
+func (r *Runner) Waiting() int {
+	return int(r.waiting.Load())
+}
+
+// Run executes ffmpeg with args, streaming stdin into the process and its
+// output into stdout. It blocks until a process slot is free, and the whole
+// call is bounded by the runner timeout or ctx, whichever ends first.
+func (r *Runner) Run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	r.waiting.Add(1)
+	select {
+	case r.slots <- struct{}{}:
+		r.waiting.Add(-1)
+	case <-ctx.Done():
+		r.waiting.Add(-1)
+		return &Error{Args: args, ExitCode: -1, TimedOut: true, Err: fmt.Errorf("waiting for ffmpeg slot: %w", ctx.Err())}
+	}
+	defer func() { <-r.slots }()
+
+	r.running.Add(1)
+	defer r.running.Add(-1)
+
+	// Keep the banner and progress noise out of stderr so errors stay readable
+	fullArgs := append([]string{"-hide_banner", "-loglevel", "error"}, args...)
+	cmd := exec.CommandContext(ctx, r.binary, fullArgs...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	stderr := &tailBuffer{limit: maxStderr}
+	cmd.Stderr = stderr
+	// Give ffmpeg a moment to flush after being signalled before it is killed
+	cmd.WaitDelay = 5 * time.Second
+
+	start := time.Now()
+	err := cmd.Run()
+	if err == nil {
+		return nil
+	}
+
+	exitCode := -1
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitCode()
+	}
+
+	return &Error{
+		Args:     args,
+		ExitCode: exitCode,
+		Stderr:   strings.TrimSpace(stderr.String()),
+		Duration: time.Since(start),
+		TimedOut: ctx.Err() != nil,
+		Err:      err,
+	}
+}
+
+// tailBuffer keeps only the last limit bytes written to it.
+type tailBuffer struct {
+	limit int
+	buf   []byte
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - t.limit; over > 0 {
+		t.buf = t.buf[over:]
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	return string(t.buf)
+}
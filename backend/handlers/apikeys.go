@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"silence-backend/auth"
+	"silence-backend/logger"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// APIKey describes an app's API key. The key itself is only returned once, on creation.
+type APIKey struct {
+	ID         string   `json:"id" example:"q1w2e3r4t5y6u7i"`
+	App        string   `json:"app" example:"k2l3m4n5o6p7q8r"`
+	Name       string   `json:"name" example:"ios client"`
+	Prefix     string   `json:"prefix" example:"slc_Ab3dEf9h"`
+	Scopes     []string `json:"scopes" example:"speak"`
+	LastUsedAt string   `json:"last_used_at,omitempty" example:"2026-10-18 13:31:13.352Z"`
+	RevokedAt  string   `json:"revoked_at,omitempty" example:""`
+	Created    string   `json:"created" example:"2026-10-18 13:31:13.352Z"`
+}
+
+// CreatedAPIKey is a new API key together with its plaintext value.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key" example:"slc_Ab3dEf9hIjKlMnOpQrStUvWxYz0123456789AbCd"`
+}
+
+// APIKeyListResponse lists an app's API keys, newest first.
+type APIKeyListResponse struct {
+	Items []APIKey `json:"items"`
+}
+
+// HandleListAPIKeys godoc
+// @Summary List app API keys
+// @Description Lists the API keys of an app, including revoked ones, without their secret values.
+// @Tags Admin
+// @Produce json
+// @Param id path string true "App ID"
+// @Success 200 {object} APIKeyListResponse "API keys"
+// @Failure 401 {object} ErrorResponse "Missing or invalid auth token"
+// @Failure 404 {object} ErrorResponse "App not found"
+// @Security BearerAuth
+// @Router /admin/apps/{id}/keys [get]
+func HandleListAPIKeys(re *core.RequestEvent, app core.App) error {
+	appRecord, err := app.FindRecordById("apps", re.Request.PathValue("id"))
+	if err != nil {
+		return sendJSONErrorStatus(re, http.StatusNotFound, "app not found")
+	}
+
+	records, err := app.FindRecordsByFilter(auth.APIKeysCollection, "app = {:app}", "-created", 0, 0, dbx.Params{"app": appRecord.Id})
+	if err != nil {
+		logger.Error("Failed to list API keys", "app", appRecord.Id, "error", err)
+		return sendJSONErrorStatus(re, http.StatusInternalServerError, "failed to list API keys")
+	}
+
+	response := APIKeyListResponse{Items: make([]APIKey, len(records))}
+	for i, record := range records {
+		response.Items[i] = newAPIKey(record)
+	}
+	return sendJSON(re, http.StatusOK, response)
+}
+
+// HandleCreateAPIKey godoc
+// @Summary Create an app API key
+// @Description Generates a new API key for an app. The plaintext key is returned only in this response; store it securely. Send it as 'Authorization: Bearer <key>'.
+// @Tags Admin
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "App ID"
+// @Param name formData string false "Label to recognise the key, e.g. the client using it"
+// @Param scopes formData string false "Comma-separated scopes: 'speak', 'transcripts:read', 'transcripts:delete', 'usage:read' (default 'speak')"
+// @Success 201 {object} CreatedAPIKey "New API key"
+// @Failure 400 {object} ErrorResponse "Invalid scopes"
+// @Failure 401 {object} ErrorResponse "Missing or invalid auth token"
+// @Failure 404 {object} ErrorResponse "App not found"
+// @Security BearerAuth
+// @Router /admin/apps/{id}/keys [post]
+func HandleCreateAPIKey(re *core.RequestEvent, app core.App) error {
+	appRecord, err := app.FindRecordById("apps", re.Request.PathValue("id"))
+	if err != nil {
+		return sendJSONErrorStatus(re, http.StatusNotFound, "app not found")
+	}
+
+	spec := re.Request.FormValue("scopes")
+	if spec == "" {
+		spec = string(auth.ScopeSpeak)
+	}
+	scopes, err := auth.ParseScopes(spec)
+	if err != nil {
+		return sendJSONError(re, err.Error())
+	}
+
+	return createAPIKey(re, app, appRecord.Id, strings.TrimSpace(re.Request.FormValue("name")), scopes)
+}
+
+// HandleRotateAPIKey godoc
+// @Summary Rotate an app API key
+// @Description Revokes an API key and returns a replacement with the same name and scopes. The old key stops working immediately.
+// @Tags Admin
+// @Produce json
+// @Param id path string true "App ID"
+// @Param keyId path string true "API key ID"
+// @Success 201 {object} CreatedAPIKey "Replacement API key"
+// @Failure 400 {object} ErrorResponse "API key already revoked"
+// @Failure 401 {object} ErrorResponse "Missing or invalid auth token"
+// @Failure 404 {object} ErrorResponse "App or API key not found"
+// @Security BearerAuth
+// @Router /admin/apps/{id}/keys/{keyId}/rotate [post]
+func HandleRotateAPIKey(re *core.RequestEvent, app core.App) error {
+	record, err := findAPIKey(re, app)
+	if err != nil {
+		return sendJSONErrorStatus(re, http.StatusNotFound, "API key not found")
+	}
+	if !record.GetDateTime("revoked_at").IsZero() {
+		return sendJSONError(re, "API key is already revoked")
+	}
+
+	scopes := make([]auth.Scope, 0, len(record.GetStringSlice("scopes")))
+	for _, scope := range record.GetStringSlice("scopes") {
+		scopes = append(scopes, auth.Scope(scope))
+	}
+
+	if err := auth.RevokeAPIKey(app, record); err != nil {
+		logger.Error("Failed to revoke API key", "key_id", record.Id, "error", err)
+		return sendJSONErrorStatus(re, http.StatusInternalServerError, "failed to revoke API key")
+	}
+	logger.Info("API key revoked for rotation", "key_id", record.Id, "app", record.GetString("app"))
+
+	return createAPIKey(re, app, record.GetString("app"), record.GetString("name"), scopes)
+}
+
+// HandleRevokeAPIKey godoc
+// @Summary Revoke an app API key
+// @Description Revokes an API key. It stops working immediately but stays listed for auditing.
+// @Tags Admin
+// @Param id path string true "App ID"
+// @Param keyId path string true "API key ID"
+// @Success 204 "API key revoked"
+// @Failure 401 {object} ErrorResponse "Missing or invalid auth token"
+// @Failure 404 {object} ErrorResponse "App or API key not found"
+// @Security BearerAuth
+// @Router /admin/apps/{id}/keys/{keyId} [delete]
+func HandleRevokeAPIKey(re *core.RequestEvent, app core.App) error {
+	record, err := findAPIKey(re, app)
+	if err != nil {
+		return sendJSONErrorStatus(re, http.StatusNotFound, "API key not found")
+	}
+
+	if err := auth.RevokeAPIKey(app, record); err != nil {
+		logger.Error("Failed to revoke API key", "key_id", record.Id, "error", err)
+		return sendJSONErrorStatus(re, http.StatusInternalServerError, "failed to revoke API key")
+	}
+
+	logger.Info("API key revoked", "key_id", record.Id, "app", record.GetString("app"))
+	return re.NoContent(http.StatusNoContent)
+}
+
+// createAPIKey creates a key and responds with its plaintext value.
+func createAPIKey(re *core.RequestEvent, app core.App, appID, name string, scopes []auth.Scope) error {
+	record, key, err := auth.CreateAPIKey(app, appID, name, scopes)
+	if err != nil {
+		logger.Error("Failed to create API key", "app", appID, "error", err)
+		return sendJSONErrorStatus(re, http.StatusInternalServerError, "failed to create API key")
+	}
+
+	logger.Info("API key created", "key_id", record.Id, "app", appID, "scopes", scopes)
+	return sendJSON(re, http.StatusCreated, CreatedAPIKey{APIKey: newAPIKey(record), Key: key})
+}
+
+// findAPIKey loads the key from the path, making sure it belongs to the app in the path.
+func findAPIKey(re *core.RequestEvent, app core.App) (*core.Record, error) {
+	return app.FindFirstRecordByFilter(auth.APIKeysCollection, "id = {:id} && app = {:app}", dbx.Params{
+		"id":  re.Request.PathValue("keyId"),
+		"app": re.Request.PathValue("id"),
+	})
+}
+
+// newAPIKey maps an api_keys record to its API representation.
+func newAPIKey(record *core.Record) APIKey {
+	return APIKey{
+		ID:         record.Id,
+		App:        record.GetString("app"),
+		Name:       record.GetString("name"),
+		Prefix:     record.GetString("prefix"),
+		Scopes:     record.GetStringSlice("scopes"),
+		LastUsedAt: record.GetString("last_used_at"),
+		RevokedAt:  record.GetString("revoked_at"),
+		Created:    record.GetString("created"),
+	}
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+
+	"silence-backend/compression"
+	"silence-backend/logger"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// HandleTranscriptAudio godoc
+// @Summary Download transcript audio
+// @Description Streams the stored recording of a transcript with its codec's content type. Supports HTTP Range requests for seeking. Add '?download=1' to force a file download.
+// @Tags Transcripts
+// @Produce audio/ogg
+// @Produce audio/mpeg
+// @Produce audio/flac
+// @Param id path string true "Transcript ID"
+// @Success 200 {file} binary "Audio file"
+// @Success 206 {file} binary "Requested byte range"
+// @Failure 401 {object} ErrorResponse "Missing or invalid auth token"
+// @Failure 404 {object} ErrorResponse "Transcript or audio not found"
+// @Security BearerAuth
+// @Router /transcripts/{id}/audio [get]
+func HandleTranscriptAudio(re *core.RequestEvent, app core.App) error {
+	id := re.Request.PathValue("id")
+
+	record, err := findTranscript(re, app)
+	if err != nil {
+		return sendJSONErrorStatus(re, http.StatusNotFound, "transcript not found")
+	}
+
+	filename := record.GetString("audio")
+	if filename == "" {
+		return sendJSONErrorStatus(re, http.StatusNotFound, "transcript has no stored audio")
+	}
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		logger.Error("Failed to open file storage", "error", err)
+		return sendJSONErrorStatus(re, http.StatusInternalServerError, "storage unavailable")
+	}
+	defer fsys.Close()
+
+	// Sniffing can't tell Opus from Vorbis, the codec field can
+	if contentType := compression.Codec(record.GetString("codec")).ContentType(); contentType != "" {
+		re.Response.Header().Set("Content-Type", contentType)
+	}
+
+	if err := fsys.Serve(re.Response, re.Request, record.BaseFilesPath()+"/"+filename, filename); err != nil {
+		logger.Error("Failed to serve audio file", "record_id", id, "error", err)
+		return sendJSONErrorStatus(re, http.StatusNotFound, "audio file not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+
+	"silence-backend/logger"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// BackfillResponse reports how many failed audio uploads were queued again.
+type BackfillResponse struct {
+	Queued    int `json:"queued" example:"12"`   // Jobs handed to the persistence queue
+	Remaining int `json:"remaining" example:"0"` // Failures left for a later call because the queue filled up
+	Skipped   int `json:"skipped" example:"1"`   // Failures whose raw audio couldn't be read
+}
+
+// HandleAudioBackfill godoc
+// @Summary Retry failed audio storage
+// @Description Queues every transcript in 'audio_failed' status for another attempt at compressing and attaching its audio, using the raw upload kept in persist_failures. Successful retries remove the dead letter and set the status to 'stored'. Stops early when the queue is full; call again to continue.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} BackfillResponse "Backfill summary"
+// @Failure 401 {object} ErrorResponse "Missing or invalid auth token"
+// @Failure 500 {object} ErrorResponse "Failed to read dead letters"
+// @Security BearerAuth
+// @Router /admin/audio/backfill [post]
+func HandleAudioBackfill(re *core.RequestEvent, app core.App, config Config) error {
+	failures, err := app.FindRecordsByFilter("persist_failures",
+		"job = 'attach_audio' && transcript != '' && audio != '' && transcript.status = 'audio_failed'",
+		"created", 0, 0)
+	if err != nil {
+		logger.Error("Failed to list dead letters", "error", err)
+		return sendJSONErrorStatus(re, http.StatusInternalServerError, "failed to read dead letters")
+	}
+
+	response := BackfillResponse{}
+	for i, failure := range failures {
+		var payload recording
+		if err := failure.UnmarshalJSONField("payload", &payload); err != nil {
+			logger.Error("Failed to read dead-letter payload", "failure_id", failure.Id, "error", err)
+			response.Skipped++
+			continue
+		}
+
+		audioData, err := readRecordFile(app, failure, "audio")
+		if err != nil {
+			logger.Error("Failed to read dead-letter audio", "failure_id", failure.Id, "error", err)
+			response.Skipped++
+			continue
+		}
+
+		job := &attachAudioJob{
+			app:        app,
+			compressor: config.Compressor,
+			recordID:   failure.GetString("transcript"),
+			audio:      audioData,
+			metadata:   payload.Metadata,
+			failureID:  failure.Id,
+		}
+
+		// Mark the transcript before queueing, so the job's own status update
+		// can't be overwritten by this one.
+		transcript, err := app.FindRecordById("silence", job.recordID)
+		if err != nil {
+			logger.Error("Failed to find transcript for dead letter", "failure_id", failure.Id, "record_id", job.recordID, "error", err)
+			response.Skipped++
+			continue
+		}
+		previousStatus := transcript.GetString("status")
+		transcript.Set("status", statusPendingAudio)
+		if err := app.Save(transcript); err != nil {
+			logger.Error("Failed to reset transcript status", "record_id", job.recordID, "error", err)
+			response.Skipped++
+			continue
+		}
+
+		if err := config.Queue.Enqueue(job); err != nil {
+			transcript.Set("status", previousStatus)
+			if err := app.Save(transcript); err != nil {
+				logger.Error("Failed to restore transcript status", "record_id", job.recordID, "error", err)
+			}
+			response.Remaining = len(failures) - i
+			break
+		}
+		response.Queued++
+	}
+
+	logger.Info("Audio backfill queued", "queued", response.Queued, "remaining", response.Remaining, "skipped", response.Skipped)
+	return sendJSON(re, http.StatusOK, response)
+}
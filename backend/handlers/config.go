@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"silence-backend/audio"
+	"silence-backend/compression"
+	"silence-backend/intent"
+	"silence-backend/postprocess"
+	"silence-backend/queue"
+	"silence-backend/ratelimit"
+	"silence-backend/retention"
+	"silence-backend/usage"
+)
+
+// Config holds server-wide defaults for request handlers.
+// Individual requests may override them through form fields.
+type Config struct {
+	Preprocess  audio.PreprocessConfig // Preprocessing applied before transcription
+	Postprocess postprocess.Pipeline   // Text processing applied after transcription
+	Compressor  compression.Compressor // Encoder for stored audio
+	Retention   *retention.Enforcer    // Retention policy, used for dry-run reports
+	Queue       *queue.Queue           // Background persistence of transcripts
+	Limiter     *ratelimit.Limiter     // Per-app and per-user rate limits and audio quotas
+	Meter       *usage.Meter           // Usage and cost accounting, with the spend budget
+	Intents     *intent.Cache          // Compiled app commands that transcripts are routed to
+}
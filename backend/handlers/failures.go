@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"silence-backend/audio"
+	"silence-backend/logger"
+	"silence-backend/postprocess"
+	"silence-backend/profile"
+	"silence-backend/transcription"
+	"silence-backend/usage"
+	"silence-backend/vocabulary"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// ReplayResult is the outcome of replaying a failed transcription request.
+type ReplayResult struct {
+	FailureID      string                  `json:"failure_id" example:"f8k2l3m4n5o6p7q"`
+	Success        bool                    `json:"success" example:"true"`
+	Text           string                  `json:"text,omitempty" example:"Hello world, this is a transcription"`
+	RawText        string                  `json:"raw_text,omitempty" example:"hello world comma this is a transcription"`
+	LanguageCode   string                  `json:"language_code,omitempty" example:"en"`
+	Provider       string                  `json:"provider,omitempty" example:"chutes"`
+	Error          string                  `json:"error,omitempty" example:"all providers failed, last error: provider 2 failed: status 503"`
+	FailedAttempts []transcription.Attempt `json:"failed_attempts"`
+	Postprocess    []postprocess.Applied   `json:"postprocess,omitempty"`
+	TranscribeMs   int64                   `json:"transcribe_ms" example:"1840"`
+	TranscriptID   string                  `json:"transcript_id,omitempty" example:"ead6abyjn82q49r"`
+	ReplayedAt     string                  `json:"replayed_at" example:"2026-10-18 13:31:13.352Z"`
+}
+
+// recordFailure stores a request that no provider could transcribe in the
+// 'failures' collection, with its original audio and every provider's error.
+func recordFailure(app core.App, rec recording, requestedProvider string, err error) {
+	collection, findErr := app.FindCollectionByNameOrId("failures")
+	if findErr != nil {
+		logger.Error("Failed to find failures collection", "error", findErr)
+		return
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("input_format", string(rec.Metadata.Format))
+	record.Set("sample_rate", rec.Metadata.SampleRate)
+	record.Set("channels", rec.Metadata.Channels)
+	record.Set("duration_ms", rec.Duration.Milliseconds())
+	record.Set("requested_language", rec.RequestedLanguage)
+	record.Set("requested_provider", requestedProvider)
+	record.Set("preprocess", rec.Preprocess)
+	record.Set("postprocess", rec.Postprocess)
+	record.Set("vocabulary", rec.Vocabulary)
+	record.Set("quality", rec.Quality)
+	record.Set("client", rec.Client)
+	record.Set("app", rec.App)
+	record.Set("owner", rec.Owner)
+	record.Set("error", err.Error())
+	record.Set("attempts", failedAttempts(err, requestedProvider))
+	record.Set("transcribe_ms", rec.TranscribeTime.Milliseconds())
+
+	audioFile, fileErr := filesystem.NewFileFromBytes(rec.Audio, "audio"+inputExtension(rec.Metadata.Format))
+	if fileErr != nil {
+		logger.Error("Failed to attach audio to failure record", "error", fileErr)
+	} else {
+		record.Set("audio", audioFile)
+	}
+
+	if saveErr := app.Save(record); saveErr != nil {
+		logger.Error("Failed to save failure record", "error", saveErr)
+		return
+	}
+
+	logger.Info("Transcription failure recorded", "failure_id", record.Id)
+}
+
+// failedAttempts lists each provider's error. A chain reports all of them; a
+// single provider's error is attributed to the provider that was requested.
+func failedAttempts(err error, requestedProvider string) []transcription.Attempt {
+	var chainErr *transcription.ChainError
+	if errors.As(err, &chainErr) {
+		return chainErr.Attempts
+	}
+	return []transcription.Attempt{{Provider: transcription.ProviderName(requestedProvider), Error: err.Error()}}
+}
+
+// HandleReplayFailure godoc
+// @Summary Replay a failed transcription
+// @Description Sends the stored audio of a failed request to a provider again, with the original language, preprocessing, postprocessing and vocabulary. The app's current vocabulary is added, and its postprocessing applies when the request didn't ask for any. The outcome is saved on the failure as 'last_replay'. With 'save=true', a successful replay is also stored as a transcript and linked to the failure.
+// @Tags Admin
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Failure ID"
+// @Param provider formData string false "Provider to replay against: 'elevenlabs' or 'chutes'. Omit to use the default provider chain."
+// @Param language_code formData string false "Override the originally requested language code"
+// @Param save formData bool false "Store a successful replay as a transcript"
+// @Success 200 {object} ReplayResult "Replay outcome, successful or not"
+// @Failure 400 {object} ErrorResponse "Invalid provider, save flag or stored audio"
+// @Failure 401 {object} ErrorResponse "Missing or invalid auth token"
+// @Failure 404 {object} ErrorResponse "Failure not found"
+// @Security BearerAuth
+// @Router /admin/failures/{id}/replay [post]
+func HandleReplayFailure(re *core.RequestEvent, app core.App, config Config, defaultProvider transcription.TranscriptionProvider, providers map[transcription.ProviderName]transcription.TranscriptionProvider) error {
+	id := re.Request.PathValue("id")
+
+	failure, err := app.FindRecordById("failures", id)
+	if err != nil {
+		return sendJSONErrorStatus(re, http.StatusNotFound, "failure not found")
+	}
+
+	providerName := re.Request.FormValue("provider")
+	provider, err := selectProvider(providerName, defaultProvider, providers)
+	if err != nil {
+		return sendJSONError(re, err.Error())
+	}
+
+	save := false
+	if value := re.Request.FormValue("save"); value != "" {
+		if save, err = strconv.ParseBool(value); err != nil {
+			return sendJSONError(re, fmt.Sprintf("Invalid save: %s. Valid options: true, false", value))
+		}
+	}
+
+	languageCode := re.Request.FormValue("language_code")
+	if languageCode == "" {
+		languageCode = failure.GetString("requested_language")
+	}
+
+	preprocess := config.Preprocess
+	if spec := failure.GetString("preprocess"); spec != "" {
+		if preprocess, err = audio.ParsePreprocessConfig(spec); err != nil {
+			return sendJSONError(re, fmt.Sprintf("Invalid stored preprocess: %v", err))
+		}
+	}
+
+	audioData, err := readRecordFile(app, failure, "audio")
+	if err != nil {
+		logger.Error("Failed to read failure audio", "failure_id", id, "error", err)
+		return sendJSONError(re, "stored audio is not available")
+	}
+
+	metadata := transcription.AudioMetadata{
+		Format:        transcription.AudioFormat(failure.GetString("input_format")),
+		SampleRate:    failure.GetInt("sample_rate"),
+		Channels:      failure.GetInt("channels"),
+		BitsPerSample: 16,
+	}
+	pcm, err := transcription.DecodeAudio(audioData, metadata)
+	if err != nil {
+		return sendJSONError(re, fmt.Sprintf("Invalid stored audio: %v", err))
+	}
+
+	logger.Info("Replaying failed transcription", "failure_id", id, "provider", providerName, "language_code", languageCode)
+	transcribeStart := time.Now()
+	appProfile := profile.Load(app, failure.GetString("app"))
+	var requestTerms []string
+	if err := failure.UnmarshalJSONField("vocabulary", &requestTerms); err != nil {
+		logger.Error("Invalid stored vocabulary, using the app's", "failure_id", id, "error", err)
+	}
+	terms := vocabulary.Merge(requestTerms, appProfile.Vocabulary)
+	result, err := transcribeAudio(provider, pcm, audioData, metadata, preprocess, languageCode, terms)
+	transcribeDuration := time.Since(transcribeStart)
+
+	replay := ReplayResult{
+		FailureID:      id,
+		Success:        err == nil,
+		FailedAttempts: []transcription.Attempt{},
+		TranscribeMs:   transcribeDuration.Milliseconds(),
+		ReplayedAt:     types.NowDateTime().String(),
+	}
+	if err != nil {
+		logger.Error("Replay failed", "failure_id", id, "error", err)
+		replay.Error = err.Error()
+		replay.FailedAttempts = failedAttempts(err, providerName)
+	} else {
+		pipeline, pipelineErr := postprocessPipeline(config, appProfile, failure.GetString("postprocess"))
+		if pipelineErr != nil {
+			logger.Error("Invalid postprocess in app profile, using the server default", "failure_id", id, "error", pipelineErr)
+			pipeline = config.Postprocess
+		}
+		if pipeline.Enabled() {
+			replay.RawText = result.Text
+		}
+		replay.Text, replay.Postprocess = pipeline.Run(result.Text, transcriptLanguage(result, languageCode))
+		replay.LanguageCode = result.LanguageCode
+		replay.Provider = string(result.Provider)
+		if result.FailedAttempts != nil {
+			replay.FailedAttempts = result.FailedAttempts
+		}
+
+		recordUsage(config, result, usage.Entry{
+			Duration: pcm.Duration(),
+			App:      failure.GetString("app"),
+			Owner:    failure.GetString("owner"),
+			Replay:   true,
+		})
+
+		if save {
+			replay.TranscriptID = persistRecording(app, config, recording{
+				Audio:             audioData,
+				Metadata:          metadata,
+				Text:              replay.Text,
+				Language:          result.LanguageCode,
+				RequestedLanguage: languageCode,
+				Provider:          result.Provider,
+				FailedAttempts:    result.FailedAttempts,
+				Preprocess:        failure.GetString("preprocess"),
+				Duration:          pcm.Duration(),
+				Quality:           audio.Analyze(pcm),
+				TranscribeTime:    transcribeDuration,
+				App:               failure.GetString("app"),
+				Owner:             failure.GetString("owner"),
+				DiscardAudio:      appProfile.DiscardAudio,
+			})
+			failure.Set("transcript", replay.TranscriptID)
+		}
+	}
+
+	failure.Set("last_replay", replay)
+	if err := app.Save(failure); err != nil {
+		logger.Error("Failed to save replay outcome", "failure_id", id, "error", err)
+	}
+
+	return sendJSON(re, http.StatusOK, replay)
+}
+
+// readRecordFile reads a file field of a record from storage.
+func readRecordFile(app core.App, record *core.Record, field string) ([]byte, error) {
+	filename := record.GetString(field)
+	if filename == "" {
+		return nil, fmt.Errorf("record %s has no %s file", record.Id, field)
+	}
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		return nil, err
+	}
+	defer fsys.Close()
+
+	reader, err := fsys.GetReader(record.BaseFilesPath() + "/" + filename)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"silence-backend/intent"
+	"silence-backend/logger"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// routeIntent matches a transcript against the commands every app declares
+// and calls the matched app's webhook. Returns nil when nothing matches or
+// the apps can't be loaded; routing never fails the transcription.
+func routeIntent(re *core.RequestEvent, config Config, text, language, transcriptID string) *intent.Match {
+	router, err := config.Intents.Router()
+	if err != nil {
+		logger.Error("Failed to load app commands", "error", err)
+		return nil
+	}
+
+	match := router.Match(text)
+	if match == nil {
+		logger.Info("No app command matched the transcript", "transcript_id", transcriptID)
+		return nil
+	}
+	logger.Info("Transcript matched an app command", "transcript_id", transcriptID, "app", match.App, "command", match.Command, "score", match.Score)
+
+	match.Notify(re.Request.Context(), intent.Event{
+		Text:         text,
+		LanguageCode: language,
+		TranscriptID: transcriptID,
+		CallerApp:    callerApp(re),
+		CallerUser:   callerUser(re),
+	})
+	return match
+}
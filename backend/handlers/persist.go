@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"silence-backend/audio"
+	"silence-backend/compression"
+	"silence-backend/ffmpeg"
+	"silence-backend/logger"
+	"silence-backend/transcription"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+// recording is everything about a request that is persisted to the 'silence' collection.
+type recording struct {
+	Audio             []byte                      `json:"-"`                  // Original upload, before any preprocessing
+	Metadata          transcription.AudioMetadata `json:"metadata"`           // Format of Audio
+	Text              string                      `json:"text"`               // Transcribed text
+	Language          string                      `json:"language"`           // Language detected by the provider
+	RequestedLanguage string                      `json:"requested_language"` // Language code sent by the client, "auto" for detection
+	Provider          transcription.ProviderName  `json:"provider"`           // Provider that produced Text
+	FailedAttempts    []transcription.Attempt     `json:"failed_attempts"`    // Providers that failed before Provider answered
+	Preprocess        string                      `json:"preprocess"`         // Preprocessing requested by the client, empty for the server default
+	Postprocess       string                      `json:"postprocess"`        // Postprocessing requested by the client, empty for the app's or server default
+	Vocabulary        []string                    `json:"vocabulary"`         // Terms sent by the client, on top of the app's
+	Duration          time.Duration               `json:"duration"`           // Exact audio duration
+	Quality           audio.Quality               `json:"quality"`            // Audio diagnostics
+	UploadTime        time.Duration               `json:"upload_time"`        // Receiving and reading the upload
+	TranscribeTime    time.Duration               `json:"transcribe_time"`    // Provider call, including fallbacks and chunking
+	Client            clientInfo                  `json:"client"`             // Who sent the request
+	App               string                      `json:"app"`                // ID of the app whose API key was used
+	Owner             string                      `json:"owner"`              // ID of the signed-in user who made the request
+	DiscardAudio      bool                        `json:"discard_audio"`      // Keep only the text, as set by the app's profile
+}
+
+// clientInfo identifies the caller of a request.
+type clientInfo struct {
+	Name      string `json:"name,omitempty"` // Optional client name from the 'client' form field
+	UserAgent string `json:"user_agent,omitempty"`
+	IP        string `json:"ip,omitempty"`
+}
+
+// newClientInfo collects client details from the request.
+func newClientInfo(re *core.RequestEvent) clientInfo {
+	return clientInfo{
+		Name:      re.Request.FormValue("client"),
+		UserAgent: re.Request.UserAgent(),
+		IP:        re.RealIP(),
+	}
+}
+
+// Audio states of a transcript, kept in the 'status' field.
+const (
+	statusPendingAudio = "pending_audio" // Transcript saved, audio still being compressed
+	statusStored       = "stored"        // Audio attached
+	statusAudioFailed  = "audio_failed"  // Audio could not be stored, raw upload kept in persist_failures
+	statusDiscarded    = "discarded"     // Audio not kept, as set by the app's profile
+	statusExpired      = "expired"       // Audio deleted by the retention policy
+
+	// Base64 audio from before audio files that couldn't be converted. The text
+	// waits in persist_failures as a convert_legacy_audio dead letter; it isn't
+	// a raw upload, so the audio backfill leaves it alone.
+	statusLegacyAudioFailed = "legacy_audio_failed"
+)
+
+// persistRecording stores a transcription in two phases. The transcript row is
+// written right away, so the text survives any later failure, and compressing
+// and attaching the audio is left to the persistence queue. If even the row
+// can't be written, the whole recording goes to the dead-letter collection.
+// Apps that discard audio only get the row. It returns the ID of the new
+// transcript, or "" when the row wasn't written.
+func persistRecording(app core.App, config Config, rec recording) string {
+	record, err := saveTranscript(app, rec)
+	if err != nil {
+		logger.Error("Failed to save transcript", "error", err)
+		writeDeadLetter(app, "save_transcript", "", rec, 0, err)
+		return ""
+	}
+	if rec.DiscardAudio {
+		return record.Id
+	}
+
+	queueAudio(app, config, &attachAudioJob{
+		app:        app,
+		compressor: config.Compressor,
+		recordID:   record.Id,
+		audio:      rec.Audio,
+		metadata:   rec.Metadata,
+	})
+
+	return record.Id
+}
+
+// queueAudio hands an audio job to the persistence queue. When the queue is
+// full or shutting down the job fails right away, which keeps the raw upload
+// for a backfill instead of dropping it.
+func queueAudio(app core.App, config Config, job *attachAudioJob) {
+	if err := config.Queue.Enqueue(job); err != nil {
+		logger.Error("Failed to queue audio for storage", "record_id", job.recordID, "error", err, "queue_depth", config.Queue.Stats().Depth)
+		job.Fail(0, err)
+	}
+}
+
+// saveTranscript writes the transcript row without audio, in pending_audio
+// status, or discarded status when the audio won't be kept.
+func saveTranscript(app core.App, rec recording) (*core.Record, error) {
+	collection, err := app.FindCollectionByNameOrId("silence")
+	if err != nil {
+		return nil, fmt.Errorf("find silence collection: %w", err)
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("status", statusPendingAudio)
+	if rec.DiscardAudio {
+		record.Set("status", statusDiscarded)
+	}
+	record.Set("result", rec.Text)
+	record.Set("language", rec.Language)
+	record.Set("requested_language", rec.RequestedLanguage)
+	record.Set("provider", string(rec.Provider))
+	record.Set("failed_attempts", rec.FailedAttempts)
+	record.Set("preprocess", rec.Preprocess)
+	record.Set("input_format", string(rec.Metadata.Format))
+	record.Set("sample_rate", rec.Metadata.SampleRate)
+	record.Set("channels", rec.Metadata.Channels)
+	record.Set("size_bytes", len(rec.Audio))
+	record.Set("duration_ms", rec.Duration.Milliseconds())
+	record.Set("quality", rec.Quality)
+	record.Set("upload_ms", rec.UploadTime.Milliseconds())
+	record.Set("transcribe_ms", rec.TranscribeTime.Milliseconds())
+	record.Set("client", rec.Client)
+	record.Set("app", rec.App)
+	record.Set("owner", rec.Owner)
+
+	if err := app.Save(record); err != nil {
+		return nil, fmt.Errorf("save transcript: %w", err)
+	}
+
+	logger.Info("Transcript saved", "record_id", record.Id)
+	return record, nil
+}
+
+// attachAudioJob compresses the upload of a saved transcript and attaches it.
+type attachAudioJob struct {
+	app        core.App
+	compressor compression.Compressor
+	recordID   string
+	audio      []byte
+	metadata   transcription.AudioMetadata
+
+	// failureID is the persist_failures record this job replays, if any
+	failureID string
+}
+
+// Name implements queue.Job.
+func (j *attachAudioJob) Name() string {
+	return "attach_audio"
+}
+
+// Run implements queue.Job. It compresses the audio with the configured codec
+// and stores it as a file on the transcript.
+func (j *attachAudioJob) Run(ctx context.Context) error {
+	logger.Info("Compressing audio data", "record_id", j.recordID, "original_size", len(j.audio), "codec", j.compressor.Codec())
+	compressStart := time.Now()
+	var compressed bytes.Buffer
+	err := j.compressor.Compress(ctx, bytes.NewReader(j.audio), &compressed, j.metadata)
+	if err != nil {
+		logFFmpegError("Failed to compress audio in background", err)
+		return fmt.Errorf("compress audio: %w", err)
+	}
+	compressDuration := time.Since(compressStart)
+
+	audioFile, err := filesystem.NewFileFromBytes(compressed.Bytes(), "audio"+j.compressor.Codec().Extension())
+	if err != nil {
+		return fmt.Errorf("prepare audio file: %w", err)
+	}
+
+	record, err := j.app.FindRecordById("silence", j.recordID)
+	if err != nil {
+		return fmt.Errorf("find transcript %s: %w", j.recordID, err)
+	}
+
+	record.Set("audio", audioFile)
+	record.Set("codec", string(j.compressor.Codec()))
+	record.Set("compress_ms", compressDuration.Milliseconds())
+	record.Set("status", statusStored)
+
+	if err := j.app.Save(record); err != nil {
+		return fmt.Errorf("attach audio: %w", err)
+	}
+
+	if j.failureID != "" {
+		if failure, err := j.app.FindRecordById("persist_failures", j.failureID); err == nil {
+			if err := j.app.Delete(failure); err != nil {
+				logger.Error("Failed to remove replayed dead letter", "failure_id", j.failureID, "error", err)
+			}
+		}
+	}
+
+	logger.Info("Audio stored", "record_id", record.Id, "compressed_size", compressed.Len())
+	return nil
+}
+
+// Fail implements queue.Job. The transcript is marked audio_failed and the raw
+// upload is kept in persist_failures for a later backfill. A replayed job
+// updates its existing dead letter instead of adding another.
+func (j *attachAudioJob) Fail(attempts int, err error) {
+	if record, findErr := j.app.FindRecordById("silence", j.recordID); findErr == nil {
+		record.Set("status", statusAudioFailed)
+		if saveErr := j.app.Save(record); saveErr != nil {
+			logger.Error("Failed to mark transcript audio as failed", "record_id", j.recordID, "error", saveErr)
+		}
+	}
+
+	if j.failureID != "" {
+		if failure, findErr := j.app.FindRecordById("persist_failures", j.failureID); findErr == nil {
+			failure.Set("error", err.Error())
+			failure.Set("attempts", failure.GetInt("attempts")+attempts)
+			if saveErr := j.app.Save(failure); saveErr != nil {
+				logger.Error("Failed to update dead-letter record", "failure_id", j.failureID, "error", saveErr)
+			}
+			return
+		}
+	}
+
+	writeDeadLetter(j.app, j.Name(), j.recordID, recording{Audio: j.audio, Metadata: j.metadata}, attempts, err)
+}
+
+// writeDeadLetter stores a recording with its original audio in the
+// 'persist_failures' collection so it can be inspected and stored later.
+func writeDeadLetter(app core.App, job string, transcriptID string, rec recording, attempts int, err error) {
+	collection, findErr := app.FindCollectionByNameOrId("persist_failures")
+	if findErr != nil {
+		logger.Error("Failed to find persist_failures collection, recording lost", "error", findErr, "text", rec.Text)
+		return
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("job", job)
+	record.Set("transcript", transcriptID)
+	record.Set("error", err.Error())
+	record.Set("attempts", attempts)
+	record.Set("payload", rec)
+
+	if len(rec.Audio) > 0 {
+		audioFile, fileErr := filesystem.NewFileFromBytes(rec.Audio, "audio"+inputExtension(rec.Metadata.Format))
+		if fileErr == nil {
+			record.Set("audio", audioFile)
+		} else {
+			logger.Error("Failed to attach audio to dead-letter record", "error", fileErr)
+		}
+	}
+
+	if saveErr := app.Save(record); saveErr != nil {
+		logger.Error("Failed to save dead-letter record, recording lost", "error", saveErr, "text", rec.Text)
+		return
+	}
+
+	logger.Info("Recording stored as dead letter", "record_id", record.Id, "job", job, "transcript", transcriptID, "attempts", attempts)
+}
+
+// inputExtension is the file extension for uploaded audio of the given format.
+func inputExtension(format transcription.AudioFormat) string {
+	if format == transcription.AudioFormatWAV {
+		return ".wav"
+	}
+	return ".pcm"
+}
+
+// logFFmpegError logs an error, expanding ffmpeg process details when present.
+func logFFmpegError(msg string, err error) {
+	var ffErr *ffmpeg.Error
+	if errors.As(err, &ffErr) {
+		logger.Error(msg, "error", ffErr.Err, "exit_code", ffErr.ExitCode, "timed_out", ffErr.TimedOut,
+			"duration_ms", ffErr.Duration.Milliseconds(), "stderr", ffErr.Stderr)
+		return
+	}
+	logger.Error(msg, "error", err)
+}
@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// HandleQueueStats godoc
+// @Summary Persistence queue metrics
+// @Description Returns the depth and counters of the background queue that compresses and stores transcripts. A growing depth means storage can't keep up with requests; failed jobs end up in the persist_failures collection.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} queue.Stats "Queue metrics"
+// @Failure 401 {object} ErrorResponse "Missing or invalid auth token"
+// @Security BearerAuth
+// @Router /admin/queue [get]
+func HandleQueueStats(re *core.RequestEvent, config Config) error {
+	return sendJSON(re, http.StatusOK, config.Queue.Stats())
+}
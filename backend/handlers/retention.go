@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"silence-backend/logger"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// HandleRetentionPreview godoc
+// @Summary Preview retention cleanup
+// @Description Runs the retention policy as a dry run and lists the transcripts, audio files and dead-letter uploads the next scheduled cleanup would delete or downsample. Nothing is modified.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} retention.Report "Dry-run report"
+// @Failure 401 {object} ErrorResponse "Missing or invalid auth token"
+// @Failure 500 {object} ErrorResponse "Failed to evaluate retention policy"
+// @Security BearerAuth
+// @Router /admin/retention [get]
+func HandleRetentionPreview(re *core.RequestEvent, config Config) error {
+	if config.Retention == nil {
+		return sendJSONErrorStatus(re, http.StatusInternalServerError, "retention is not configured")
+	}
+
+	report, err := config.Retention.Run(re.Request.Context(), true)
+	if err != nil {
+		logger.Error("Retention dry run failed", "error", err)
+		return sendJSONErrorStatus(re, http.StatusInternalServerError, "failed to evaluate retention policy")
+	}
+
+	return sendJSON(re, http.StatusOK, report)
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"silence-backend/database"
+	"silence-backend/logger"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Markers wrapped around matched words in search snippets. SQLite inserts
+// control characters that escaping leaves alone, and they are swapped for the
+// tags once the text is HTML-escaped.
+const (
+	snippetOpen  = "\x02"
+	snippetClose = "\x03"
+)
+
+// snippetMarkers turns SQLite's markers into <mark> tags around escaped text.
+var snippetMarkers = strings.NewReplacer(snippetOpen, "<mark>", snippetClose, "</mark>")
+
+// TranscriptSearchResult is a transcript matching a search query.
+type TranscriptSearchResult struct {
+	Transcript
+	Snippet string  `json:"snippet" example:"…what did I say about <mark>pricing</mark> last week…"`
+	Rank    float64 `json:"rank" example:"-4.21"`
+}
+
+// TranscriptSearchResponse is a page of search results, best match first.
+type TranscriptSearchResponse struct {
+	Items      []TranscriptSearchResult `json:"items"`
+	NextOffset int                      `json:"next_offset,omitempty" example:"20"`
+}
+
+// searchHit is one row of the full-text query.
+type searchHit struct {
+	ID      string  `db:"id"`
+	Snippet string  `db:"snippet"`
+	Rank    float64 `db:"rank"`
+}
+
+// HandleSearchTranscripts godoc
+// @Summary Search transcripts
+// @Description Full-text search over transcript text, ranked by relevance (BM25, lower rank is better). All words must match; end a word with '*' for prefix matching. The 'snippet' is HTML-escaped text with matches highlighted in <mark> tags. Accepts the same filters as the transcript listing.
+// @Tags Transcripts
+// @Produce json
+// @Param q query string true "Search text, e.g. 'pricing meeting'"
+// @Param from query string false "Only transcripts created at or after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string false "Only transcripts created before this time (RFC 3339 or YYYY-MM-DD, exclusive)"
+// @Param language query string false "Detected language code, e.g. 'en'"
+// @Param provider query string false "Provider that produced the transcript: 'elevenlabs' or 'chutes'"
+// @Param app query string false "ID of the app the transcript is attributed to"
+// @Param owner query string false "ID of the user who owns the transcript (ignored for user tokens, which only see their own)"
+// @Param status query string false "Audio storage status: 'pending_audio', 'stored', 'audio_failed', 'discarded', 'expired' or 'legacy_audio_failed'"
+// @Param limit query int false "Page size, 1-100 (default 20)"
+// @Param offset query int false "Number of results to skip, from 'next_offset'"
+// @Success 200 {object} TranscriptSearchResponse "Matching transcripts"
+// @Failure 400 {object} ErrorResponse "Missing query or invalid filter"
+// @Failure 401 {object} ErrorResponse "Missing or invalid auth token"
+// @Security BearerAuth
+// @Router /transcripts/search [get]
+func HandleSearchTranscripts(re *core.RequestEvent, app core.App) error {
+	query := re.Request.URL.Query()
+
+	match := database.SearchQuery(query.Get("q"))
+	if match == "" {
+		return sendJSONError(re, "q is required")
+	}
+
+	filter, err := parseTranscriptFilter(re)
+	if err != nil {
+		return sendJSONError(re, err.Error())
+	}
+
+	limit := defaultTranscriptLimit
+	if raw := query.Get("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxTranscriptLimit {
+			return sendJSONError(re, "limit must be between 1 and 100")
+		}
+	}
+	offset := 0
+	if raw := query.Get("offset"); raw != "" {
+		offset, err = strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return sendJSONError(re, "offset must be a non-negative integer")
+		}
+	}
+
+	// Fetch one extra hit to know whether there is a next page
+	var hits []searchHit
+	err = app.DB().
+		Select(
+			"f.id AS id",
+			"snippet("+database.SearchTable+", 1, {:open}, {:close}, '…', 16) AS snippet",
+			"bm25("+database.SearchTable+") AS rank",
+		).
+		From(database.SearchTable+" f").
+		InnerJoin("silence s", dbx.NewExp("s.id = f.id")).
+		Where(dbx.NewExp(database.SearchTable+" MATCH {:match}", dbx.Params{"match": match})).
+		AndWhere(filter.sqlConditions("s")).
+		Bind(dbx.Params{"open": snippetOpen, "close": snippetClose}).
+		OrderBy("rank ASC").
+		Limit(int64(limit + 1)).
+		Offset(int64(offset)).
+		All(&hits)
+	if err != nil {
+		logger.Error("Failed to search transcripts", "query", match, "error", err)
+		return sendJSONErrorStatus(re, http.StatusInternalServerError, "failed to search transcripts")
+	}
+
+	response := TranscriptSearchResponse{Items: []TranscriptSearchResult{}}
+	if len(hits) > limit {
+		hits = hits[:limit]
+		response.NextOffset = offset + limit
+	}
+	if len(hits) == 0 {
+		return sendJSON(re, http.StatusOK, response)
+	}
+
+	ids := make([]string, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+	records, err := app.FindRecordsByIds("silence", ids)
+	if err != nil {
+		logger.Error("Failed to load search results", "error", err)
+		return sendJSONErrorStatus(re, http.StatusInternalServerError, "failed to search transcripts")
+	}
+	byID := make(map[string]*core.Record, len(records))
+	for _, record := range records {
+		byID[record.Id] = record
+	}
+
+	// Keep the ranking order of the full-text query
+	for _, hit := range hits {
+		record, ok := byID[hit.ID]
+		if !ok {
+			continue
+		}
+		response.Items = append(response.Items, TranscriptSearchResult{
+			Transcript: newTranscript(record, false),
+			Snippet:    snippetMarkers.Replace(html.EscapeString(strings.TrimSpace(hit.Snippet))),
+			Rank:       hit.Rank,
+		})
+	}
+
+	return sendJSON(re, http.StatusOK, response)
+}
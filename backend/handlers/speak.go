@@ -1,26 +1,39 @@
 package handlers
 
 import (
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
-	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pocketbase/pocketbase/core"
-	"silence-backend/compression"
+	"silence-backend/audio"
+	"silence-backend/auth"
+	"silence-backend/intent"
 	"silence-backend/logger"
+	"silence-backend/postprocess"
+	"silence-backend/profile"
 	"silence-backend/transcription"
+	"silence-backend/usage"
+	"silence-backend/vocabulary"
 )
 
 // SuccessResponse represents a successful transcription response
 type SuccessResponse struct {
-	Text         string `json:"text" example:"Hello world, this is a transcription"`
-	LanguageCode string `json:"language_code" example:"en"`
-	AudioLength  int    `json:"audio_length" example:"15"`
-	Timestamp    int64  `json:"timestamp" example:"1629840000"`
+	ID            string                `json:"id" example:"ead6abyjn82q49r"`
+	Text          string                `json:"text" example:"Hello world, this is a transcription"`
+	RawText       string                `json:"raw_text,omitempty" example:"hello world comma this is a transcription"` // Provider's text before postprocessing
+	LanguageCode  string                `json:"language_code" example:"en"`
+	Provider      string                `json:"provider" example:"elevenlabs"`
+	AudioLength   float64               `json:"audio_length" example:"14.52"`
+	AudioLengthMs int64                 `json:"audio_length_ms" example:"14520"`
+	Quality       audio.Quality         `json:"quality"`
+	Postprocess   []postprocess.Applied `json:"postprocess,omitempty"` // Text processing steps, in the order they ran
+	Intent        *intent.Match         `json:"intent,omitempty"`      // App command the text matched, null if none; only with route=true
+	Timestamp     int64                 `json:"timestamp" example:"1629840000"`
 }
 
 // ErrorResponse represents an error response
@@ -31,19 +44,30 @@ type ErrorResponse struct {
 
 // HandleSpeak godoc
 // @Summary Transcribe audio
-// @Description Accepts audio in multipart/form-data format and returns transcribed text using the configured transcription provider. Supports both PCM and WAV formats.
+// @Description Accepts audio in multipart/form-data format and returns transcribed text using the configured transcription provider. Supports both PCM and WAV formats. Requires a user auth token or an app API key with the 'speak' scope; the transcript is owned by that user or attributed to that app. Options a request made with an API key leaves out come from the app's transcription profile (default_provider, default_language, vocabulary, preprocess, postprocess, output_format); the profile can also restrict the allowed languages and discard the audio after transcription.
 // @Tags Audio
 // @Accept multipart/form-data
-// @Produce json
+// @Produce json,plain
 // @Param audio formData file true "Audio file (PCM or WAV format, max 32MB)"
 // @Param file_format formData string false "Audio format: 'pcm_s16le_16' or 'wav'. Defaults to 'pcm_s16le_16' for lower latency. Use pcm_s16le_16 for 16-bit PCM at 16kHz, mono, little-endian."
-// @Param language_code formData string false "ISO-639-1 or ISO-639-3 language code. Use 'auto' or omit for auto-detection. Examples: 'en', 'es', 'fr'"
-// @Param provider formData string false "Transcription provider: 'elevenlabs' or 'chutes'. Omit to use default provider chain with fallback."
+// @Param language_code formData string false "ISO-639-1 or ISO-639-3 language code. Use 'auto' for auto-detection; omit for the app's default language or auto-detection. Examples: 'en', 'es', 'fr'"
+// @Param provider formData string false "Transcription provider: 'elevenlabs' or 'chutes'. Omit to use the app's default provider, or the default provider chain with fallback."
+// @Param client formData string false "Optional name of the calling client, stored with the transcript"
+// @Param preprocess formData string false "Comma-separated preprocessing steps applied before transcription: 'dc', 'highpass[=hz]', 'gate[=dbfs]', 'normalize[=peak|rms|loudness[:target]]', 'default' or 'none'. Omit to use the app's or the server default. The stored audio is never modified."
+// @Param postprocess formData string false "Comma-separated text processing steps applied after transcription, in order: 'cleanup', 'itn' (spoken numbers, dates and units to written form), 'fillers', 'profanity[=mask]', 'case[=sentence|lower|upper]', 'dictation' (spoken commands such as 'new line', 'comma' or 'scratch that', said on their own between pauses, to formatting; put it before 'itn'), or 'none'; a JSON array of steps also configures 'replace' dictionaries and extra 'dictation' commands. Omit to use the app's or the server default. Each step is reported in the response, along with the provider's raw text."
+// @Param vocabulary formData string false "Comma-separated names and jargon to bias transcription towards, added to the app's vocabulary. Passed to providers that support biasing (ElevenLabs keyterms), otherwise close spellings in the text are corrected to these terms; everyday words are left alone."
+// @Param route formData boolean false "Match the transcript against the commands apps declare in their description and return the matched app, command and slots as 'intent'. The matched app's webhook, if it has one, is called before responding."
+// @Param output_format formData string false "Response format: 'json' (default) or 'text' for the transcript only, as text/plain. Omit to use the app's default."
 // @Success 200 {object} SuccessResponse "Transcription successful"
-// @Failure 400 {object} ErrorResponse "Bad request (invalid format, empty audio, etc.)"
+// @Failure 400 {object} ErrorResponse "Bad request (invalid format, language not allowed for the app, empty or silent audio, etc.)"
+// @Failure 401 {object} ErrorResponse "Missing auth token, or invalid or revoked API key"
+// @Failure 403 {object} ErrorResponse "API key lacks the 'speak' scope"
+// @Failure 429 {object} ErrorResponse "Rate limit, concurrency limit or audio quota exceeded; see Retry-After"
+// @Security BearerAuth
 // @Router /speak [post]
-func HandleSpeak(re *core.RequestEvent, app core.App, defaultProvider transcription.TranscriptionProvider, providers map[transcription.ProviderName]transcription.TranscriptionProvider) error {
+func HandleSpeak(re *core.RequestEvent, app core.App, config Config, defaultProvider transcription.TranscriptionProvider, providers map[transcription.ProviderName]transcription.TranscriptionProvider) error {
 	logger.Info("Starting audio processing request")
+	requestStart := time.Now()
 
 	// Set JSON response headers
 	re.Response.Header().Set("Content-Type", "application/json")
@@ -65,11 +89,21 @@ func HandleSpeak(re *core.RequestEvent, app core.App, defaultProvider transcript
 	}
 	defer file.Close()
 
+	// Options the request leaves out come from the calling app's profile
+	appProfile := profile.FromApp(auth.RequestApp(re))
+
 	// Get optional language_code from form
 	languageCode := re.Request.FormValue("language_code")
+	if languageCode == "" {
+		languageCode = appProfile.Language
+	}
 	if languageCode == "" {
 		languageCode = "auto"
 	}
+	if !appProfile.AllowsLanguage(languageCode) {
+		logger.Error("Language not allowed for app", "language_code", languageCode, "allowed", appProfile.AllowedLanguages)
+		return sendJSONError(re, fmt.Sprintf("language_code %q is not allowed for this app, allowed: %s", languageCode, strings.Join(appProfile.AllowedLanguages, ", ")))
+	}
 
 	// Get optional file_format from form (default to pcm_s16le_16)
 	fileFormat := re.Request.FormValue("file_format")
@@ -77,16 +111,72 @@ func HandleSpeak(re *core.RequestEvent, app core.App, defaultProvider transcript
 		fileFormat = "pcm_s16le_16"
 	}
 
-	// Get optional provider from form (use default chain if not specified)
+	// Get optional provider from form (use the app's provider or the default chain if not specified)
 	providerName := re.Request.FormValue("provider")
-	var provider transcription.TranscriptionProvider = defaultProvider
-	if providerName != "" {
-		name := transcription.ProviderName(providerName)
-		if p, ok := providers[name]; ok {
-			provider = p
-		} else {
-			logger.Error("Invalid provider specified", "provider", providerName)
-			return sendJSONError(re, fmt.Sprintf("Invalid provider: %s. Valid options: elevenlabs, chutes", providerName))
+	if providerName == "" {
+		providerName = string(appProfile.Provider)
+	}
+	provider, err := selectProvider(providerName, defaultProvider, providers)
+	if err != nil {
+		logger.Error("Invalid provider specified", "provider", providerName)
+		return sendJSONError(re, err.Error())
+	}
+
+	// Past the spend budget, requests that neither ask for a provider nor have
+	// one pinned by their app try the cheapest provider first
+	if providerName == "" {
+		if names, chain := budgetProvider(config, providers); chain != nil {
+			logger.Info("Spend budget exceeded, trying the cheapest provider first", "providers", names)
+			provider = chain
+		}
+	}
+
+	// Get optional preprocess chain from form (use the app's or the server default if not specified)
+	preprocess := config.Preprocess
+	preprocessSpec := re.Request.FormValue("preprocess")
+	if preprocessSpec == "" {
+		preprocessSpec = appProfile.Preprocess
+	}
+	if preprocessSpec != "" {
+		preprocess, err = audio.ParsePreprocessConfig(preprocessSpec)
+		if err != nil {
+			logger.Error("Invalid preprocess specified", "preprocess", preprocessSpec, "error", err)
+			return sendJSONError(re, fmt.Sprintf("Invalid preprocess: %v", err))
+		}
+	}
+
+	// Get optional vocabulary from form, on top of the app's
+	requestTerms := vocabulary.Parse(re.Request.Form["vocabulary"])
+	terms := vocabulary.Merge(requestTerms, appProfile.Vocabulary)
+	if err := vocabulary.Validate(terms); err != nil {
+		return sendJSONError(re, fmt.Sprintf("Invalid vocabulary: %v", err))
+	}
+
+	// Get optional postprocess pipeline from form (use the app's or the server default if not specified)
+	pipeline, err := postprocessPipeline(config, appProfile, re.Request.FormValue("postprocess"))
+	if err != nil {
+		logger.Error("Invalid postprocess specified", "postprocess", re.Request.FormValue("postprocess"), "error", err)
+		return sendJSONError(re, fmt.Sprintf("Invalid postprocess: %v", err))
+	}
+
+	// Get optional output_format from form (use the app's format or JSON if not specified)
+	outputFormat := re.Request.FormValue("output_format")
+	if outputFormat == "" {
+		outputFormat = appProfile.OutputFormat
+	}
+	switch outputFormat {
+	case "":
+		outputFormat = profile.OutputJSON
+	case profile.OutputJSON, profile.OutputText:
+	default:
+		return sendJSONError(re, fmt.Sprintf("Invalid output_format: %s. Valid options: json, text", outputFormat))
+	}
+
+	// Get optional route flag from form (match the transcript against the apps' commands)
+	route := false
+	if value := re.Request.FormValue("route"); value != "" {
+		if route, err = strconv.ParseBool(value); err != nil {
+			return sendJSONError(re, fmt.Sprintf("Invalid route: %s. Valid options: true, false", value))
 		}
 	}
 
@@ -101,32 +191,126 @@ func HandleSpeak(re *core.RequestEvent, app core.App, defaultProvider transcript
 		logger.Error("Audio file is empty")
 		return sendJSONError(re, "audio file is empty")
 	}
+	uploadDuration := time.Since(requestStart)
+
+	// Raw PCM is always 16kHz mono 16-bit; WAV metadata is taken from its header
+	metadata := transcription.AudioMetadata{
+		Format:        transcription.AudioFormat(fileFormat),
+		SampleRate:    16000,
+		Channels:      1,
+		BitsPerSample: 16,
+	}
 
-	// Calculate audio length (assuming 16kHz, 1 channel, 16-bit PCM)
-	audioLength := calculateAudioLength(len(audioData))
+	// Decode audio to get the exact duration from the samples, not the byte count
+	pcm, err := transcription.DecodeAudio(audioData, metadata)
+	if err != nil {
+		logger.Error("Failed to decode audio", "file_format", fileFormat, "error", err)
+		return sendJSONError(re, fmt.Sprintf("Invalid audio data: %v", err))
+	}
+	metadata.SampleRate = pcm.SampleRate
+	metadata.Channels = pcm.Channels
+	audioDuration := pcm.Duration()
+
+	// Diagnose the recording and refuse to pay a provider for silence
+	quality := audio.Analyze(pcm)
+	logger.Info("Audio analyzed", "rms_dbfs", quality.RMSDBFS, "peak_dbfs", quality.PeakDBFS, "snr_db", quality.SNRDB, "speech_ratio", quality.SpeechRatio)
+	if quality.Silent {
+		logger.Error("Audio contains no speech", "duration_ms", audioDuration.Milliseconds())
+		return sendJSONError(re, "audio is silent: no speech detected")
+	}
+
+	// Refuse uploads that don't fit in the caller's remaining audio quota
+	if limitErr := config.Limiter.CheckAudio(re, audioDuration); limitErr != nil {
+		logger.Info("Audio quota exceeded", "duration_ms", audioDuration.Milliseconds(), "error", limitErr.Message)
+		re.Response.Header().Set("Retry-After", limitErr.RetryAfterSeconds())
+		return sendJSONErrorStatus(re, http.StatusTooManyRequests, limitErr.Message)
+	}
 
 	// Use provider to transcribe audio
-	logger.Info("Starting audio transcription", "language_code", languageCode, "file_format", fileFormat)
-	result, err := provider.Transcribe(audioData, transcription.TranscriptionOptions{
-		LanguageCode: languageCode,
-		Metadata: transcription.AudioMetadata{
-			Format:        transcription.AudioFormat(fileFormat),
-			SampleRate:    16000,
-			Channels:      1,
-			BitsPerSample: 16,
-		},
-	})
+	logger.Info("Starting audio transcription", "language_code", languageCode, "file_format", fileFormat, "duration_ms", audioDuration.Milliseconds())
+	transcribeStart := time.Now()
+	result, err := transcribeAudio(provider, pcm, audioData, metadata, preprocess, languageCode, terms)
+	transcribeDuration := time.Since(transcribeStart)
 	if err != nil {
 		logger.Error("Failed to transcribe audio", "error", err)
+		recordFailure(app, recording{
+			Audio:             audioData,
+			Metadata:          metadata,
+			RequestedLanguage: languageCode,
+			Preprocess:        preprocessSpec,
+			Postprocess:       re.Request.FormValue("postprocess"),
+			Vocabulary:        requestTerms,
+			Duration:          audioDuration,
+			Quality:           quality,
+			UploadTime:        uploadDuration,
+			TranscribeTime:    transcribeDuration,
+			Client:            newClientInfo(re),
+			App:               callerApp(re),
+			Owner:             callerUser(re),
+		}, providerName, err)
 		return sendJSONError(re, fmt.Sprintf("Failed to transcribe audio: %v", err))
 	}
 
-	// Send JSON response immediately after transcription
+	config.Limiter.RecordAudio(re, audioDuration)
+
+	text, applied := pipeline.Run(result.Text, transcriptLanguage(result, languageCode))
+
+	// The transcript row is written before responding; compression and audio
+	// storage run on the background persistence queue
+	transcriptID := persistRecording(app, config, recording{
+		Audio:             audioData,
+		Metadata:          metadata,
+		Text:              text,
+		Language:          result.LanguageCode,
+		RequestedLanguage: languageCode,
+		Provider:          result.Provider,
+		FailedAttempts:    result.FailedAttempts,
+		Preprocess:        preprocessSpec,
+		Duration:          audioDuration,
+		Quality:           quality,
+		UploadTime:        uploadDuration,
+		TranscribeTime:    transcribeDuration,
+		Client:            newClientInfo(re),
+		App:               callerApp(re),
+		Owner:             callerUser(re),
+		DiscardAudio:      appProfile.DiscardAudio,
+	})
+
+	recordUsage(config, result, usage.Entry{
+		Duration:   audioDuration,
+		Transcript: transcriptID,
+		App:        callerApp(re),
+		Owner:      callerUser(re),
+	})
+
+	var match *intent.Match
+	if route {
+		match = routeIntent(re, config, text, transcriptLanguage(result, languageCode), transcriptID)
+	}
+
+	if outputFormat == profile.OutputText {
+		re.Response.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		re.Response.WriteHeader(http.StatusOK)
+		re.Response.Write([]byte(text))
+		return nil
+	}
+
 	response := map[string]any{
-		"text":          result.Text,
-		"language_code": result.LanguageCode,
-		"audio_length":  audioLength,
-		"timestamp":     time.Now().Unix(),
+		"id":              transcriptID,
+		"text":            text,
+		"language_code":   result.LanguageCode,
+		"provider":        result.Provider,
+		"audio_length":    audioDuration.Seconds(),
+		"audio_length_ms": audioDuration.Milliseconds(),
+		"quality":         quality,
+		"timestamp":       time.Now().Unix(),
+	}
+	if pipeline.Enabled() {
+		response["raw_text"] = result.Text
+		response["postprocess"] = applied
+	}
+	if route {
+		response["intent"] = match
 	}
 
 	jsonData, err := json.Marshal(response)
@@ -137,51 +321,116 @@ func HandleSpeak(re *core.RequestEvent, app core.App, defaultProvider transcript
 	re.Response.WriteHeader(http.StatusOK)
 	re.Response.Write(jsonData)
 
-	// Handle compression and database storage asynchronously
-	go saveAudioToDatabase(app, audioData, result.Text)
-
 	return nil
 }
 
-// saveAudioToDatabase compresses audio data and stores it in the PocketBase database.
-// This function runs asynchronously in a goroutine to avoid blocking the response.
-// The audio is compressed and base64-encoded before storage in the 'silence' collection.
-func saveAudioToDatabase(app core.App, audioData []byte, transcriptionText string) {
-	logger.Info("Starting background compression and database storage")
+// callerApp returns the ID of the app whose API key made the request, or "".
+func callerApp(re *core.RequestEvent) string {
+	if app := auth.RequestApp(re); app != nil {
+		return app.Id
+	}
+	return ""
+}
 
-	// Compress the audio data
-	logger.Info("Compressing audio data", "original_size", len(audioData))
-	compressedData, err := compression.CompressAudio(audioData)
-	if err != nil {
-		logger.Error("Failed to compress audio in background", "error", err)
-		return
+// callerUser returns the ID of the signed-in user who made the request, or "".
+func callerUser(re *core.RequestEvent) string {
+	if user := auth.RequestUser(re); user != nil {
+		return user.Id
 	}
+	return ""
+}
 
-	// Encode compressed audio to base64
-	base64Data := base64.StdEncoding.EncodeToString(compressedData)
+// selectProvider returns the provider with the given name, or the default
+// chain when name is empty.
+func selectProvider(name string, defaultProvider transcription.TranscriptionProvider, providers map[transcription.ProviderName]transcription.TranscriptionProvider) (transcription.TranscriptionProvider, error) {
+	if name == "" {
+		return defaultProvider, nil
+	}
+	if p, ok := providers[transcription.ProviderName(name)]; ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("Invalid provider: %s. Valid options: elevenlabs, chutes", name)
+}
 
-	// Save compressed audio and transcription to database using PocketBase
-	collection, err := app.FindCollectionByNameOrId("silence")
-	if err != nil {
-		logger.Error("Failed to find silence collection in background", "error", err)
-		return
+// postprocessPipeline returns the text pipeline of a request: the spec from
+// the request, else the app's, else the server default.
+func postprocessPipeline(config Config, appProfile profile.Profile, spec string) (postprocess.Pipeline, error) {
+	switch {
+	case spec != "":
+		return postprocess.Parse(spec)
+	case appProfile.Postprocess != nil:
+		return postprocess.ParseJSON(appProfile.Postprocess)
 	}
+	return config.Postprocess, nil
+}
 
-	record := core.NewRecord(collection)
-	record.Set("audio", base64Data)
-	record.Set("result", transcriptionText)
+// transcriptLanguage is the language postprocessing assumes: the detected
+// language, else the requested one, else "" for unknown.
+func transcriptLanguage(result *transcription.TranscriptionResult, requested string) string {
+	if result.LanguageCode != "" {
+		return result.LanguageCode
+	}
+	if requested == "auto" {
+		return ""
+	}
+	return requested
+}
 
-	if err := app.Save(record); err != nil {
-		logger.Error("Failed to save record to database in background", "error", err)
-		return
+// transcribeAudio preprocesses decoded audio as configured and sends it to the
+// provider. Without preprocessing the original upload is sent unchanged. When
+// the provider that answered couldn't be biased towards the vocabulary, close
+// spellings in its text are corrected to the vocabulary terms.
+func transcribeAudio(provider transcription.TranscriptionProvider, pcm *audio.PCM, audioData []byte, metadata transcription.AudioMetadata, preprocess audio.PreprocessConfig, languageCode string, terms []string) (*transcription.TranscriptionResult, error) {
+	transcribeData, transcribeMetadata := audioData, metadata
+	if preprocess.Enabled() {
+		var err error
+		pcm = audio.Preprocess(pcm, preprocess)
+		transcribeData, transcribeMetadata, err = transcription.EncodeAudio(pcm, metadata.Format)
+		if err != nil {
+			return nil, fmt.Errorf("preprocess audio: %w", err)
+		}
+		logger.Info("Audio preprocessed", "config", fmt.Sprintf("%+v", preprocess))
+	}
+
+	result, err := provider.Transcribe(transcribeData, transcription.TranscriptionOptions{
+		LanguageCode: languageCode,
+		Metadata:     transcribeMetadata,
+		Vocabulary:   terms,
+		PCM:          pcm,
+	})
+	if err != nil || len(terms) == 0 || result.Biased {
+		return result, err
 	}
 
-	logger.Info("Background processing completed successfully", "record_id", record.Id)
+	var corrections []vocabulary.Correction
+	result.Text, corrections = vocabulary.Correct(result.Text, terms)
+	if len(corrections) > 0 {
+		logger.Info("Transcript corrected to vocabulary", "provider", result.Provider, "corrections", corrections)
+	}
+	return result, nil
+}
+
+// sendJSON sends a JSON response with the given status code.
+func sendJSON(re *core.RequestEvent, status int, data any) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return sendJSONErrorStatus(re, http.StatusInternalServerError, "Failed to encode response")
+	}
+
+	re.Response.Header().Set("Content-Type", "application/json")
+	re.Response.WriteHeader(status)
+	re.Response.Write(jsonData)
+	return nil
 }
 
 // sendJSONError sends a JSON-formatted error response with a 400 status code.
 // The response includes the error message and current timestamp.
 func sendJSONError(re *core.RequestEvent, message string) error {
+	return sendJSONErrorStatus(re, http.StatusBadRequest, message)
+}
+
+// sendJSONErrorStatus sends a JSON-formatted error response with the given status code.
+func sendJSONErrorStatus(re *core.RequestEvent, status int, message string) error {
 	errorData := map[string]any{
 		"error":     message,
 		"timestamp": time.Now().Unix(),
@@ -194,18 +443,8 @@ func sendJSONError(re *core.RequestEvent, message string) error {
 		return nil
 	}
 
-	re.Response.WriteHeader(http.StatusBadRequest)
+	re.Response.Header().Set("Content-Type", "application/json")
+	re.Response.WriteHeader(status)
 	re.Response.Write(jsonData)
 	return nil
 }
-
-// calculateAudioLength calculates audio duration in seconds from raw audio data size.
-// Assumes 16kHz sample rate, 1 channel (mono), and 16-bit depth.
-func calculateAudioLength(dataSize int) int {
-	sampleRate := 16000
-	channels := 1
-	bitsPerSample := 16
-	bytesPerSample := bitsPerSample / 8
-	totalSamples := dataSize / (bytesPerSample * channels)
-	return int(math.Ceil(float64(totalSamples) / float64(sampleRate)))
-}
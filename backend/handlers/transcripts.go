@@ -0,0 +1,400 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"silence-backend/auth"
+	"silence-backend/logger"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	defaultTranscriptLimit = 20
+	maxTranscriptLimit     = 100
+)
+
+// Transcript is the compact view of a stored transcription. It never contains
+// the audio itself, only a link to download it.
+type Transcript struct {
+	ID         string             `json:"id" example:"ead6abyjn82q49r"`
+	Text       string             `json:"text" example:"Hello world, this is a transcription"`
+	Language   string             `json:"language" example:"en"`
+	Provider   string             `json:"provider" example:"elevenlabs"`
+	App        string             `json:"app,omitempty" example:"k2l3m4n5o6p7q8r"`
+	Owner      string             `json:"owner,omitempty" example:"u9v8w7x6y5z4a3b"`
+	DurationMs int64              `json:"duration_ms" example:"14520"`
+	Status     string             `json:"status" example:"stored" enums:"pending_audio,stored,audio_failed,discarded,expired,legacy_audio_failed"`
+	AudioURL   string             `json:"audio_url,omitempty" example:"/transcripts/ead6abyjn82q49r/audio"`
+	Created    string             `json:"created" example:"2026-10-18 13:31:13.352Z"`
+	Details    *TranscriptDetails `json:"details,omitempty"`
+}
+
+// TranscriptDetails holds the full request metadata of a transcript.
+type TranscriptDetails struct {
+	Codec             string        `json:"codec" example:"opus"`
+	RequestedLanguage string        `json:"requested_language" example:"auto"`
+	FailedAttempts    types.JSONRaw `json:"failed_attempts" swaggertype:"array,object"`
+	Preprocess        string        `json:"preprocess" example:"default"`
+	InputFormat       string        `json:"input_format" example:"pcm_s16le_16"`
+	SampleRate        int           `json:"sample_rate" example:"16000"`
+	Channels          int           `json:"channels" example:"1"`
+	SizeBytes         int           `json:"size_bytes" example:"464640"`
+	Quality           types.JSONRaw `json:"quality" swaggertype:"object"`
+	UploadMs          int64         `json:"upload_ms" example:"35"`
+	TranscribeMs      int64         `json:"transcribe_ms" example:"1840"`
+	CompressMs        int64         `json:"compress_ms" example:"120"`
+	Client            types.JSONRaw `json:"client" swaggertype:"object"`
+	Updated           string        `json:"updated" example:"2026-10-18 13:31:13.352Z"`
+}
+
+// TranscriptListResponse is a page of transcripts, newest first.
+type TranscriptListResponse struct {
+	Items      []Transcript `json:"items"`
+	NextCursor string       `json:"next_cursor,omitempty" example:"MjAyNi0xMC0xOCAxMzozMToxMy4zNTJafGVhZDZhYnlqbjgycTQ5cg"`
+}
+
+// HandleListTranscripts godoc
+// @Summary List transcripts
+// @Description Returns stored transcripts, newest first, without audio. Use 'next_cursor' from the response as 'cursor' to fetch the next page.
+// @Tags Transcripts
+// @Produce json
+// @Param from query string false "Only transcripts created at or after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string false "Only transcripts created before this time (RFC 3339 or YYYY-MM-DD, exclusive)"
+// @Param language query string false "Detected language code, e.g. 'en'"
+// @Param provider query string false "Provider that produced the transcript: 'elevenlabs' or 'chutes'"
+// @Param app query string false "ID of the app the transcript is attributed to"
+// @Param owner query string false "ID of the user who owns the transcript (ignored for user tokens, which only see their own)"
+// @Param status query string false "Audio storage status: 'pending_audio', 'stored', 'audio_failed', 'discarded', 'expired' or 'legacy_audio_failed'"
+// @Param limit query int false "Page size, 1-100 (default 20)"
+// @Param cursor query string false "Cursor from a previous response"
+// @Param view query string false "'compact' (default) or 'full' to include request details"
+// @Success 200 {object} TranscriptListResponse "Page of transcripts"
+// @Failure 400 {object} ErrorResponse "Invalid filter or cursor"
+// @Failure 401 {object} ErrorResponse "Missing or invalid auth token"
+// @Security BearerAuth
+// @Router /transcripts [get]
+func HandleListTranscripts(re *core.RequestEvent, app core.App) error {
+	query := re.Request.URL.Query()
+
+	filter, err := parseTranscriptFilter(re)
+	if err != nil {
+		return sendJSONError(re, err.Error())
+	}
+
+	limit := defaultTranscriptLimit
+	if raw := query.Get("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxTranscriptLimit {
+			return sendJSONError(re, fmt.Sprintf("limit must be between 1 and %d", maxTranscriptLimit))
+		}
+	}
+
+	expr, params := filter.expression()
+	if raw := query.Get("cursor"); raw != "" {
+		created, id, err := decodeCursor(raw)
+		if err != nil {
+			return sendJSONError(re, "invalid cursor")
+		}
+		expr = joinFilters(expr, "(created < {:cursorCreated} || (created = {:cursorCreated} && id < {:cursorId}))")
+		params["cursorCreated"] = created
+		params["cursorId"] = id
+	}
+
+	// Fetch one extra record to know whether there is a next page
+	records, err := app.FindRecordsByFilter("silence", expr, "-created,-id", limit+1, 0, params)
+	if err != nil {
+		logger.Error("Failed to list transcripts", "error", err)
+		return sendJSONErrorStatus(re, http.StatusInternalServerError, "failed to list transcripts")
+	}
+
+	response := TranscriptListResponse{Items: []Transcript{}}
+	if len(records) > limit {
+		records = records[:limit]
+		last := records[limit-1]
+		response.NextCursor = encodeCursor(last.GetString("created"), last.Id)
+	}
+
+	full := query.Get("view") == "full"
+	for _, record := range records {
+		response.Items = append(response.Items, newTranscript(record, full))
+	}
+
+	return sendJSON(re, http.StatusOK, response)
+}
+
+// HandleGetTranscript godoc
+// @Summary Get a transcript
+// @Description Returns one transcript with its full request details. The audio is available at 'audio_url'.
+// @Tags Transcripts
+// @Produce json
+// @Param id path string true "Transcript ID"
+// @Success 200 {object} Transcript "Transcript"
+// @Failure 401 {object} ErrorResponse "Missing or invalid auth token"
+// @Failure 404 {object} ErrorResponse "Transcript not found"
+// @Security BearerAuth
+// @Router /transcripts/{id} [get]
+func HandleGetTranscript(re *core.RequestEvent, app core.App) error {
+	record, err := findTranscript(re, app)
+	if err != nil {
+		return sendJSONErrorStatus(re, http.StatusNotFound, "transcript not found")
+	}
+
+	return sendJSON(re, http.StatusOK, newTranscript(record, true))
+}
+
+// HandleDeleteTranscript godoc
+// @Summary Delete a transcript
+// @Description Deletes a transcript together with its stored audio file.
+// @Tags Transcripts
+// @Param id path string true "Transcript ID"
+// @Success 204 "Transcript deleted"
+// @Failure 401 {object} ErrorResponse "Missing or invalid auth token"
+// @Failure 404 {object} ErrorResponse "Transcript not found"
+// @Security BearerAuth
+// @Router /transcripts/{id} [delete]
+func HandleDeleteTranscript(re *core.RequestEvent, app core.App) error {
+	record, err := findTranscript(re, app)
+	if err != nil {
+		return sendJSONErrorStatus(re, http.StatusNotFound, "transcript not found")
+	}
+
+	// PocketBase removes the record's files once the delete is committed
+	if err := app.Delete(record); err != nil {
+		logger.Error("Failed to delete transcript", "record_id", record.Id, "error", err)
+		return sendJSONErrorStatus(re, http.StatusInternalServerError, "failed to delete transcript")
+	}
+
+	logger.Info("Transcript deleted", "record_id", record.Id)
+	return re.NoContent(http.StatusNoContent)
+}
+
+// findTranscript loads the transcript named in the path. A transcript of another
+// app or user is reported as missing to API keys and users.
+func findTranscript(re *core.RequestEvent, app core.App) (*core.Record, error) {
+	record, err := app.FindRecordById("silence", re.Request.PathValue("id"))
+	if err != nil {
+		return nil, err
+	}
+	if caller := auth.RequestApp(re); caller != nil && record.GetString("app") != caller.Id {
+		return nil, fmt.Errorf("transcript %s belongs to another app", record.Id)
+	}
+	if user := auth.RequestUser(re); user != nil && record.GetString("owner") != user.Id {
+		return nil, fmt.Errorf("transcript %s belongs to another user", record.Id)
+	}
+	return record, nil
+}
+
+// newTranscript maps a silence record to its API representation.
+func newTranscript(record *core.Record, full bool) Transcript {
+	t := Transcript{
+		ID:         record.Id,
+		Text:       record.GetString("result"),
+		Language:   record.GetString("language"),
+		Provider:   record.GetString("provider"),
+		App:        record.GetString("app"),
+		Owner:      record.GetString("owner"),
+		DurationMs: int64(record.GetInt("duration_ms")),
+		Status:     record.GetString("status"),
+		Created:    record.GetString("created"),
+	}
+	if record.GetString("audio") != "" {
+		t.AudioURL = "/transcripts/" + record.Id + "/audio"
+	}
+
+	if full {
+		t.Details = &TranscriptDetails{
+			Codec:             record.GetString("codec"),
+			RequestedLanguage: record.GetString("requested_language"),
+			FailedAttempts:    jsonValue(record, "failed_attempts"),
+			Preprocess:        record.GetString("preprocess"),
+			InputFormat:       record.GetString("input_format"),
+			SampleRate:        record.GetInt("sample_rate"),
+			Channels:          record.GetInt("channels"),
+			SizeBytes:         record.GetInt("size_bytes"),
+			Quality:           jsonValue(record, "quality"),
+			UploadMs:          int64(record.GetInt("upload_ms")),
+			TranscribeMs:      int64(record.GetInt("transcribe_ms")),
+			CompressMs:        int64(record.GetInt("compress_ms")),
+			Client:            jsonValue(record, "client"),
+			Updated:           record.GetString("updated"),
+		}
+	}
+
+	return t
+}
+
+// jsonValue returns a JSON field as raw JSON, or nil when it is unset.
+func jsonValue(record *core.Record, field string) types.JSONRaw {
+	raw, _ := record.Get(field).(types.JSONRaw)
+	if len(raw) == 0 {
+		return nil
+	}
+	return raw
+}
+
+// transcriptFilter holds the filters shared by the listing and search endpoints.
+type transcriptFilter struct {
+	From     time.Time
+	To       time.Time
+	Language string
+	Provider string
+	App      string
+	Owner    string
+	Status   string
+}
+
+// parseTranscriptFilter reads filters from the query string. Requests made with
+// an app's API key only ever see that app's transcripts, and signed-in users
+// only their own.
+func parseTranscriptFilter(re *core.RequestEvent) (transcriptFilter, error) {
+	query := re.Request.URL.Query()
+	filter := transcriptFilter{
+		Language: query.Get("language"),
+		Provider: query.Get("provider"),
+		App:      query.Get("app"),
+		Owner:    query.Get("owner"),
+		Status:   query.Get("status"),
+	}
+	if caller := auth.RequestApp(re); caller != nil {
+		filter.App = caller.Id
+	}
+	if user := auth.RequestUser(re); user != nil {
+		filter.Owner = user.Id
+	}
+
+	switch filter.Status {
+	case "", statusPendingAudio, statusStored, statusAudioFailed, statusDiscarded, statusExpired, statusLegacyAudioFailed:
+	default:
+		return filter, fmt.Errorf("invalid status: %q, valid options: pending_audio, stored, audio_failed, discarded, expired, legacy_audio_failed", filter.Status)
+	}
+
+	var err error
+	if raw := query.Get("from"); raw != "" {
+		if filter.From, err = parseFilterTime(raw); err != nil {
+			return filter, fmt.Errorf("invalid from: %v", err)
+		}
+	}
+	if raw := query.Get("to"); raw != "" {
+		if filter.To, err = parseFilterTime(raw); err != nil {
+			return filter, fmt.Errorf("invalid to: %v", err)
+		}
+	}
+
+	return filter, nil
+}
+
+// parseFilterTime accepts RFC 3339 timestamps and plain dates (midnight UTC).
+func parseFilterTime(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return t, fmt.Errorf("expected RFC 3339 timestamp or YYYY-MM-DD, got %q", raw)
+	}
+	return t, nil
+}
+
+// expression builds a PocketBase filter expression and its parameters.
+func (f transcriptFilter) expression() (string, dbx.Params) {
+	var parts []string
+	params := dbx.Params{}
+
+	if !f.From.IsZero() {
+		parts = append(parts, "created >= {:from}")
+		params["from"] = formatDateTime(f.From)
+	}
+	if !f.To.IsZero() {
+		parts = append(parts, "created < {:to}")
+		params["to"] = formatDateTime(f.To)
+	}
+	if f.Language != "" {
+		parts = append(parts, "language = {:language}")
+		params["language"] = f.Language
+	}
+	if f.Provider != "" {
+		parts = append(parts, "provider = {:provider}")
+		params["provider"] = f.Provider
+	}
+	if f.App != "" {
+		parts = append(parts, "app = {:app}")
+		params["app"] = f.App
+	}
+	if f.Owner != "" {
+		parts = append(parts, "owner = {:owner}")
+		params["owner"] = f.Owner
+	}
+	if f.Status != "" {
+		parts = append(parts, "status = {:status}")
+		params["status"] = f.Status
+	}
+
+	return strings.Join(parts, " && "), params
+}
+
+// sqlConditions builds the same filters as expression for raw queries,
+// with columns qualified by the given table alias.
+func (f transcriptFilter) sqlConditions(alias string) dbx.Expression {
+	var conditions []dbx.Expression
+	if !f.From.IsZero() {
+		conditions = append(conditions, dbx.NewExp(alias+".created >= {:from}", dbx.Params{"from": formatDateTime(f.From)}))
+	}
+	if !f.To.IsZero() {
+		conditions = append(conditions, dbx.NewExp(alias+".created < {:to}", dbx.Params{"to": formatDateTime(f.To)}))
+	}
+	if f.Language != "" {
+		conditions = append(conditions, dbx.HashExp{alias + ".language": f.Language})
+	}
+	if f.Provider != "" {
+		conditions = append(conditions, dbx.HashExp{alias + ".provider": f.Provider})
+	}
+	if f.App != "" {
+		conditions = append(conditions, dbx.HashExp{alias + ".app": f.App})
+	}
+	if f.Owner != "" {
+		conditions = append(conditions, dbx.HashExp{alias + ".owner": f.Owner})
+	}
+	if f.Status != "" {
+		conditions = append(conditions, dbx.HashExp{alias + ".status": f.Status})
+	}
+	return dbx.And(conditions...)
+}
+
+// formatDateTime formats a time the way PocketBase stores autodate fields.
+func formatDateTime(t time.Time) string {
+	return t.UTC().Format(types.DefaultDateLayout)
+}
+
+// joinFilters combines two filter expressions with AND.
+func joinFilters(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return "(" + a + ") && " + b
+}
+
+// encodeCursor packs the sort key of the last returned record into an opaque cursor.
+func encodeCursor(created, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(created + "|" + id))
+}
+
+// decodeCursor unpacks a cursor produced by encodeCursor.
+func decodeCursor(cursor string) (created, id string, err error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", "", err
+	}
+	created, id, ok := strings.Cut(string(data), "|")
+	if !ok || id == "" {
+		return "", "", fmt.Errorf("malformed cursor")
+	}
+	return created, id, nil
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"silence-backend/auth"
+	"silence-backend/logger"
+	"silence-backend/transcription"
+	"silence-backend/usage"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// UsageRow is aggregated usage for one group. Fields that aren't grouped by are empty.
+type UsageRow struct {
+	Day             string  `json:"day,omitempty" db:"day" example:"2026-10-18"`
+	App             string  `json:"app,omitempty" db:"app" example:"k2l3m4n5o6p7q8r"`
+	User            string  `json:"user,omitempty" db:"owner" example:"u9v8w7x6y5z4a3b"`
+	Provider        string  `json:"provider,omitempty" db:"provider" example:"elevenlabs"`
+	Requests        int     `json:"requests" db:"requests" example:"42"` // Billed provider requests; a chunked transcription makes one per chunk
+	AudioSeconds    float64 `json:"audio_seconds" db:"audio_seconds" example:"1830.5"`
+	BillableSeconds float64 `json:"billable_seconds" db:"billable_seconds" example:"1852"`
+	CostUSD         float64 `json:"cost_usd" db:"cost_usd" example:"0.2057"`
+}
+
+// UsageResponse is usage grouped as requested, plus the total over all groups.
+type UsageResponse struct {
+	GroupBy []string   `json:"group_by" example:"day,provider"`
+	Items   []UsageRow `json:"items"`
+	Total   UsageRow   `json:"total"`
+}
+
+// usageGroups maps group_by values to their SQL expressions.
+var usageGroups = map[string]string{
+	"day":      "substr(created, 1, 10)",
+	"app":      "app",
+	"user":     "owner",
+	"provider": "provider",
+}
+
+// usageColumns lists grouping columns in output order, with their result names.
+var usageColumns = []struct{ group, column string }{
+	{"day", "day"},
+	{"app", "app"},
+	{"user", "owner"},
+	{"provider", "provider"},
+}
+
+// HandleUsage godoc
+// @Summary Usage and estimated cost
+// @Description Aggregates billable audio and estimated provider cost of successful transcriptions, grouped by any of day, app, user and provider. Costs are estimates from the configured price table. Users see only their own usage and API keys only their app's.
+// @Tags Usage
+// @Produce json
+// @Param group_by query string false "Comma-separated grouping: 'day', 'app', 'user', 'provider' (default 'day')"
+// @Param from query string false "Only usage at or after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string false "Only usage before this time (RFC 3339 or YYYY-MM-DD, exclusive)"
+// @Param app query string false "Only usage of this app"
+// @Param user query string false "Only usage of this user"
+// @Param provider query string false "Only usage of this provider: 'elevenlabs' or 'chutes'"
+// @Success 200 {object} UsageResponse "Aggregated usage"
+// @Failure 400 {object} ErrorResponse "Invalid grouping or filter"
+// @Failure 401 {object} ErrorResponse "Missing or invalid auth token"
+// @Failure 403 {object} ErrorResponse "API key lacks the 'usage:read' scope"
+// @Security BearerAuth
+// @Router /usage [get]
+func HandleUsage(re *core.RequestEvent, app core.App) error {
+	query := re.Request.URL.Query()
+
+	groupBy := []string{"day"}
+	if raw := query.Get("group_by"); raw != "" {
+		groupBy = nil
+		for _, part := range strings.Split(raw, ",") {
+			part = strings.TrimSpace(part)
+			if _, ok := usageGroups[part]; !ok {
+				return sendJSONError(re, fmt.Sprintf("invalid group_by: %q, valid options: day, app, user, provider", part))
+			}
+			if !slices.Contains(groupBy, part) {
+				groupBy = append(groupBy, part)
+			}
+		}
+	}
+
+	where, err := usageConditions(re)
+	if err != nil {
+		return sendJSONError(re, err.Error())
+	}
+
+	var columns, groupExprs []string
+	for _, c := range usageColumns {
+		if slices.Contains(groupBy, c.group) {
+			columns = append(columns, usageGroups[c.group]+" AS "+c.column)
+			groupExprs = append(groupExprs, usageGroups[c.group])
+		}
+	}
+	columns = append(columns,
+		"COUNT(*) AS requests",
+		"COALESCE(SUM(audio_ms), 0) / 1000.0 AS audio_seconds",
+		"COALESCE(SUM(billable_seconds), 0) AS billable_seconds",
+		"COALESCE(SUM(cost_usd), 0) AS cost_usd",
+	)
+
+	// Newest days first, then the most expensive groups
+	orderBy := []string{"cost_usd DESC"}
+	if slices.Contains(groupBy, "day") {
+		orderBy = append([]string{"day DESC"}, orderBy...)
+	}
+
+	response := UsageResponse{GroupBy: groupBy, Items: []UsageRow{}}
+	err = app.DB().
+		Select(columns...).
+		From(usage.Collection).
+		Where(where).
+		GroupBy(groupExprs...).
+		OrderBy(orderBy...).
+		All(&response.Items)
+	if err != nil {
+		logger.Error("Failed to aggregate usage", "error", err)
+		return sendJSONErrorStatus(re, http.StatusInternalServerError, "failed to aggregate usage")
+	}
+
+	for _, row := range response.Items {
+		response.Total.Requests += row.Requests
+		response.Total.AudioSeconds += row.AudioSeconds
+		response.Total.BillableSeconds += row.BillableSeconds
+		response.Total.CostUSD += row.CostUSD
+	}
+
+	return sendJSON(re, http.StatusOK, response)
+}
+
+// usageConditions builds the filters of a usage query, limited to the caller's
+// own usage for users and API keys.
+func usageConditions(re *core.RequestEvent) (dbx.Expression, error) {
+	query := re.Request.URL.Query()
+	var conditions []dbx.Expression
+
+	for _, bound := range []struct{ param, op string }{{"from", ">="}, {"to", "<"}} {
+		raw := query.Get(bound.param)
+		if raw == "" {
+			continue
+		}
+		t, err := parseFilterTime(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", bound.param, err)
+		}
+		conditions = append(conditions, dbx.NewExp("created "+bound.op+" {:"+bound.param+"}", dbx.Params{bound.param: formatDateTime(t)}))
+	}
+
+	appID, owner := query.Get("app"), query.Get("user")
+	if caller := auth.RequestApp(re); caller != nil {
+		appID = caller.Id
+	}
+	if user := auth.RequestUser(re); user != nil {
+		owner = user.Id
+	}
+	if appID != "" {
+		conditions = append(conditions, dbx.HashExp{"app": appID})
+	}
+	if owner != "" {
+		conditions = append(conditions, dbx.HashExp{"owner": owner})
+	}
+	if provider := query.Get("provider"); provider != "" {
+		conditions = append(conditions, dbx.HashExp{"provider": provider})
+	}
+
+	return dbx.And(conditions...), nil
+}
+
+// recordUsage meters the successful provider requests behind a result, one per
+// chunk of a chunked transcription, each against the provider that served it.
+// entry.Duration is the whole audio. Failing to meter never fails the request.
+func recordUsage(config Config, result *transcription.TranscriptionResult, entry usage.Entry) {
+	for _, call := range result.ProviderCalls(entry.Duration) {
+		entry.Provider, entry.Duration = call.Provider, call.Duration
+		if err := config.Meter.Record(entry); err != nil {
+			logger.Error("Failed to record usage", "provider", entry.Provider, "error", err)
+		}
+	}
+}
+
+// budgetProvider returns a chain of the providers from cheapest to most
+// expensive once the spend budget is exceeded, or nil while within budget.
+// The pricier providers stay as fallbacks, so a failing cheap provider
+// doesn't fail the request.
+func budgetProvider(config Config, providers map[transcription.ProviderName]transcription.TranscriptionProvider) ([]transcription.ProviderName, transcription.TranscriptionProvider) {
+	if !config.Meter.OverBudget() || len(providers) == 0 {
+		return nil, nil
+	}
+
+	names := make([]transcription.ProviderName, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	names = config.Meter.Prices().ByPrice(names)
+
+	chain := make([]transcription.TranscriptionProvider, len(names))
+	for i, name := range names {
+		chain[i] = providers[name]
+	}
+	return names, transcription.NewProviderChain(chain...)
+}
@@ -0,0 +1,211 @@
+// Package intent routes transcripts to apps. Apps declare the voice commands
+// they handle in their description, with trigger phrases holding {slots};
+// a transcript that matches a trigger comes back with the app, the command
+// and the slot values, and the app's webhook is told about it. The webhook
+// secret lives in the app's hidden 'webhook_secret' field.
+package intent
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"silence-backend/logger"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Ways a trigger is matched against a transcript.
+const (
+	MatchKeyword = "keyword" // The trigger's words in order, case- and punctuation-insensitive (default)
+	MatchRegex   = "regex"   // The trigger is a regular expression; named groups are slots
+	MatchFuzzy   = "fuzzy"   // Like keyword, but words may be misspelled or sound alike
+)
+
+// Description is what an app declares in its 'description' field. Other keys
+// are left alone, and a description that isn't a JSON object declares nothing.
+type Description struct {
+	Commands []Command `json:"commands,omitempty"`
+	Webhook  string    `json:"webhook,omitempty"` // URL that matched commands are posted to
+}
+
+// Command is a voice command of an app.
+type Command struct {
+	Name     string   `json:"name"`
+	Match    string   `json:"match,omitempty"`
+	Triggers []string `json:"triggers"` // Phrases such as "remind me to {task} at {time}"
+}
+
+// ParseDescription reads the declaration of an app's description field.
+func ParseDescription(raw string) (Description, error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.HasPrefix(raw, "{") {
+		return Description{}, nil
+	}
+	var d struct {
+		Description
+		WebhookSecret *string `json:"webhook_secret"`
+	}
+	if err := json.Unmarshal([]byte(raw), &d); err != nil {
+		return Description{}, fmt.Errorf("invalid commands: %w", err)
+	}
+	// The description is readable by anyone who can read the app
+	if d.WebhookSecret != nil {
+		return Description{}, fmt.Errorf("webhook_secret belongs in the app's webhook_secret field, not its description")
+	}
+	return d.Description, nil
+}
+
+// route is a compiled trigger of an app's command.
+type route struct {
+	app, appName  string
+	command       string
+	webhook       string
+	webhookSecret string
+	trigger       *trigger
+}
+
+// Router matches transcripts against the commands of every app.
+type Router struct {
+	routes []route
+}
+
+// compile checks a description and compiles its triggers. Webhook bodies are
+// signed with secret when it's set.
+func compile(appID, appName string, d Description, secret string) ([]route, error) {
+	if d.Webhook != "" {
+		u, err := url.Parse(d.Webhook)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("webhook must be an http or https URL")
+		}
+	}
+
+	var routes []route
+	seen := make(map[string]bool)
+	for i, command := range d.Commands {
+		if strings.TrimSpace(command.Name) == "" {
+			return nil, fmt.Errorf("command %d has no name", i+1)
+		}
+		if seen[command.Name] {
+			return nil, fmt.Errorf("command %q is declared twice", command.Name)
+		}
+		seen[command.Name] = true
+		if len(command.Triggers) == 0 {
+			return nil, fmt.Errorf("command %q has no triggers", command.Name)
+		}
+		for _, source := range command.Triggers {
+			t, err := compileTrigger(source, command.Match)
+			if err != nil {
+				return nil, fmt.Errorf("command %q: %w", command.Name, err)
+			}
+			routes = append(routes, route{
+				app:           appID,
+				appName:       appName,
+				command:       command.Name,
+				webhook:       d.Webhook,
+				webhookSecret: secret,
+				trigger:       t,
+			})
+		}
+	}
+	return routes, nil
+}
+
+// Load compiles the commands of every app, in the order the apps were
+// created. Apps with an invalid description are skipped.
+func Load(app core.App) (*Router, error) {
+	records, err := app.FindRecordsByFilter("apps", "", "created", 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load apps: %w", err)
+	}
+
+	router := &Router{}
+	for _, record := range records {
+		d, err := ParseDescription(record.GetString("description"))
+		if err == nil {
+			var routes []route
+			if routes, err = compile(record.Id, record.GetString("name"), d, record.GetString("webhook_secret")); err == nil {
+				router.routes = append(router.routes, routes...)
+				continue
+			}
+		}
+		logger.Error("Skipping app with invalid commands", "app", record.Id, "error", err)
+	}
+	return router, nil
+}
+
+// Cache keeps the compiled commands of every app between requests. Creating,
+// updating or deleting an app drops them, and the next request compiles them
+// again.
+type Cache struct {
+	app core.App
+
+	mu         sync.Mutex
+	router     *Router // nil until the next request loads it
+	generation int     // Bumped on every change, so a load racing a change isn't kept
+}
+
+// NewCache creates a cache of the apps' commands and binds the record hooks
+// that keep it current.
+func NewCache(app core.App) *Cache {
+	c := &Cache{app: app}
+	invalidate := func(e *core.RecordEvent) error {
+		c.mu.Lock()
+		c.router = nil
+		c.generation++
+		c.mu.Unlock()
+		return e.Next()
+	}
+	app.OnRecordAfterCreateSuccess("apps").BindFunc(invalidate)
+	app.OnRecordAfterUpdateSuccess("apps").BindFunc(invalidate)
+	app.OnRecordAfterDeleteSuccess("apps").BindFunc(invalidate)
+	return c
+}
+
+// Router returns the compiled commands, loading them if an app changed since
+// the last call.
+func (c *Cache) Router() (*Router, error) {
+	c.mu.Lock()
+	router, generation := c.router, c.generation
+	c.mu.Unlock()
+	if router != nil {
+		return router, nil
+	}
+
+	router, err := Load(c.app)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if c.generation == generation {
+		c.router = router
+	}
+	c.mu.Unlock()
+	return router, nil
+}
+
+// slotName is the syntax of slot names, in triggers and regex groups.
+var slotName = regexp.MustCompile(`^[\pL_][\pL\pN_]*$`)
+
+// RegisterValidation rejects apps whose commands can't be compiled, so
+// mistakes surface when the app is saved instead of as silently unmatched
+// transcripts.
+func RegisterValidation(app core.App) {
+	app.OnRecordValidate("apps").BindFunc(func(e *core.RecordEvent) error {
+		d, err := ParseDescription(e.Record.GetString("description"))
+		if err == nil {
+			_, err = compile(e.Record.Id, e.Record.GetString("name"), d, e.Record.GetString("webhook_secret"))
+		}
+		if err != nil {
+			return validation.Errors{
+				"description": validation.NewError("validation_invalid_commands", err.Error()),
+			}
+		}
+		return e.Next()
+	})
+}
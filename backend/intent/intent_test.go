@@ -0,0 +1,128 @@
+package intent
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"silence-backend/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.Init()
+	os.Exit(m.Run())
+}
+
+func TestParseDescription(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		commands int
+		webhook  string
+		wantErr  string
+	}{
+		{"plain text", "Tracks tasks", 0, "", ""},
+		{"empty", "", 0, "", ""},
+		{"commands", `{"commands": [{"name": "add", "triggers": ["add {item}"]}], "webhook": "https://example.com/hook", "icon": "x"}`, 1, "https://example.com/hook", ""},
+		{"invalid json", `{"commands": [`, 0, "", "invalid commands"},
+		{"secret in the description", `{"webhook": "https://example.com/hook", "webhook_secret": "s3cret"}`, 0, "", "webhook_secret belongs in the app's webhook_secret field"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := ParseDescription(tt.raw)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseDescription() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(d.Commands) != tt.commands || d.Webhook != tt.webhook {
+				t.Errorf("ParseDescription() = %+v", d)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		d       Description
+		wantErr string
+	}{
+		{"no name", Description{Commands: []Command{{Triggers: []string{"go"}}}}, "command 1 has no name"},
+		{"duplicate", Description{Commands: []Command{{Name: "a", Triggers: []string{"go"}}, {Name: "a", Triggers: []string{"stop"}}}}, "declared twice"},
+		{"no triggers", Description{Commands: []Command{{Name: "a"}}}, "has no triggers"},
+		{"bad webhook", Description{Webhook: "ftp://example.com"}, "http or https"},
+		{"unknown match", Description{Commands: []Command{{Name: "a", Match: "exact", Triggers: []string{"go"}}}}, "unknown match"},
+		{"bad regex", Description{Commands: []Command{{Name: "a", Match: MatchRegex, Triggers: []string{"go ("}}}}, "invalid trigger"},
+		{"slots only", Description{Commands: []Command{{Name: "a", Triggers: []string{"{task}"}}}}, "needs at least one word"},
+		{"adjacent slots", Description{Commands: []Command{{Name: "a", Triggers: []string{"add {a} {b}"}}}}, "need a word between them"},
+		{"repeated slot", Description{Commands: []Command{{Name: "a", Triggers: []string{"add {a} and {a}"}}}}, "appears twice"},
+		{"bad slot name", Description{Commands: []Command{{Name: "a", Triggers: []string{"add {1a}"}}}}, "invalid slot name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compile("app1", "App", tt.d, "")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("compile() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNotifySignsWithSecret(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+	}{
+		{"signed", "s3cret"},
+		{"unsigned", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body []byte
+			var signature string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ = io.ReadAll(r.Body)
+				signature = r.Header.Get("X-Silence-Signature")
+			}))
+			defer server.Close()
+
+			routes, err := compile("app1", "Tasks", Description{
+				Commands: []Command{{Name: "add", Triggers: []string{"add {item}"}}},
+				Webhook:  server.URL,
+			}, tt.secret)
+			if err != nil {
+				t.Fatal(err)
+			}
+			match := (&Router{routes: routes}).Match("add milk")
+			match.Notify(context.Background(), Event{Text: "add milk"})
+
+			if match.Webhook == nil || match.Webhook.StatusCode != http.StatusOK {
+				t.Fatalf("webhook result = %+v", match.Webhook)
+			}
+			want := ""
+			if tt.secret != "" {
+				mac := hmac.New(sha256.New, []byte(tt.secret))
+				mac.Write(body)
+				want = "sha256=" + hex.EncodeToString(mac.Sum(nil))
+			}
+			if signature != want {
+				t.Errorf("signature = %q, want %q", signature, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,332 @@
+package intent
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"silence-backend/vocabulary"
+)
+
+// Match is a transcript matched to an app's command.
+type Match struct {
+	App     string            `json:"app" example:"k3j4h5g6f7d8s9a"`
+	AppName string            `json:"app_name" example:"Tasks"`
+	Command string            `json:"command" example:"create_task"`
+	Trigger string            `json:"trigger" example:"remind me to {task} at {time}"`
+	Slots   map[string]string `json:"slots"`
+	Score   float64           `json:"score" example:"0.93"` // 1 for an exact match
+	Webhook *WebhookResult    `json:"webhook,omitempty"`    // Outcome of calling the app's webhook, if it has one
+
+	route *route
+}
+
+// Match finds the command a transcript best matches, or nil if none does.
+// Exact matches beat fuzzy ones; among equal scores the trigger with more
+// words wins, then the app created first.
+func (r *Router) Match(text string) *Match {
+	var best *Match
+	var bestScore float64
+	bestWords := 0
+	for i := range r.routes {
+		rt := &r.routes[i]
+		slots, score, ok := rt.trigger.match(text)
+		if !ok {
+			continue
+		}
+		if best != nil && (score < bestScore || (score == bestScore && rt.trigger.words <= bestWords)) {
+			continue
+		}
+		best = &Match{
+			App:     rt.app,
+			AppName: rt.appName,
+			Command: rt.command,
+			Trigger: rt.trigger.source,
+			Slots:   slots,
+			Score:   math.Round(score*100) / 100,
+			route:   rt,
+		}
+		bestScore, bestWords = score, rt.trigger.words
+	}
+	return best
+}
+
+// trigger is a compiled trigger phrase.
+type trigger struct {
+	source string
+	re     *regexp.Regexp // Keyword and regex triggers
+	parts  []part         // Fuzzy triggers
+	words  int            // Literal words, for preferring specific triggers
+}
+
+// part is a literal word or a slot of a fuzzy trigger.
+type part struct {
+	word string
+	slot string
+}
+
+// templatePart splits a trigger phrase into its literal text and {slots}.
+var templatePart = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// Separators allowed between the words of a keyword trigger, and what a slot may span.
+const (
+	wordSeparator = `[\s,;:—–-]+`
+	slotText      = `[^.!?\n]`
+)
+
+// compileTrigger compiles a trigger phrase for a way of matching.
+func compileTrigger(source, mode string) (*trigger, error) {
+	t := &trigger{source: source}
+	switch mode {
+	case MatchRegex:
+		re, err := regexp.Compile("(?i)" + source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trigger %q: %w", source, err)
+		}
+		for _, name := range re.SubexpNames()[1:] {
+			if name != "" && !slotName.MatchString(name) {
+				return nil, fmt.Errorf("invalid slot name %q in trigger %q", name, source)
+			}
+		}
+		t.re = re
+		t.words = len(strings.Fields(source))
+		return t, nil
+	case "", MatchKeyword, MatchFuzzy:
+	default:
+		return nil, fmt.Errorf("unknown match %q, expected one of: %s, %s, %s", mode, MatchKeyword, MatchRegex, MatchFuzzy)
+	}
+
+	// Split the phrase into literal words and slots
+	last := 0
+	seen := make(map[string]bool)
+	for _, m := range templatePart.FindAllStringSubmatchIndex(source, -1) {
+		for _, w := range literalWords(source[last:m[0]]) {
+			t.parts = append(t.parts, part{word: w})
+		}
+		name := source[m[2]:m[3]]
+		if !slotName.MatchString(name) {
+			return nil, fmt.Errorf("invalid slot name %q in trigger %q", name, source)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("slot %q appears twice in trigger %q", name, source)
+		}
+		seen[name] = true
+		if n := len(t.parts); n > 0 && t.parts[n-1].slot != "" {
+			return nil, fmt.Errorf("slots need a word between them in trigger %q", source)
+		}
+		t.parts = append(t.parts, part{slot: name})
+		last = m[1]
+	}
+	for _, w := range literalWords(source[last:]) {
+		t.parts = append(t.parts, part{word: w})
+	}
+	for _, p := range t.parts {
+		if p.slot == "" {
+			t.words++
+		}
+	}
+	if t.words == 0 {
+		return nil, fmt.Errorf("trigger %q needs at least one word besides its slots", source)
+	}
+	if mode == MatchFuzzy {
+		return t, nil
+	}
+
+	// Keyword triggers become a pattern; a slot in the middle takes as little
+	// as it can, one at the end the rest of the sentence
+	var sb strings.Builder
+	sb.WriteString(`(?i)(?:^|[^\pL\pN])`)
+	for i, p := range t.parts {
+		if i > 0 {
+			sb.WriteString(wordSeparator)
+		}
+		switch {
+		case p.slot == "":
+			sb.WriteString(regexp.QuoteMeta(p.word))
+		case i == len(t.parts)-1:
+			sb.WriteString(`(?P<` + p.slot + `>` + slotText + `+)`)
+		default:
+			sb.WriteString(`(?P<` + p.slot + `>` + slotText + `+?)`)
+		}
+	}
+	if t.parts[len(t.parts)-1].slot == "" {
+		sb.WriteString(`(?:$|[^\pL\pN])`)
+	}
+	t.re = regexp.MustCompile(sb.String())
+	return t, nil
+}
+
+// literalWords splits literal trigger text into lowercase words without punctuation.
+func literalWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '’'
+	})
+}
+
+// match matches the trigger against a transcript, returning the slot values
+// and a score from 0 to 1.
+func (t *trigger) match(text string) (map[string]string, float64, bool) {
+	if t.re == nil {
+		return t.matchFuzzy(text)
+	}
+
+	m := t.re.FindStringSubmatch(text)
+	if m == nil {
+		return nil, 0, false
+	}
+	slots := make(map[string]string)
+	for i, name := range t.re.SubexpNames() {
+		if i > 0 && name != "" {
+			slots[name] = cleanSlot(m[i])
+		}
+	}
+	return slots, 1, true
+}
+
+// cleanSlot trims the spaces and punctuation a provider left around a slot value.
+func cleanSlot(value string) string {
+	return strings.TrimFunc(value, func(r rune) bool {
+		return unicode.IsSpace(r) || (unicode.IsPunct(r) && !strings.ContainsRune(`"'»)`, r))
+	})
+}
+
+// word is a word of a transcript and its byte range.
+type word struct {
+	text       string
+	start, end int
+}
+
+// splitWords finds the words of a transcript.
+func splitWords(text string) []word {
+	var words []word
+	start := -1
+	for i, r := range text + " " {
+		inner := r == '\'' || r == '’' || r == '-'
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || (inner && start >= 0) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			words = append(words, word{text: text[start:i], start: start, end: i})
+			start = -1
+		}
+	}
+	return words
+}
+
+// maxSlotWords is the longest a slot in the middle of a fuzzy trigger may be,
+// which bounds the search on long transcripts. A slot at the end takes the
+// rest of the sentence whatever its length.
+const maxSlotWords = 30
+
+// fuzzyState is a search for the best alignment of a fuzzy trigger. The best
+// alignment of each part from each word is worked out once, so the search
+// grows with the number of parts and words rather than exponentially.
+type fuzzyState struct {
+	text  string
+	words []word
+	parts []part
+	memo  []alignment // By part, then word
+}
+
+// alignment is the best match of the parts from one part and word on.
+type alignment struct {
+	done bool
+	ok   bool
+	sum  float64 // Similarity sum of the literal words
+	next int     // Word the following part starts at
+}
+
+// matchFuzzy aligns the trigger's words with the transcript's, allowing each
+// literal word to be misspelled or to sound alike, and slots to take one or
+// more words within a sentence. The score is the mean similarity of the
+// literal words.
+func (t *trigger) matchFuzzy(text string) (map[string]string, float64, bool) {
+	words := splitWords(text)
+	s := &fuzzyState{text: text, words: words, parts: t.parts, memo: make([]alignment, len(t.parts)*len(words))}
+
+	start, best := -1, 0.0
+	for i := range words {
+		if a := s.align(0, i); a.ok && (start < 0 || a.sum > best) {
+			start, best = i, a.sum
+		}
+	}
+	if start < 0 {
+		return nil, 0, false
+	}
+
+	slots := make(map[string]string)
+	wi := start
+	for pi, p := range t.parts {
+		next := s.align(pi, wi).next
+		if p.slot != "" {
+			slots[p.slot] = cleanSlot(text[words[wi].start:words[next-1].end])
+		}
+		wi = next
+	}
+	return slots, best / float64(t.words), true
+}
+
+// joined reports whether word i continues the sentence of word i-1.
+func (s *fuzzyState) joined(i int) bool {
+	return !strings.ContainsAny(s.text[s.words[i-1].end:s.words[i].start], ".!?\n")
+}
+
+// align finds the best match of parts[pi:] from word wi. Parts after the
+// first continue the sentence of the part before them.
+func (s *fuzzyState) align(pi, wi int) alignment {
+	if pi == len(s.parts) {
+		return alignment{ok: true}
+	}
+	if wi >= len(s.words) || (pi > 0 && !s.joined(wi)) {
+		return alignment{}
+	}
+	memo := &s.memo[pi*len(s.words)+wi]
+	if memo.done {
+		return *memo
+	}
+
+	best := alignment{done: true}
+	try := func(next int, sim float64) {
+		if rest := s.align(pi+1, next); rest.ok && (!best.ok || rest.sum+sim > best.sum) {
+			best.ok, best.sum, best.next = true, rest.sum+sim, next
+		}
+	}
+
+	p := s.parts[pi]
+	if p.slot == "" {
+		// Providers often split unfamiliar words, so a word may span two
+		for n := 1; n <= 2 && wi+n <= len(s.words); n++ {
+			if n > 1 && !s.joined(wi+n-1) {
+				break
+			}
+			span := s.text[s.words[wi].start:s.words[wi+n-1].end]
+			if sim := vocabulary.Similarity(span, p.word); sim > 0 {
+				try(wi+n, sim)
+			}
+		}
+	} else {
+		// A slot at the end takes the rest of the sentence, one in the middle
+		// every length up to it
+		end := wi + 1
+		for end < len(s.words) && s.joined(end) {
+			end++
+		}
+		from := wi + 1
+		if pi == len(s.parts)-1 {
+			from = end
+		} else {
+			end = min(end, wi+maxSlotWords)
+		}
+		for k := from; k <= end; k++ {
+			try(k, 0)
+		}
+	}
+
+	*memo = best
+	return best
+}
@@ -0,0 +1,113 @@
+package intent
+
+import (
+	"maps"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testRouter compiles commands of one app into a router.
+func testRouter(t *testing.T, commands ...Command) *Router {
+	t.Helper()
+	routes, err := compile("app1", "Tasks", Description{Commands: commands}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Router{routes: routes}
+}
+
+func TestMatch(t *testing.T) {
+	reminder := Command{Name: "create_task", Triggers: []string{"remind me to {task} at {time}"}}
+	shopping := Command{Name: "add_item", Triggers: []string{"add {item} to the shopping list", "add {item}"}}
+	regex := Command{Name: "call", Match: MatchRegex, Triggers: []string{`call (?P<person>\w+)`}}
+	fuzzy := Command{Name: "deploy", Match: MatchFuzzy, Triggers: []string{"deploy {service} to kubernetes", "rollback {service}"}}
+
+	tests := []struct {
+		name     string
+		commands []Command
+		text     string
+		command  string // "" for no match
+		slots    map[string]string
+		exact    bool
+	}{
+		{"keyword slots", []Command{reminder}, "Remind me to buy milk at five pm.", "create_task", map[string]string{"task": "buy milk", "time": "five pm"}, true},
+		{"keyword punctuation", []Command{reminder}, "Remind me, to call mom at noon", "create_task", map[string]string{"task": "call mom", "time": "noon"}, true},
+		{"slot stops at the sentence", []Command{reminder}, "Remind me to rest. At home.", "", nil, false},
+		{"longer trigger wins", []Command{shopping}, "add eggs to the shopping list", "add_item", map[string]string{"item": "eggs"}, true},
+		{"shorter trigger", []Command{shopping}, "add eggs", "add_item", map[string]string{"item": "eggs"}, true},
+		{"whole words only", []Command{shopping}, "address book", "", nil, false},
+		{"regex", []Command{regex}, "please call Anna now", "call", map[string]string{"person": "Anna"}, true},
+		{"fuzzy split word", []Command{fuzzy}, "deploy billing to cooper netties", "deploy", map[string]string{"service": "billing"}, false},
+		{"fuzzy misspelling", []Command{fuzzy}, "rolback payments", "deploy", map[string]string{"service": "payments"}, false},
+		{"fuzzy exact", []Command{fuzzy}, "deploy search to kubernetes", "deploy", map[string]string{"service": "search"}, true},
+		{"fuzzy no match", []Command{fuzzy}, "the weather is nice", "", nil, false},
+		{"nothing", []Command{reminder, shopping}, "hello there", "", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testRouter(t, tt.commands...).Match(tt.text)
+			if tt.command == "" {
+				if m != nil {
+					t.Fatalf("Match(%q) = %+v, want no match", tt.text, m)
+				}
+				return
+			}
+			if m == nil {
+				t.Fatalf("Match(%q) = nil, want %s", tt.text, tt.command)
+			}
+			if m.Command != tt.command || !maps.Equal(m.Slots, tt.slots) {
+				t.Errorf("Match(%q) = %s %v, want %s %v", tt.text, m.Command, m.Slots, tt.command, tt.slots)
+			}
+			if exact := m.Score == 1; exact != tt.exact {
+				t.Errorf("Match(%q) score = %v, want exact %v", tt.text, m.Score, tt.exact)
+			}
+		})
+	}
+}
+
+func TestFuzzyMatchLongTranscript(t *testing.T) {
+	tests := []struct {
+		name    string
+		trigger string
+		text    string
+		slots   map[string]string
+	}{
+		{
+			name:    "many slots over a long sentence",
+			trigger: "move {a} from {b} to {c} at {d} on {e} with {f}",
+			text:    "move box from hall to attic at noon on friday with care " + strings.Repeat("from to at on with ", 300),
+			slots:   map[string]string{"a": "box", "b": "hall", "c": "attic", "d": "noon", "e": "friday"},
+		},
+		{
+			name:    "middle slot longer than the cap",
+			trigger: "remind me to {task} at {time}",
+			text:    "remind me to " + strings.Repeat("really ", maxSlotWords) + "rest at noon",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := testRouter(t, Command{Name: "c", Match: MatchFuzzy, Triggers: []string{tt.trigger}})
+			start := time.Now()
+			m := r.Match(tt.text)
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Fatalf("Match took %v", elapsed)
+			}
+			if tt.slots == nil {
+				if m != nil {
+					t.Errorf("Match() = %v, want no match", m.Slots)
+				}
+				return
+			}
+			if m != nil {
+				// The last slot takes the rest of the sentence
+				delete(m.Slots, "f")
+			}
+			if m == nil || !maps.Equal(m.Slots, tt.slots) {
+				t.Errorf("Match() = %+v, want slots %v", m, tt.slots)
+			}
+		})
+	}
+}
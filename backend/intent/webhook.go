@@ -0,0 +1,97 @@
+package intent
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"silence-backend/logger"
+)
+
+// webhookClient calls app webhooks. The call holds up the /speak response,
+// so the timeout is short.
+var webhookClient = &http.Client{Timeout: 5 * time.Second}
+
+// Event is the body posted to an app's webhook when one of its commands matches.
+type Event struct {
+	Event        string            `json:"event"` // Always "command"
+	App          string            `json:"app"`
+	Command      string            `json:"command"`
+	Slots        map[string]string `json:"slots"`
+	Score        float64           `json:"score"`
+	Text         string            `json:"text"`
+	LanguageCode string            `json:"language_code"`
+	TranscriptID string            `json:"transcript_id,omitempty"`
+	CallerApp    string            `json:"caller_app,omitempty"`  // App whose API key made the request
+	CallerUser   string            `json:"caller_user,omitempty"` // User who made the request
+	Timestamp    int64             `json:"timestamp"`
+}
+
+// WebhookResult is the outcome of calling an app's webhook.
+type WebhookResult struct {
+	StatusCode int    `json:"status_code,omitempty" example:"200"`
+	Error      string `json:"error,omitempty" example:"webhook returned status 500"`
+	DurationMs int64  `json:"duration_ms" example:"84"`
+}
+
+// Notify posts the match to the matched app's webhook, if it has one, and
+// records the outcome on the match. Bodies are signed in the
+// X-Silence-Signature header ("sha256=" and the hex HMAC of the body) when
+// the app has a webhook secret.
+func (m *Match) Notify(ctx context.Context, event Event) {
+	if m.route == nil || m.route.webhook == "" {
+		return
+	}
+
+	event.Event = "command"
+	event.App = m.App
+	event.Command = m.Command
+	event.Slots = m.Slots
+	event.Score = m.Score
+	event.Timestamp = time.Now().Unix()
+
+	start := time.Now()
+	result := &WebhookResult{}
+	m.Webhook = result
+	defer func() { result.DurationMs = time.Since(start).Milliseconds() }()
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to encode event: %v", err)
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.route.webhook, bytes.NewReader(body))
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to create request: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Silence-Event", event.Event)
+	if m.route.webhookSecret != "" {
+		mac := hmac.New(sha256.New, []byte(m.route.webhookSecret))
+		mac.Write(body)
+		req.Header.Set("X-Silence-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		result.Error = fmt.Sprintf("webhook request failed: %v", err)
+		logger.Error("App webhook failed", "app", m.App, "command", m.Command, "error", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	result.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		result.Error = fmt.Sprintf("webhook returned status %d", resp.StatusCode)
+		logger.Error("App webhook failed", "app", m.App, "command", m.Command, "status", resp.StatusCode)
+		return
+	}
+	logger.Info("App webhook called", "app", m.App, "command", m.Command, "status", resp.StatusCode)
+}
@@ -14,18 +14,38 @@ package main
 // @host localhost:8090
 // @BasePath /
 
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description PocketBase user or superuser auth token, or an app API key ("slc_..."), optionally prefixed with "Bearer "
+
 import (
+	"context"
 	"log"
+	"silence-backend/audio"
 	"silence-backend/auth"
+	"silence-backend/compression"
 	"silence-backend/database"
 	"silence-backend/env"
+	"silence-backend/ffmpeg"
+	"silence-backend/handlers"
+	"silence-backend/intent"
 	"silence-backend/logger"
+	_ "silence-backend/migrations" // Schema migrations
+	"silence-backend/postprocess"
+	"silence-backend/profile"
+	"silence-backend/queue"
+	"silence-backend/ratelimit"
+	"silence-backend/retention"
 	"silence-backend/routes"
 	"silence-backend/transcription"
+	"silence-backend/usage"
 	"strings"
+	"time"
 
 	"github.com/pocketbase/pocketbase"
 	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/plugins/migratecmd"
 	"github.com/pocketbase/pocketbase/tools/hook"
 )
 
@@ -44,8 +64,127 @@ func main() {
 
 	envVars := env.Load()
 
+	preprocess, err := audio.ParsePreprocessConfig(envVars.Preprocess)
+	if err != nil {
+		log.Fatal("Invalid AUDIO_PREPROCESS: ", err)
+	}
+
+	codec, err := compression.ParseCodec(envVars.StorageCodec)
+	if err != nil {
+		log.Fatal("Invalid STORAGE_CODEC: ", err)
+	}
+	ffmpegRunner := ffmpeg.NewRunner(ffmpeg.Config{
+		Binary:       envVars.FFmpegPath,
+		MaxProcesses: envVars.FFmpegMaxProcesses,
+		Timeout:      envVars.FFmpegTimeout,
+	})
+	compressor, err := compression.NewFFmpegCompressor(ffmpegRunner, codec, compression.Settings{
+		Bitrate: envVars.StorageBitrate,
+		Quality: envVars.StorageQuality,
+	})
+	if err != nil {
+		log.Fatal("Failed to create compressor: ", err)
+	}
+
+	audioAction, err := retention.ParseAudioAction(envVars.RetentionAudioAction)
+	if err != nil {
+		log.Fatal("Invalid RETENTION_AUDIO_ACTION: ", err)
+	}
+	downsampler, err := compression.NewFFmpegCompressor(ffmpegRunner, compression.CodecOpus, compression.Settings{
+		Bitrate: envVars.RetentionDownsampleRate,
+	})
+	if err != nil {
+		log.Fatal("Failed to create retention downsampler: ", err)
+	}
+
+	postprocessing, err := postprocess.Parse(envVars.Postprocess)
+	if err != nil {
+		log.Fatal("Invalid TEXT_POSTPROCESS: ", err)
+	}
+
+	prices, err := usage.ParsePriceTable(envVars.PriceTable)
+	if err != nil {
+		log.Fatal("Invalid PRICE_TABLE: ", err)
+	}
+
 	app := pocketbase.New()
 
+	retentionEnforcer := retention.NewEnforcer(app, retention.Policy{
+		AudioDays:   envVars.RetentionAudioDays,
+		TextDays:    envVars.RetentionTextDays,
+		AudioAction: audioAction,
+	}, downsampler)
+
+	persistQueue := queue.New(queue.Config{
+		Workers:     envVars.PersistWorkers,
+		Capacity:    envVars.PersistQueueSize,
+		MaxAttempts: envVars.PersistMaxAttempts,
+		Backoff:     envVars.PersistRetryBackoff,
+		MaxBackoff:  time.Minute,
+	})
+
+	handlerConfig := handlers.Config{
+		Preprocess:  preprocess,
+		Postprocess: postprocessing,
+		Compressor:  compressor,
+		Retention:   retentionEnforcer,
+		Queue:       persistQueue,
+		Limiter: ratelimit.New(app, ratelimit.Limits{
+			RequestsPerMinute:   envVars.LimitRequestsPerMinute,
+			DailyAudioMinutes:   envVars.LimitDailyAudioMinutes,
+			MonthlyAudioMinutes: envVars.LimitMonthlyAudioMinutes,
+			MaxConcurrent:       envVars.LimitMaxConcurrent,
+		}),
+		Meter: usage.NewMeter(app, prices, usage.Budget{
+			DailyUSD:   envVars.BudgetDailyUSD,
+			MonthlyUSD: envVars.BudgetMonthlyUSD,
+		}),
+		Intents: intent.NewCache(app),
+	}
+
+	// Schema changes live in versioned migrations, applied automatically on serve.
+	// The "migrate" command shows the history and can revert them.
+	migratecmd.MustRegister(app, app.RootCmd, migratecmd.Config{
+		Automigrate: false,
+	})
+
+	database.RegisterSearchIndex(app)
+	profile.RegisterValidation(app)
+	intent.RegisterValidation(app)
+
+	// Retention runs as a cron job; per-app overrides can enable it even when
+	// the global periods keep data forever
+	app.Cron().MustAdd("retention", envVars.RetentionSchedule, func() {
+		report, err := retentionEnforcer.Run(context.Background(), false)
+		if err != nil {
+			logger.Error("Retention run failed", "error", err)
+			return
+		}
+		retention.LogReport(report)
+	})
+
+	// Drain pending saves after the HTTP server stops and before the database closes.
+	// Whatever doesn't finish in time is written to the dead-letter collection.
+	app.OnTerminate().BindFunc(func(e *core.TerminateEvent) error {
+		stats := persistQueue.Stats()
+		logger.Info("Draining persistence queue", "depth", stats.Depth, "active", stats.Active)
+
+		ctx, cancel := context.WithTimeout(context.Background(), envVars.PersistShutdownTimeout)
+		defer cancel()
+		if err := persistQueue.Shutdown(ctx); err != nil {
+			logger.Error("Persistence queue did not drain in time", "error", err)
+		}
+		return e.Next()
+	})
+
+	// Storage must be configured before migrations run, they may upload files
+	app.OnBootstrap().BindFunc(func(e *core.BootstrapEvent) error {
+		if err := e.Next(); err != nil {
+			return err
+		}
+		return database.ConfigureS3(e.App, envVars.S3)
+	})
+
 	// Register custom routes with high priority (execute early)
 	app.OnServe().Bind(&hook.Handler[*core.ServeEvent]{
 		Func: func(se *core.ServeEvent) error {
@@ -56,13 +195,20 @@ func main() {
 			// Create provider chain: ElevenLabs first, then Chutes as fallback
 			providerChain := transcription.NewProviderChain(elevenlabsProvider, chutesProvider)
 
+			// Split long recordings at pauses before they reach any provider
+			chunking := transcription.ChunkingConfig{
+				MaxDuration: envVars.ChunkMaxDuration,
+				Overlap:     envVars.ChunkOverlap,
+				Concurrency: envVars.ChunkConcurrency,
+			}
+
 			// Create providers map for direct provider selection
 			providers := map[transcription.ProviderName]transcription.TranscriptionProvider{
-				transcription.ProviderElevenLabs: elevenlabsProvider,
-				transcription.ProviderChutes:     chutesProvider,
+				transcription.ProviderElevenLabs: transcription.NewChunkedProvider(elevenlabsProvider, chunking),
+				transcription.ProviderChutes:     transcription.NewChunkedProvider(chutesProvider, chunking),
 			}
 
-			routes.Setup(se, app, providerChain, providers)
+			routes.Setup(se, app, handlerConfig, transcription.NewChunkedProvider(providerChain, chunking), providers)
 			return se.Next()
 		},
 		Priority: 1, // Execute early (low number = early)
@@ -74,14 +220,12 @@ func main() {
 			return err
 		}
 
-		if err := database.EnsureSilenceCollection(se.App); err != nil {
-			logger.Error("Failed to ensure silence collection", "error", err)
-			return err
-		}
-
-		if err := database.EnsureAppsCollection(se.App); err != nil {
-			logger.Error("Failed to ensure apps collection", "error", err)
-			return err
+		// Transcription works without ffmpeg; transcripts are saved and their audio
+		// is kept in persist_failures for a backfill once ffmpeg is available
+		if version, err := ffmpegRunner.Check(context.Background()); err != nil {
+			logger.Error("ffmpeg is not available, audio will be marked audio_failed", "path", envVars.FFmpegPath, "error", err)
+		} else {
+			logger.Info("ffmpeg available", "version", version, "max_processes", envVars.FFmpegMaxProcesses)
 		}
 
 		logServerStart(se.Server.Addr)
@@ -0,0 +1,61 @@
+// Package migrations holds the versioned schema migrations of the Silence collections.
+// They are registered with PocketBase on import and applied automatically on serve;
+// the applied history is kept in the _migrations table and can be inspected or
+// reverted with the "migrate" command.
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+// Creates the silence and apps collections. Deployments that predate migrations
+// already have them, so existing collections are left for later migrations to upgrade.
+func init() {
+	m.Register(func(app core.App) error {
+		if _, err := app.FindCollectionByNameOrId("silence"); err != nil {
+			silence := core.NewBaseCollection("silence")
+			silence.Fields.Add(&core.FileField{
+				Name:      "audio",
+				MaxSelect: 1,
+				MaxSize:   100 << 20, // 100MB, hours of compressed speech
+				Protected: true,
+			})
+			silence.Fields.Add(&core.TextField{
+				Name: "result",
+				Max:  10000, // 10k characters limit
+			})
+			if err := app.Save(silence); err != nil {
+				return err
+			}
+		}
+
+		if _, err := app.FindCollectionByNameOrId("apps"); err != nil {
+			apps := core.NewBaseCollection("apps")
+			apps.Fields.Add(&core.TextField{
+				Name:     "name",
+				Required: true,
+				Max:      255,
+			})
+			apps.Fields.Add(&core.JSONField{
+				Name: "description",
+			})
+			if err := app.Save(apps); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func(app core.App) error {
+		for _, name := range []string{"silence", "apps"} {
+			collection, err := app.FindCollectionByNameOrId(name)
+			if err != nil {
+				continue
+			}
+			if err := app.Delete(collection); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,125 @@
+package migrations
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"silence-backend/compression"
+	"silence-backend/logger"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+// legacyAudioField is the old base64 text column, kept only until its rows are converted.
+const legacyAudioField = "audio_base64"
+
+// Converts silence collections that stored audio as base64 text into the file
+// field layout. The old text field is renamed, a file field takes its name,
+// every row is decoded and re-uploaded as a file, and the old field is dropped
+// once all rows have been converted. Rows that fail keep the legacy column
+// until migration 1792301400 retries them and moves the rest to
+// persist_failures; the conversion is not reversible.
+func init() {
+	m.Register(migrateAudioToFiles, nil)
+}
+
+func migrateAudioToFiles(app core.App) error {
+	collection, err := app.FindCollectionByNameOrId("silence")
+	if err != nil {
+		return err
+	}
+
+	if legacy, ok := collection.Fields.GetByName("audio").(*core.TextField); ok {
+		legacy.Name = legacyAudioField
+		legacy.Required = false
+		legacy.Max = 0
+		collection.Fields.Add(&core.FileField{
+			Name:      "audio",
+			MaxSelect: 1,
+			MaxSize:   100 << 20,
+			Protected: true,
+		})
+		if err := app.Save(collection); err != nil {
+			logger.Error("Failed to add audio file field", "error", err)
+			return err
+		}
+		logger.Info("Replaced base64 audio field with file field")
+	}
+
+	if collection.Fields.GetByName(legacyAudioField) == nil {
+		return nil
+	}
+
+	failed, err := convertLegacyAudio(app, collection)
+	if err != nil || len(failed) > 0 {
+		// Keep the legacy column so failed rows can be retried later
+		return err
+	}
+	return removeLegacyAudioField(app, collection)
+}
+
+// convertLegacyAudio converts every row that still has base64 audio and
+// returns the errors of the rows that couldn't be converted, by record id.
+func convertLegacyAudio(app core.App, collection *core.Collection) (map[string]error, error) {
+	// Load ids only, the base64 payloads are fetched one row at a time
+	var ids []string
+	err := app.DB().Select("id").From(collection.Name).Where(dbx.NewExp(legacyAudioField + " != ''")).Column(&ids)
+	if err != nil {
+		return nil, err
+	}
+
+	failed := make(map[string]error)
+	for _, id := range ids {
+		record, err := app.FindRecordById(collection, id)
+		if err == nil {
+			err = convertAudioRecord(app, record)
+		}
+		if err != nil {
+			logger.Error("Failed to convert base64 audio", "record_id", id, "error", err)
+			failed[id] = err
+		}
+	}
+
+	logger.Info("Converted base64 audio to files", "converted", len(ids)-len(failed), "failed", len(failed))
+	return failed, nil
+}
+
+// removeLegacyAudioField drops the base64 audio column once no row needs it.
+func removeLegacyAudioField(app core.App, collection *core.Collection) error {
+	collection.Fields.RemoveByName(legacyAudioField)
+	if err := app.Save(collection); err != nil {
+		logger.Error("Failed to remove legacy audio field", "error", err)
+		return err
+	}
+
+	logger.Info("Removed legacy base64 audio field")
+	return nil
+}
+
+// convertAudioRecord moves one row's base64 audio into the file field.
+func convertAudioRecord(app core.App, record *core.Record) error {
+	data, err := base64.StdEncoding.DecodeString(record.GetString(legacyAudioField))
+	if err != nil {
+		return fmt.Errorf("invalid base64: %w", err)
+	}
+
+	// Rows from before the codec field were always Vorbis
+	codec := compression.Codec(record.GetString("codec"))
+	if codec == "" {
+		codec = compression.CodecVorbis
+	}
+
+	file, err := filesystem.NewFileFromBytes(data, "audio"+codec.Extension())
+	if err != nil {
+		return err
+	}
+
+	record.Set("audio", file)
+	record.Set(legacyAudioField, "")
+	record.Set("codec", string(codec))
+
+	return app.Save(record)
+}
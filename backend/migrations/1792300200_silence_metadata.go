@@ -0,0 +1,122 @@
+package migrations
+
+import (
+	"io"
+
+	"silence-backend/audio"
+	"silence-backend/logger"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// Adds per-transcript metadata to silence: codec, detected language, provider,
+// audio duration and diagnostics, the calling app and a latency breakdown.
+// Existing rows are backfilled with their codec and, for Ogg audio, duration.
+// Rows from before this migration get the migration time as created/updated
+// timestamps, so retention and history ordering treat them as one batch.
+// Reverting removes every field it adds, backfilled values included.
+func init() {
+	m.Register(func(app core.App) error {
+		apps, err := app.FindCollectionByNameOrId("apps")
+		if err != nil {
+			return err
+		}
+
+		err = addMissingFields(app, "silence",
+			&core.TextField{Name: "codec", Max: 32},
+			&core.TextField{Name: "language", Max: 16},
+			&core.TextField{Name: "provider", Max: 64},
+			&core.NumberField{Name: "duration_ms", OnlyInt: true},
+			&core.JSONField{Name: "quality"},
+			&core.RelationField{Name: "app", CollectionId: apps.Id, MaxSelect: 1},
+			&core.NumberField{Name: "upload_ms", OnlyInt: true},
+			&core.NumberField{Name: "transcribe_ms", OnlyInt: true},
+			&core.NumberField{Name: "compress_ms", OnlyInt: true},
+			&core.AutodateField{Name: "created", OnCreate: true},
+			&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+		)
+		if err != nil {
+			return err
+		}
+
+		if err := backfillTimestamps(app); err != nil {
+			return err
+		}
+		return backfillAudioMetadata(app)
+	}, func(app core.App) error {
+		return removeFields(app, "silence", "codec", "language", "provider", "duration_ms", "quality",
+			"app", "upload_ms", "transcribe_ms", "compress_ms", "created", "updated")
+	})
+}
+
+// backfillTimestamps sets the created and updated time of rows that predate
+// the autodate fields. Their real creation time was never recorded.
+func backfillTimestamps(app core.App) error {
+	now := types.NowDateTime().String()
+	for _, field := range []string{"created", "updated"} {
+		_, err := app.DB().Update("silence", dbx.Params{field: now},
+			dbx.Or(dbx.HashExp{field: ""}, dbx.NewExp(field+" IS NULL"))).Execute()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillAudioMetadata sets the codec of rows stored before it was recorded
+// (always Vorbis) and reads the duration of Ogg audio from the stored file.
+func backfillAudioMetadata(app core.App) error {
+	_, err := app.DB().NewQuery("UPDATE silence SET codec = 'vorbis' WHERE codec = '' AND audio != ''").Execute()
+	if err != nil {
+		return err
+	}
+
+	records, err := app.FindRecordsByFilter("silence", "duration_ms = 0 && audio != '' && (codec = 'vorbis' || codec = 'opus')", "", 0, 0)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+
+	filled := 0
+	for _, record := range records {
+		key := record.BaseFilesPath() + "/" + record.GetString("audio")
+		reader, err := fsys.GetReader(key)
+		if err != nil {
+			logger.Warn("Audio file missing during duration backfill", "record_id", record.Id, "error", err)
+			continue
+		}
+		data, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			logger.Warn("Failed to read stored audio during duration backfill", "record_id", record.Id, "error", err)
+			continue
+		}
+
+		duration, err := audio.OggDuration(data)
+		if err != nil {
+			logger.Warn("Could not read duration of stored audio", "record_id", record.Id, "error", err)
+			continue
+		}
+
+		// Raw update so the backfill doesn't touch the updated timestamp
+		_, err = app.DB().Update("silence", dbx.Params{"duration_ms": duration.Milliseconds()}, dbx.HashExp{"id": record.Id}).Execute()
+		if err != nil {
+			return err
+		}
+		filled++
+	}
+
+	logger.Info("Backfilled audio durations", "records", len(records), "filled", filled)
+	return nil
+}
@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+// Adds the rest of what /speak knows about a request to silence: requested
+// language and preprocessing, failed provider attempts, the input format and
+// size, and client details.
+func init() {
+	m.Register(func(app core.App) error {
+		return addMissingFields(app, "silence",
+			&core.TextField{Name: "requested_language", Max: 16},
+			&core.JSONField{Name: "failed_attempts"},
+			&core.TextField{Name: "preprocess", Max: 255},
+			&core.TextField{Name: "input_format", Max: 32},
+			&core.NumberField{Name: "sample_rate", OnlyInt: true},
+			&core.NumberField{Name: "channels", OnlyInt: true},
+			&core.NumberField{Name: "size_bytes", OnlyInt: true},
+			&core.JSONField{Name: "client"},
+		)
+	}, func(app core.App) error {
+		return removeFields(app, "silence",
+			"requested_language", "failed_attempts", "preprocess", "input_format",
+			"sample_rate", "channels", "size_bytes", "client")
+	})
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+// Creates the FTS5 full-text index over transcript text and fills it with the
+// existing rows. The index is kept in sync by record hooks (see database.RegisterSearchIndex).
+// unicode61 with diacritics removal handles both English and Russian text.
+func init() {
+	m.Register(func(app core.App) error {
+		_, err := app.DB().NewQuery(`
+			CREATE VIRTUAL TABLE IF NOT EXISTS silence_fts USING fts5(
+				id UNINDEXED,
+				result,
+				tokenize = 'unicode61 remove_diacritics 2'
+			)
+		`).Execute()
+		if err != nil {
+			return err
+		}
+
+		_, err = app.DB().NewQuery(`
+			INSERT INTO silence_fts (id, result)
+			SELECT id, result FROM silence WHERE result != ''
+		`).Execute()
+		return err
+	}, func(app core.App) error {
+		_, err := app.DB().NewQuery("DROP TABLE IF EXISTS silence_fts").Execute()
+		return err
+	})
+}
@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+// Adds per-app retention overrides and marks audio that retention has already
+// downsampled, so it isn't re-encoded on every run.
+// Retention days: 0 inherits the global policy, -1 keeps data forever.
+func init() {
+	m.Register(func(app core.App) error {
+		err := addMissingFields(app, "apps",
+			&core.NumberField{Name: "audio_retention_days", OnlyInt: true},
+			&core.NumberField{Name: "text_retention_days", OnlyInt: true},
+		)
+		if err != nil {
+			return err
+		}
+		return addMissingFields(app, "silence",
+			&core.BoolField{Name: "audio_downsampled"},
+		)
+	}, func(app core.App) error {
+		if err := removeFields(app, "apps", "audio_retention_days", "text_retention_days"); err != nil {
+			return err
+		}
+		return removeFields(app, "silence", "audio_downsampled")
+	})
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+// Creates the dead-letter collection for transcripts that the background
+// persistence queue couldn't store. The original upload is kept as a file so
+// nothing the provider was paid for is lost.
+func init() {
+	m.Register(func(app core.App) error {
+		if _, err := app.FindCollectionByNameOrId("persist_failures"); err == nil {
+			return nil
+		}
+
+		failures := core.NewBaseCollection("persist_failures")
+		failures.Fields.Add(
+			&core.TextField{Name: "job", Required: true, Max: 100},
+			&core.TextField{Name: "error"},
+			&core.NumberField{Name: "attempts", OnlyInt: true},
+			&core.JSONField{Name: "payload"},
+			&core.FileField{
+				Name:      "audio",
+				MaxSelect: 1,
+				MaxSize:   200 << 20, // Uncompressed uploads
+				Protected: true,
+			},
+			&core.AutodateField{Name: "created", OnCreate: true},
+		)
+		return app.Save(failures)
+	}, func(app core.App) error {
+		failures, err := app.FindCollectionByNameOrId("persist_failures")
+		if err != nil {
+			return nil
+		}
+		return app.Delete(failures)
+	})
+}
@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+// Transcripts are now saved before their audio is compressed. The status field
+// tracks the audio: pending_audio until it is attached, stored once it is, and
+// audio_failed when it couldn't be; the raw upload then waits in persist_failures
+// for a backfill. Rows saved before this migration always had their audio attached.
+func init() {
+	m.Register(func(app core.App) error {
+		silence, err := app.FindCollectionByNameOrId("silence")
+		if err != nil {
+			return err
+		}
+
+		err = addMissingFields(app, "silence", &core.SelectField{
+			Name:      "status",
+			MaxSelect: 1,
+			Values:    []string{"pending_audio", "stored", "audio_failed"},
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = app.DB().NewQuery("UPDATE silence SET status = 'stored' WHERE status = ''").Execute()
+		if err != nil {
+			return err
+		}
+
+		return addMissingFields(app, "persist_failures", &core.RelationField{
+			Name:          "transcript",
+			CollectionId:  silence.Id,
+			MaxSelect:     1,
+			CascadeDelete: true,
+		})
+	}, func(app core.App) error {
+		if err := removeFields(app, "persist_failures", "transcript"); err != nil {
+			return err
+		}
+		return removeFields(app, "silence", "status")
+	})
+}
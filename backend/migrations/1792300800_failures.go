@@ -0,0 +1,59 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+// Creates the failures collection: transcription requests where every provider
+// failed, stored with the original upload, the request options and each
+// provider's error so they can be reproduced and replayed.
+func init() {
+	m.Register(func(app core.App) error {
+		if _, err := app.FindCollectionByNameOrId("failures"); err == nil {
+			return nil
+		}
+
+		silence, err := app.FindCollectionByNameOrId("silence")
+		if err != nil {
+			return err
+		}
+
+		failures := core.NewBaseCollection("failures")
+		failures.Fields.Add(
+			&core.FileField{
+				Name:      "audio",
+				MaxSelect: 1,
+				MaxSize:   200 << 20, // Uncompressed uploads
+				Protected: true,
+			},
+			&core.TextField{Name: "input_format", Max: 50},
+			&core.NumberField{Name: "sample_rate", OnlyInt: true},
+			&core.NumberField{Name: "channels", OnlyInt: true},
+			&core.NumberField{Name: "duration_ms", OnlyInt: true},
+			&core.TextField{Name: "requested_language", Max: 20},
+			&core.TextField{Name: "requested_provider", Max: 50},
+			&core.TextField{Name: "preprocess", Max: 255},
+			&core.JSONField{Name: "quality"},
+			&core.JSONField{Name: "client"},
+			&core.TextField{Name: "error"},
+			&core.JSONField{Name: "attempts"},
+			&core.NumberField{Name: "transcribe_ms", OnlyInt: true},
+			&core.JSONField{Name: "last_replay"},
+			&core.RelationField{
+				Name:         "transcript",
+				CollectionId: silence.Id,
+				MaxSelect:    1,
+			},
+			&core.AutodateField{Name: "created", OnCreate: true},
+			&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+		)
+		return app.Save(failures)
+	}, func(app core.App) error {
+		failures, err := app.FindCollectionByNameOrId("failures")
+		if err != nil {
+			return nil
+		}
+		return app.Delete(failures)
+	})
+}
@@ -0,0 +1,65 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+// Creates the api_keys collection. Each key belongs to an app and is stored
+// only as a SHA-256 hash with a short clear prefix to recognise it. Keys carry
+// scopes and are revoked rather than deleted. Transcripts and failures are
+// attributed to the calling app through their 'app' relation.
+func init() {
+	m.Register(func(app core.App) error {
+		apps, err := app.FindCollectionByNameOrId("apps")
+		if err != nil {
+			return err
+		}
+
+		if _, err := app.FindCollectionByNameOrId("api_keys"); err != nil {
+			keys := core.NewBaseCollection("api_keys")
+			keys.Fields.Add(
+				&core.RelationField{
+					Name:          "app",
+					CollectionId:  apps.Id,
+					MaxSelect:     1,
+					Required:      true,
+					CascadeDelete: true,
+				},
+				&core.TextField{Name: "name", Max: 255},
+				&core.TextField{Name: "prefix", Required: true, Max: 20},
+				&core.TextField{Name: "key_hash", Required: true, Hidden: true, Max: 64},
+				&core.SelectField{
+					Name:      "scopes",
+					MaxSelect: 3,
+					Required:  true,
+					Values:    []string{"speak", "transcripts:read", "transcripts:delete"},
+				},
+				&core.DateField{Name: "last_used_at"},
+				&core.DateField{Name: "revoked_at"},
+				&core.AutodateField{Name: "created", OnCreate: true},
+				&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+			)
+			keys.AddIndex("idx_api_keys_key_hash", true, "key_hash", "")
+			keys.AddIndex("idx_api_keys_app", false, "app", "")
+			if err := app.Save(keys); err != nil {
+				return err
+			}
+		}
+
+		return addMissingFields(app, "failures", &core.RelationField{
+			Name:         "app",
+			CollectionId: apps.Id,
+			MaxSelect:    1,
+		})
+	}, func(app core.App) error {
+		if err := removeFields(app, "failures", "app"); err != nil {
+			return err
+		}
+		keys, err := app.FindCollectionByNameOrId("api_keys")
+		if err != nil {
+			return nil
+		}
+		return app.Delete(keys)
+	})
+}
@@ -0,0 +1,64 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// ownerRule lets a signed-in user reach only the records they own.
+const ownerRule = "@request.auth.id != '' && owner = @request.auth.id"
+
+// Gives transcripts and failures an owner from the users auth collection and
+// opens the silence collection API to users for their own transcripts. Creating
+// and updating stay superuser-only; transcripts are only written by the server.
+func init() {
+	m.Register(func(app core.App) error {
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		owner := func() core.Field {
+			return &core.RelationField{
+				Name:          "owner",
+				CollectionId:  users.Id,
+				MaxSelect:     1,
+				CascadeDelete: true,
+			}
+		}
+		if err := addMissingFields(app, "silence", owner()); err != nil {
+			return err
+		}
+		if err := addMissingFields(app, "failures", owner()); err != nil {
+			return err
+		}
+
+		silence, err := app.FindCollectionByNameOrId("silence")
+		if err != nil {
+			return err
+		}
+		silence.ListRule = types.Pointer(ownerRule)
+		silence.ViewRule = types.Pointer(ownerRule)
+		silence.DeleteRule = types.Pointer(ownerRule)
+		silence.AddIndex("idx_silence_owner_created", false, "owner, created", "")
+		return app.Save(silence)
+	}, func(app core.App) error {
+		silence, err := app.FindCollectionByNameOrId("silence")
+		if err != nil {
+			return err
+		}
+		silence.ListRule = nil
+		silence.ViewRule = nil
+		silence.DeleteRule = nil
+		silence.RemoveIndex("idx_silence_owner_created")
+		if err := app.Save(silence); err != nil {
+			return err
+		}
+
+		if err := removeFields(app, "failures", "owner"); err != nil {
+			return err
+		}
+		return removeFields(app, "silence", "owner")
+	})
+}
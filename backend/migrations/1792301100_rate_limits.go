@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+// Adds per-app rate limits and audio quotas, and the usage_counters collection
+// that keeps audio usage per caller and UTC day or month across restarts.
+// Limits on apps: 0 inherits the server default, -1 is unlimited.
+func init() {
+	m.Register(func(app core.App) error {
+		err := addMissingFields(app, "apps",
+			&core.NumberField{Name: "rate_limit_rpm", OnlyInt: true},
+			&core.NumberField{Name: "quota_daily_minutes", OnlyInt: true},
+			&core.NumberField{Name: "quota_monthly_minutes", OnlyInt: true},
+			&core.NumberField{Name: "max_concurrent", OnlyInt: true},
+		)
+		if err != nil {
+			return err
+		}
+
+		if _, err := app.FindCollectionByNameOrId("usage_counters"); err == nil {
+			return nil
+		}
+
+		counters := core.NewBaseCollection("usage_counters")
+		counters.Fields.Add(
+			&core.TextField{Name: "caller", Required: true, Max: 100},
+			&core.TextField{Name: "period", Required: true, Max: 10},
+			&core.TextField{Name: "period_key", Required: true, Max: 10},
+			&core.NumberField{Name: "audio_ms", OnlyInt: true},
+		)
+		counters.AddIndex("idx_usage_counters_caller_period", true, "caller, period, period_key", "")
+		return app.Save(counters)
+	}, func(app core.App) error {
+		if counters, err := app.FindCollectionByNameOrId("usage_counters"); err == nil {
+			if err := app.Delete(counters); err != nil {
+				return err
+			}
+		}
+		return removeFields(app, "apps", "rate_limit_rpm", "quota_daily_minutes", "quota_monthly_minutes", "max_concurrent")
+	})
+}
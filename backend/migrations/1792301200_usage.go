@@ -0,0 +1,59 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+// Creates the usage collection with one record per successful provider request:
+// billable audio, provider and estimated cost. Usage is kept when the transcript,
+// app or user is deleted so spend history stays complete. Also adds the
+// usage:read API key scope.
+func init() {
+	m.Register(func(app core.App) error {
+		if err := setAPIKeyScopes(app, "speak", "transcripts:read", "transcripts:delete", "usage:read"); err != nil {
+			return err
+		}
+
+		if _, err := app.FindCollectionByNameOrId("usage"); err == nil {
+			return nil
+		}
+
+		silence, err := app.FindCollectionByNameOrId("silence")
+		if err != nil {
+			return err
+		}
+		apps, err := app.FindCollectionByNameOrId("apps")
+		if err != nil {
+			return err
+		}
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		usage := core.NewBaseCollection("usage")
+		usage.Fields.Add(
+			&core.TextField{Name: "provider", Required: true, Max: 50},
+			&core.NumberField{Name: "audio_ms", OnlyInt: true},
+			&core.NumberField{Name: "billable_seconds"},
+			&core.NumberField{Name: "cost_usd"},
+			&core.RelationField{Name: "transcript", CollectionId: silence.Id, MaxSelect: 1},
+			&core.RelationField{Name: "app", CollectionId: apps.Id, MaxSelect: 1},
+			&core.RelationField{Name: "owner", CollectionId: users.Id, MaxSelect: 1},
+			&core.BoolField{Name: "replay"},
+			&core.AutodateField{Name: "created", OnCreate: true},
+		)
+		usage.AddIndex("idx_usage_created", false, "created", "")
+		usage.AddIndex("idx_usage_app_created", false, "app, created", "")
+		usage.AddIndex("idx_usage_owner_created", false, "owner, created", "")
+		return app.Save(usage)
+	}, func(app core.App) error {
+		if usage, err := app.FindCollectionByNameOrId("usage"); err == nil {
+			if err := app.Delete(usage); err != nil {
+				return err
+			}
+		}
+		return setAPIKeyScopes(app, "speak", "transcripts:read", "transcripts:delete")
+	})
+}
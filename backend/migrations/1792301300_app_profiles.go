@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+// Adds the transcription profile of an app: defaults that /speak applies when
+// a request leaves an option out. Empty fields fall back to the server defaults.
+// Transcripts of apps that discard audio get the new 'discarded' status.
+func init() {
+	m.Register(func(app core.App) error {
+		err := addMissingFields(app, "apps",
+			&core.SelectField{Name: "default_provider", MaxSelect: 1, Values: []string{"elevenlabs", "chutes"}},
+			&core.TextField{Name: "default_language", Max: 16},
+			&core.JSONField{Name: "allowed_languages", MaxSize: 4 << 10},
+			&core.JSONField{Name: "vocabulary", MaxSize: 64 << 10},
+			&core.TextField{Name: "preprocess", Max: 255},
+			&core.JSONField{Name: "postprocess", MaxSize: 64 << 10},
+			&core.SelectField{Name: "output_format", MaxSelect: 1, Values: []string{"json", "text"}},
+			&core.BoolField{Name: "discard_audio"},
+		)
+		if err != nil {
+			return err
+		}
+		return setTranscriptStatuses(app, "pending_audio", "stored", "audio_failed", "discarded")
+	}, func(app core.App) error {
+		if err := setTranscriptStatuses(app, "pending_audio", "stored", "audio_failed"); err != nil {
+			return err
+		}
+		return removeFields(app, "apps", "default_provider", "default_language", "allowed_languages",
+			"vocabulary", "preprocess", "postprocess", "output_format", "discard_audio")
+	})
+}
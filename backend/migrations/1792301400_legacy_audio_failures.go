@@ -0,0 +1,72 @@
+package migrations
+
+import (
+	"silence-backend/logger"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+// legacyAudioJob names the dead letters of base64 audio that couldn't be converted.
+const legacyAudioJob = "convert_legacy_audio"
+
+// Finishes the base64 audio conversion of migration 1792300100 for rows that
+// failed it. Each is tried once more; the ones that still fail are moved to
+// persist_failures with their base64 text in the payload, and the legacy column
+// is dropped. Their transcript is marked legacy_audio_failed rather than
+// audio_failed: the text was already compressed audio, not a raw upload, so the
+// attach_audio backfill can't retry it and recovery is manual. Not reversible.
+func init() {
+	m.Register(func(app core.App) error {
+		err := setTranscriptStatuses(app, "pending_audio", "stored", "audio_failed", "discarded", "legacy_audio_failed")
+		if err != nil {
+			return err
+		}
+
+		collection, err := app.FindCollectionByNameOrId("silence")
+		if err != nil {
+			return err
+		}
+		if collection.Fields.GetByName(legacyAudioField) == nil {
+			return nil
+		}
+
+		failed, err := convertLegacyAudio(app, collection)
+		if err != nil {
+			return err
+		}
+
+		failures, err := app.FindCollectionByNameOrId("persist_failures")
+		if err != nil {
+			return err
+		}
+		for id, convertErr := range failed {
+			record, err := app.FindRecordById(collection, id)
+			if err != nil {
+				return err
+			}
+
+			deadLetter := core.NewRecord(failures)
+			deadLetter.Set("job", legacyAudioJob)
+			deadLetter.Set("transcript", id)
+			deadLetter.Set("error", convertErr.Error())
+			deadLetter.Set("attempts", 2)
+			deadLetter.Set("payload", map[string]string{legacyAudioField: record.GetString(legacyAudioField)})
+			if err := app.Save(deadLetter); err != nil {
+				return err
+			}
+
+			// Raw update so the status change doesn't touch the updated timestamp
+			_, err = app.DB().Update("silence", dbx.Params{"status": "legacy_audio_failed"}, dbx.HashExp{"id": id}).Execute()
+			if err != nil {
+				return err
+			}
+		}
+		if len(failed) > 0 {
+			logger.Info("Moved unconvertible base64 audio to persist_failures", "records", len(failed))
+		}
+
+		return removeLegacyAudioField(app, collection)
+	}, nil)
+}
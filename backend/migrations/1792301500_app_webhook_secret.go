@@ -0,0 +1,85 @@
+package migrations
+
+import (
+	"encoding/json"
+	"strings"
+
+	"silence-backend/logger"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+// Moves the webhook secret of app commands out of the description, which
+// anyone who can read the app sees, into a hidden 'webhook_secret' field.
+func init() {
+	m.Register(func(app core.App) error {
+		if err := addMissingFields(app, "apps", &core.TextField{Name: "webhook_secret", Max: 255, Hidden: true}); err != nil {
+			return err
+		}
+
+		records, err := app.FindAllRecords("apps")
+		if err != nil {
+			return err
+		}
+		moved := 0
+		for _, record := range records {
+			description, ok := descriptionObject(record)
+			if !ok || description["webhook_secret"] == nil {
+				continue
+			}
+
+			var secret string
+			if err := json.Unmarshal(description["webhook_secret"], &secret); err != nil {
+				logger.Error("Dropping a webhook_secret that isn't a string", "app", record.Id)
+			}
+			delete(description, "webhook_secret")
+			record.Set("description", description)
+			record.Set("webhook_secret", secret)
+			if err := app.SaveNoValidate(record); err != nil {
+				return err
+			}
+			moved++
+		}
+		if moved > 0 {
+			logger.Info("Moved webhook secrets out of app descriptions", "apps", moved)
+		}
+		return nil
+	}, func(app core.App) error {
+		records, err := app.FindAllRecords("apps")
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			secret := record.GetString("webhook_secret")
+			if secret == "" {
+				continue
+			}
+			description, ok := descriptionObject(record)
+			if !ok {
+				continue
+			}
+
+			description["webhook_secret"], _ = json.Marshal(secret)
+			record.Set("description", description)
+			if err := app.SaveNoValidate(record); err != nil {
+				return err
+			}
+		}
+		return removeFields(app, "apps", "webhook_secret")
+	})
+}
+
+// descriptionObject reads an app's description as a JSON object, keeping
+// every key as it is. It reports false for descriptions that aren't objects.
+func descriptionObject(record *core.Record) (map[string]json.RawMessage, bool) {
+	raw := strings.TrimSpace(record.GetString("description"))
+	if !strings.HasPrefix(raw, "{") {
+		return nil, false
+	}
+	var description map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &description); err != nil {
+		return nil, false
+	}
+	return description, true
+}
@@ -0,0 +1,19 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+// Keeps the vocabulary and postprocessing a failed request asked for, so a
+// replay transcribes it the way the client wanted.
+func init() {
+	m.Register(func(app core.App) error {
+		return addMissingFields(app, "failures",
+			&core.JSONField{Name: "vocabulary", MaxSize: 64 << 10},
+			&core.TextField{Name: "postprocess", Max: 64 << 10},
+		)
+	}, func(app core.App) error {
+		return removeFields(app, "failures", "vocabulary", "postprocess")
+	})
+}
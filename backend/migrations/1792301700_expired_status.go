@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+// Adds the 'expired' status, set on transcripts whose audio was deleted by the
+// retention policy. Reverting turns them into 'discarded', which also means the
+// audio isn't kept.
+func init() {
+	m.Register(func(app core.App) error {
+		return setTranscriptStatuses(app, "pending_audio", "stored", "audio_failed", "discarded", "legacy_audio_failed", "expired")
+	}, func(app core.App) error {
+		_, err := app.DB().Update("silence", dbx.Params{"status": "discarded"}, dbx.HashExp{"status": "expired"}).Execute()
+		if err != nil {
+			return err
+		}
+		return setTranscriptStatuses(app, "pending_audio", "stored", "audio_failed", "discarded", "legacy_audio_failed")
+	})
+}
@@ -0,0 +1,69 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// addMissingFields adds fields that the collection doesn't have yet.
+// Collections upgraded by the pre-migration startup code may already have some of them.
+func addMissingFields(app core.App, collectionName string, fields ...core.Field) error {
+	collection, err := app.FindCollectionByNameOrId(collectionName)
+	if err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		if collection.Fields.GetByName(field.GetName()) == nil {
+			collection.Fields.Add(field)
+		}
+	}
+
+	return app.Save(collection)
+}
+
+// removeFields drops fields by name, ignoring ones that don't exist.
+func removeFields(app core.App, collectionName string, names ...string) error {
+	collection, err := app.FindCollectionByNameOrId(collectionName)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		collection.Fields.RemoveByName(name)
+	}
+
+	return app.Save(collection)
+}
+
+// setAPIKeyScopes replaces the scopes an API key can be granted.
+func setAPIKeyScopes(app core.App, scopes ...string) error {
+	collection, err := app.FindCollectionByNameOrId("api_keys")
+	if err != nil {
+		return err
+	}
+
+	field, ok := collection.Fields.GetByName("scopes").(*core.SelectField)
+	if !ok {
+		return nil
+	}
+	field.Values = scopes
+	field.MaxSelect = len(scopes)
+
+	return app.Save(collection)
+}
+
+// setTranscriptStatuses replaces the values of the silence status field.
+func setTranscriptStatuses(app core.App, statuses ...string) error {
+	collection, err := app.FindCollectionByNameOrId("silence")
+	if err != nil {
+		return err
+	}
+
+	field, ok := collection.Fields.GetByName("status").(*core.SelectField)
+	if !ok {
+		return nil
+	}
+	field.Values = statuses
+
+	return app.Save(collection)
+}
@@ -0,0 +1,260 @@
+package postprocess
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Dictation actions a command phrase can trigger.
+const (
+	actionNewLine     = "new_line"
+	actionParagraph   = "new_paragraph"
+	actionPeriod      = "period"
+	actionComma       = "comma"
+	actionQuestion    = "question_mark"
+	actionExclamation = "exclamation_mark"
+	actionColon       = "colon"
+	actionSemicolon   = "semicolon"
+	actionOpenQuote   = "open_quote"
+	actionCloseQuote  = "close_quote"
+	actionScratch     = "scratch_that" // Delete the previous sentence
+)
+
+// dictationMarks are the punctuation actions and the mark each one writes.
+var dictationMarks = map[string]string{
+	actionPeriod:      ".",
+	actionComma:       ",",
+	actionQuestion:    "?",
+	actionExclamation: "!",
+	actionColon:       ":",
+	actionSemicolon:   ";",
+}
+
+// dictationActions lists every action, for validating app commands.
+var dictationActions = []string{
+	actionNewLine, actionParagraph, actionPeriod, actionComma, actionQuestion, actionExclamation,
+	actionColon, actionSemicolon, actionOpenQuote, actionCloseQuote, actionScratch,
+}
+
+// dictationCommands are the spoken command phrases per language and the action of each.
+var dictationCommands = map[string]map[string]string{
+	"en": {
+		"new line": actionNewLine, "newline": actionNewLine, "next line": actionNewLine,
+		"new paragraph": actionParagraph, "next paragraph": actionParagraph,
+		"period": actionPeriod, "full stop": actionPeriod,
+		"comma":            actionComma,
+		"question mark":    actionQuestion,
+		"exclamation mark": actionExclamation, "exclamation point": actionExclamation,
+		"colon":     actionColon,
+		"semicolon": actionSemicolon, "semi colon": actionSemicolon,
+		"open quote": actionOpenQuote, "begin quote": actionOpenQuote,
+		"close quote": actionCloseQuote, "end quote": actionCloseQuote, "unquote": actionCloseQuote,
+		"scratch that": actionScratch, "delete that": actionScratch, "strike that": actionScratch,
+	},
+	"ru": {
+		"новая строка": actionNewLine, "с новой строки": actionNewLine,
+		"новый абзац": actionParagraph, "с нового абзаца": actionParagraph,
+		"точка":                actionPeriod,
+		"запятая":              actionComma,
+		"вопросительный знак":  actionQuestion,
+		"восклицательный знак": actionExclamation,
+		"двоеточие":            actionColon,
+		"точка с запятой":      actionSemicolon,
+		"открыть кавычки":      actionOpenQuote, "открой кавычки": actionOpenQuote, "кавычки открываются": actionOpenQuote,
+		"закрыть кавычки": actionCloseQuote, "закрой кавычки": actionCloseQuote, "кавычки закрываются": actionCloseQuote,
+		"отменить это": actionScratch, "удалить это": actionScratch, "удали это": actionScratch, "зачеркни это": actionScratch,
+	},
+}
+
+// dictationTable is the command phrases of one language, keyed by commandKey.
+type dictationTable struct {
+	re      *regexp.Regexp
+	actions map[string]string
+}
+
+// dictation turns spoken commands into formatting: punctuation, line breaks,
+// quotes, and "scratch that" to delete the previous sentence. A phrase is
+// only a command when it's said on its own, with a pause before and after it
+// that the provider punctuated, the start or end of the text, or another
+// command; "the meeting period ended" keeps its words. It should run before
+// steps that read the words it consumes, like "itn" reading "запятая" as a
+// decimal comma.
+type dictation struct {
+	tables map[string]dictationTable // By language, "" for unknown languages
+}
+
+func newDictation(spec StepSpec) (Step, error) {
+	extra := make(map[string]string, len(spec.Commands))
+	for phrase, action := range spec.Commands {
+		if strings.TrimSpace(phrase) == "" {
+			return nil, fmt.Errorf("dictation commands can't have an empty phrase")
+		}
+		if !slices.Contains(dictationActions, action) {
+			return nil, fmt.Errorf("unknown dictation action %q for %q, expected one of: %s", action, phrase, strings.Join(dictationActions, ", "))
+		}
+		extra[commandKey(phrase)] = action
+	}
+
+	all := make(map[string]string)
+	tables := make(map[string]dictationTable, len(dictationCommands)+1)
+	for lang, commands := range dictationCommands {
+		maps.Copy(all, commands)
+		tables[lang] = newDictationTable(commands, extra)
+	}
+	tables[""] = newDictationTable(all, extra)
+	return &dictation{tables: tables}, nil
+}
+
+// newDictationTable compiles a language's commands with the app's extra commands on top.
+func newDictationTable(commands, extra map[string]string) dictationTable {
+	table := dictationTable{actions: make(map[string]string, len(commands)+len(extra))}
+	for phrase, action := range commands {
+		table.actions[commandKey(phrase)] = action
+	}
+	maps.Copy(table.actions, extra)
+
+	alternatives := make([]string, 0, len(table.actions))
+	for _, phrase := range slices.Sorted(maps.Keys(table.actions)) {
+		alternatives = append(alternatives, literalPattern(phrase))
+	}
+	table.re = wordPattern(alternatives)
+	return table
+}
+
+// commandKey normalizes a command phrase for lookup.
+func commandKey(phrase string) string {
+	return strings.Join(strings.Fields(strings.ToLower(phrase)), " ")
+}
+
+func (*dictation) Name() string { return "dictation" }
+
+func (d *dictation) Apply(text, language string) string {
+	table, ok := d.tables[baseLanguage(language)]
+	if !ok {
+		table = d.tables[""]
+	}
+
+	matches := wholeWordMatches(text, table.re)
+	if len(matches) == 0 {
+		return text
+	}
+
+	w := dictationWriter{}
+	last := 0
+	commanded := false
+	for k, m := range matches {
+		// Another command next to this one counts as a pause
+		before := (commanded && strings.TrimSpace(text[last:m[0]]) == "") || pauseEnds(text[:m[0]])
+		after := (k+1 < len(matches) && strings.TrimSpace(text[m[1]:matches[k+1][0]]) == "") || pauseStarts(text[m[1]:])
+		if !before || !after {
+			continue
+		}
+		w.write(text[last:m[0]])
+		w.command(table.actions[commandKey(text[m[0]:m[1]])])
+		last = m[1]
+		commanded = true
+	}
+	w.write(text[last:])
+	return strings.TrimSpace(w.out)
+}
+
+// pauseEnds reports whether text ends at a pause: it's empty or the provider
+// punctuated its end.
+func pauseEnds(text string) bool {
+	r, _ := utf8.DecodeLastRuneInString(strings.TrimRight(text, " \t"))
+	return r == utf8.RuneError || strings.ContainsRune(providerPunctuation+"\n", r)
+}
+
+// pauseStarts reports whether text starts at a pause: it's empty or starts
+// with the provider's punctuation.
+func pauseStarts(text string) bool {
+	r, _ := utf8.DecodeRuneInString(strings.TrimLeft(text, " \t"))
+	return r == utf8.RuneError || strings.ContainsRune(providerPunctuation+"\n", r)
+}
+
+// dictationWriter builds the output of a dictation run. Providers often
+// punctuate around spoken commands ("Hello, comma, world."), so punctuation
+// next to a command belongs to it and is dropped.
+type dictationWriter struct {
+	out        string
+	skipLead   bool // Drop the punctuation and spaces at the start of the next text
+	capitalize bool // Capitalize the next text, it starts a sentence
+	openQuote  bool // The next text follows an opening quote, without a space
+}
+
+// providerPunctuation is what a provider may have put around a command.
+const providerPunctuation = ",.;:!?…"
+
+func (w *dictationWriter) write(text string) {
+	if text == "" {
+		return
+	}
+	if w.skipLead {
+		text = strings.TrimLeft(text, " "+providerPunctuation)
+		if text == "" {
+			return
+		}
+		w.skipLead = false
+	}
+	if w.capitalize {
+		r, size := utf8.DecodeRuneInString(text)
+		text = string(unicode.ToUpper(r)) + text[size:]
+		w.capitalize = false
+	}
+	if w.openQuote {
+		w.out += text
+		w.openQuote = false
+		return
+	}
+	if w.out != "" && !strings.HasSuffix(w.out, "\n") && !strings.HasSuffix(w.out, " ") && !strings.HasPrefix(text, " ") {
+		w.out += " "
+	}
+	w.out += text
+}
+
+// trimEnd drops the trailing spaces and the provider's punctuation before a command.
+func (w *dictationWriter) trimEnd() {
+	w.out = strings.TrimRight(w.out, " "+providerPunctuation)
+}
+
+func (w *dictationWriter) command(action string) {
+	w.skipLead = true
+	switch action {
+	case actionNewLine, actionParagraph:
+		w.out = strings.TrimRight(w.out, " ")
+		if w.out != "" {
+			w.out += "\n"
+			if action == actionParagraph {
+				w.out += "\n"
+			}
+		}
+		w.capitalize = true
+	case actionOpenQuote:
+		w.out = strings.TrimRight(w.out, " ")
+		if w.out != "" && !strings.HasSuffix(w.out, "\n") {
+			w.out += " "
+		}
+		w.out += `"`
+		w.openQuote = true
+	case actionCloseQuote:
+		// Keep the sentence's own punctuation inside the quotes
+		w.out = strings.TrimRight(w.out, " ") + `"`
+	case actionScratch:
+		w.trimEnd()
+		cut := strings.LastIndexAny(w.out, ".!?\n")
+		w.out = w.out[:cut+1]
+		w.openQuote = false
+		w.capitalize = cut >= 0 || w.out == ""
+	default:
+		mark := dictationMarks[action]
+		w.trimEnd()
+		w.out += mark
+		w.capitalize = mark == "." || mark == "?" || mark == "!"
+		w.openQuote = false
+	}
+}
@@ -0,0 +1,56 @@
+package postprocess
+
+import "testing"
+
+func TestDictation(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		commands map[string]string
+		text     string
+		want     string
+	}{
+		{"punctuated commands", "en", nil, "Hello, comma, world. Period.", "Hello, world."},
+		{"question mark", "en", nil, "Are you there? Question mark.", "Are you there?"},
+		{"new line", "en", nil, "Dear team. New line. Thanks.", "Dear team.\nThanks."},
+		{"new paragraph", "en", nil, "First part. New paragraph. Second part.", "First part.\n\nSecond part."},
+		{"quotes", "en", nil, "He said: open quote, hello. Close quote.", `He said: "hello."`},
+		{"scratch that", "en", nil, "I like apples. Scratch that. I like pears.", "I like pears."},
+		{"scratch that keeps earlier sentences", "en", nil, "We met. I was late. Scratch that. I was early.", "We met. I was early."},
+		{"commands next to each other", "en", nil, "Hello, period new line, world.", "Hello.\nWorld."},
+		{"command ends the text", "en", nil, "See you tomorrow, period", "See you tomorrow."},
+		{"command starts the text", "en", nil, "New line. Hello.", "Hello."},
+		{"word inside a sentence", "en", nil, "the meeting period ended", "the meeting period ended"},
+		{"word before a pause", "en", nil, "We offer a trial period. It's free.", "We offer a trial period. It's free."},
+		{"word after a pause", "en", nil, "Comma separated values work.", "Comma separated values work."},
+		{"word inside a word", "en", nil, "periodic checks", "periodic checks"},
+		{"russian commands", "ru", nil, "Привет, запятая, мир. Точка.", "Привет, мир."},
+		{"russian word", "ru", nil, "с моей точка зрения верна", "с моей точка зрения верна"},
+		{"app command", "en", map[string]string{"stop": actionPeriod}, "We are done, stop.", "We are done."},
+		{"unknown language", "", nil, "Hello, запятая, world.", "Hello, world."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, err := newDictation(StepSpec{Commands: tt.commands})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := step.Apply(tt.text, tt.language); got != tt.want {
+				t.Errorf("Apply(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDictationInvalidCommand(t *testing.T) {
+	tests := []map[string]string{
+		{" ": actionPeriod},
+		{"stop": "halt"},
+	}
+	for _, commands := range tests {
+		if _, err := newDictation(StepSpec{Commands: commands}); err == nil {
+			t.Errorf("newDictation(%v) succeeded, want an error", commands)
+		}
+	}
+}
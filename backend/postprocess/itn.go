@@ -0,0 +1,695 @@
+package postprocess
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// itn is inverse text normalization: spoken numbers, dates, times, money,
+// percentages and units are written the usual way, so "twenty five dollars on
+// march third at five pm" becomes "$25 on March 3 at 5 PM". Single number
+// words below ten are left alone unless a unit, currency or time follows.
+type itn struct{}
+
+func newITN(StepSpec) (Step, error) { return itn{}, nil }
+
+func (itn) Name() string { return "itn" }
+
+func (itn) Apply(text, language string) string {
+	if lex, ok := lexicons[baseLanguage(language)]; ok {
+		return lex.normalize(text)
+	}
+	// The languages use different scripts, so both can run on unknown text
+	for _, lang := range []string{"en", "ru"} {
+		text = lexicons[lang].normalize(text)
+	}
+	return text
+}
+
+// lexicons holds the spoken forms of each supported language.
+var lexicons = map[string]*lexicon{
+	"en": english,
+	"ru": russian,
+}
+
+// wordKind is the grammatical role of a number word.
+type wordKind int
+
+const (
+	kindNone     wordKind = iota
+	kindUnit              // 0-19
+	kindTen               // 20, 30, ... 90
+	kindHundreds          // A whole hundred in one word, e.g. Russian "двести"
+	kindHundred           // Multiplies by a hundred, e.g. English "hundred"
+	kindScale             // Thousand, million, billion, trillion
+)
+
+// numberWord is one form of a number word.
+type numberWord struct {
+	kind    wordKind
+	value   int64
+	ordinal bool
+}
+
+// suffix is a word or phrase after a number that is written as a symbol.
+type suffix struct {
+	words  []string // Spoken forms, lowercase
+	symbol string
+	prefix bool // Symbol goes before the number, as in "$25"
+	space  bool // Space between number and symbol, as in "5 km"
+	subs   *subunit
+}
+
+// subunit is an optional hundredth of a currency that may follow it, as in
+// "five dollars and twenty cents".
+type subunit struct {
+	words []string
+}
+
+// lexicon holds a language's spoken forms and writing conventions.
+type lexicon struct {
+	numbers      map[string]numberWord
+	and          map[string]bool // Words allowed inside a number, e.g. "one hundred and five"
+	one          map[string]bool // Words standing for one before a hundred or a scale, e.g. "a thousand"
+	bareScales   bool            // A scale word alone is a number, e.g. "тысяча"
+	largeScales  map[string]bool // Scale words past the supported range, numbers with them are kept as words
+	decimal      map[string]bool // Decimal point words
+	fractions    map[string]int  // Words naming the decimal places, "сотых" for two
+	months       map[string]string
+	monthFirst   bool            // Dates may start with the month, "march third"
+	vagueMonths  map[string]bool // Months that are also common words, only read as months before an ordinal
+	dateJoiner   string          // Word between an ordinal and a month, e.g. "of"
+	yearWords    map[string]bool
+	hourWords    map[string]bool
+	minuteWords  map[string]bool
+	oClock       map[string]bool
+	suffixes     []suffix
+	decimalSep   string
+	groupSep     string
+	ordinalForm  func(n int64) string // Written ordinal outside dates, "" to keep the words
+	spokenYears  bool                 // Years are said in pairs, "nineteen ninety"
+	zeroForYears string               // Spoken zero in years and times, e.g. "oh"
+	meridiem     *regexp.Regexp       // AM or PM after a time
+}
+
+// token is a word of the text.
+type token struct {
+	start, end int
+	lower      string
+}
+
+// tokenize splits text into words: runs of letters and digits with
+// apostrophes and hyphens inside them.
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	flush := func(end int) {
+		word := strings.TrimRight(text[start:end], "'’-")
+		tokens = append(tokens, token{start, start + len(word), strings.ToLower(word)})
+		start = -1
+	}
+	for i, r := range text {
+		inner := r == '\'' || r == '’' || r == '-'
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || (inner && start >= 0) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			flush(i)
+		}
+	}
+	if start >= 0 {
+		flush(len(text))
+	}
+	return tokens
+}
+
+// number is a spoken number found in the tokens.
+type number struct {
+	start, end int // Token range
+	value      int64
+	fraction   string // Digits after the decimal separator
+	ordinal    bool
+	pair       bool // A 10-99 from teen and tens words only: half of a spoken year
+	multiword  bool
+	digits     bool // Already written in digits
+	malformed  bool // Repeats a scale or has one past the supported range, so it is kept as words
+}
+
+// edit replaces a byte range of the text.
+type edit struct {
+	start, end int
+	text       string
+}
+
+// normalize rewrites the spoken forms of the language in text.
+func (l *lexicon) normalize(text string) string {
+	tokens := tokenize(text)
+	var edits []edit
+
+	for i := 0; i < len(tokens); {
+		e, next := l.match(text, tokens, i)
+		if next <= i {
+			i++
+			continue
+		}
+		edits = append(edits, e)
+		i = next
+	}
+
+	if len(edits) == 0 {
+		return text
+	}
+	var sb strings.Builder
+	last := 0
+	for _, e := range edits {
+		sb.WriteString(text[last:e.start])
+		sb.WriteString(e.text)
+		last = e.end
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
+
+// match tries every rule at token i. It returns the edit and the token after
+// it, or i when nothing matched.
+func (l *lexicon) match(text string, tokens []token, i int) (edit, int) {
+	// Month followed by a day: "march third"
+	if month, ok := l.months[tokens[i].lower]; ok && l.monthFirst && l.adjacent(text, tokens, i, i+1) {
+		if day, ok := l.parse(text, tokens, i+1); ok && day.isDay() && (day.ordinal || !l.vagueMonths[tokens[i].lower]) {
+			written := month + " " + strconv.FormatInt(day.value, 10)
+			end := day.end
+			if year, ok := l.year(text, tokens, end); ok {
+				written += ", " + strconv.FormatInt(year.value, 10)
+				end = year.end
+			}
+			return l.replace(tokens, i, end, written), end
+		}
+	}
+
+	// "the" before a date is dropped with it: "on the third of march"
+	start := i
+	if tokens[i].lower == "the" && l.dateJoiner != "" && l.adjacent(text, tokens, i, i+1) {
+		i++
+	}
+
+	n, ok := l.parse(text, tokens, i)
+	if n.malformed {
+		// Kept whole, so no part of it is converted on its own
+		return l.replace(tokens, i, n.end, text[tokens[i].start:tokens[n.end-1].end]), n.end
+	}
+	if !ok {
+		return edit{}, start
+	}
+
+	// Day followed by a month: "third of march", "третьего марта"
+	if n.ordinal && n.isDay() {
+		j := n.end
+		if l.dateJoiner != "" && j < len(tokens) && tokens[j].lower == l.dateJoiner && l.adjacent(text, tokens, j-1, j) {
+			j++
+		}
+		if j < len(tokens) && l.adjacent(text, tokens, j-1, j) {
+			if month, ok := l.months[tokens[j].lower]; ok {
+				end := j + 1
+				var written string
+				if l.dateJoiner != "" {
+					written = month + " " + strconv.FormatInt(n.value, 10)
+					if year, ok := l.year(text, tokens, end); ok {
+						written += ", " + strconv.FormatInt(year.value, 10)
+						end = year.end
+					}
+				} else {
+					written = strconv.FormatInt(n.value, 10) + " " + tokens[j].lower
+				}
+				return l.replace(tokens, start, end, written), end
+			}
+		}
+	}
+	if start != i {
+		return edit{}, start
+	}
+
+	// Year: "две тысячи двадцать пятого года", "in nineteen ninety"
+	if n.ordinal && n.value >= 1000 && n.end < len(tokens) && l.yearWords[tokens[n.end].lower] && l.adjacent(text, tokens, n.end-1, n.end) {
+		return l.replace(tokens, i, n.end, strconv.FormatInt(n.value, 10)), n.end
+	}
+	if l.spokenYears && i > 0 && tokens[i-1].lower == "in" {
+		if year, ok := l.year(text, tokens, i); ok && year.end > n.end {
+			return l.replace(tokens, i, year.end, strconv.FormatInt(year.value, 10)), year.end
+		}
+	}
+
+	if e, end, ok := l.time(text, tokens, n); ok {
+		return e, end
+	}
+	if e, end, ok := l.withSuffix(text, tokens, n); ok {
+		return e, end
+	}
+
+	if n.ordinal {
+		// "twenty second" is more often a duration than an ordinal
+		if l.ordinalForm == nil || (!n.multiword && n.value < 11) || tokens[n.end-1].lower == "second" {
+			return edit{}, i
+		}
+		if written := l.ordinalForm(n.value); written != "" {
+			return l.replace(tokens, i, n.end, written), n.end
+		}
+		return edit{}, i
+	}
+	if n.digits || (!n.multiword && n.value < 10 && n.fraction == "") {
+		return edit{}, i
+	}
+	// Outside "in", a spoken year can't be told from two numbers or a time
+	// ("twenty twenty", "ten fifteen"), so its words are kept
+	if l.spokenYears && n.pair {
+		if year, ok := l.yearAt(text, tokens, i); ok && year.end > n.end {
+			return l.replace(tokens, i, year.end, text[tokens[i].start:tokens[year.end-1].end]), year.end
+		}
+	}
+	return l.replace(tokens, i, n.end, l.format(n)), n.end
+}
+
+// isDay reports whether a number can be the day of a month.
+func (n number) isDay() bool {
+	return n.value >= 1 && n.value <= 31 && n.fraction == ""
+}
+
+// replace builds the edit replacing tokens [from, to).
+func (l *lexicon) replace(tokens []token, from, to int, written string) edit {
+	return edit{tokens[from].start, tokens[to-1].end, written}
+}
+
+// adjacent reports whether tokens i and j exist and only whitespace separates them.
+func (l *lexicon) adjacent(text string, tokens []token, i, j int) bool {
+	if i < 0 || j >= len(tokens) {
+		return false
+	}
+	return strings.TrimSpace(text[tokens[i].end:tokens[j].start]) == ""
+}
+
+// format writes a cardinal number, with digit grouping from ten thousand up.
+func (l *lexicon) format(n number) string {
+	digits := strconv.FormatInt(n.value, 10)
+	if n.value >= 10000 {
+		var sb strings.Builder
+		for i, r := range digits {
+			if i > 0 && (len(digits)-i)%3 == 0 {
+				sb.WriteString(l.groupSep)
+			}
+			sb.WriteRune(r)
+		}
+		digits = sb.String()
+	}
+	if n.fraction != "" {
+		digits += l.decimalSep + n.fraction
+	}
+	return digits
+}
+
+// parseState accumulates a number word by word.
+type parseState struct {
+	total, current int64
+	last           wordKind
+	lastScale      int64
+	ordinal        bool
+	onlyPairWords  bool
+	bareScale      bool // A scale word may start the number
+	words          int
+}
+
+// add combines the next word into the number, or reports that it can't be.
+func (s *parseState) add(w numberWord) bool {
+	switch w.kind {
+	case kindUnit:
+		if s.last == kindUnit || (s.last == kindTen && (w.value == 0 || w.value >= 10)) || (w.value == 0 && s.last != kindNone) {
+			return false
+		}
+		s.current += w.value
+	case kindTen:
+		if s.last == kindUnit || s.last == kindTen {
+			return false
+		}
+		s.current += w.value
+	case kindHundreds:
+		if s.last != kindNone && s.last != kindScale {
+			return false
+		}
+		s.current += w.value
+		s.onlyPairWords = false
+	case kindHundred:
+		if (s.last != kindUnit && s.last != kindTen) || s.current%1000 >= 100 {
+			return false
+		}
+		s.current = s.current/100*100 + s.current%100*w.value
+		s.onlyPairWords = false
+	case kindScale:
+		if w.value >= s.lastScale {
+			return false
+		}
+		if s.current == 0 {
+			if s.last != kindNone || !s.bareScale {
+				return false
+			}
+			s.current = 1
+		}
+		s.total += s.current * w.value
+		s.current = 0
+		s.lastScale = w.value
+		s.onlyPairWords = false
+	}
+	s.last = w.kind
+	s.ordinal = w.ordinal
+	s.words++
+	return true
+}
+
+// parse reads the number starting at token i. A malformed number isn't ok,
+// but still reports its extent so the caller can skip it.
+func (l *lexicon) parse(text string, tokens []token, i int) (number, bool) {
+	if i >= len(tokens) {
+		return number{}, false
+	}
+	if isDigits(tokens[i].lower) {
+		value, err := strconv.ParseInt(tokens[i].lower, 10, 64)
+		if err != nil || len(tokens[i].lower) > 15 {
+			return number{}, false
+		}
+		return number{start: i, end: i + 1, value: value, digits: true}, true
+	}
+
+	s := parseState{lastScale: 1 << 62, onlyPairWords: true, bareScale: l.bareScales}
+	j := i
+	// "a thousand" is one thousand
+	if l.one[tokens[i].lower] && l.adjacent(text, tokens, i, i+1) {
+		if w, ok := l.numbers[tokens[i+1].lower]; ok && !w.ordinal && (w.kind == kindHundred || w.kind == kindScale) {
+			s.add(numberWord{kind: kindUnit, value: 1})
+			j++
+		}
+	}
+	for j < len(tokens) && !s.ordinal {
+		if j > i && !l.adjacent(text, tokens, j-1, j) {
+			break
+		}
+		if l.and[tokens[j].lower] {
+			// Only inside a number, after a hundred or a scale
+			if s.last != kindHundred && s.last != kindHundreds && s.last != kindScale || !l.adjacent(text, tokens, j, j+1) {
+				break
+			}
+			if _, ok := l.numbers[strings.Split(tokens[j+1].lower, "-")[0]]; !ok {
+				break
+			}
+			j++
+			continue
+		}
+
+		saved := s
+		parsed := true
+		for _, part := range strings.Split(tokens[j].lower, "-") {
+			w, ok := l.numbers[part]
+			if !ok || !s.add(w) {
+				parsed = false
+				break
+			}
+		}
+		if !parsed {
+			s = saved
+			break
+		}
+		j++
+	}
+	if j < len(tokens) && (j == i || l.adjacent(text, tokens, j-1, j)) && l.stopsAtScale(tokens[j].lower, j > i) {
+		return number{start: i, end: l.phraseEnd(text, tokens, j), malformed: true}, false
+	}
+	if s.words == 0 {
+		return number{}, false
+	}
+
+	n := number{
+		start:     i,
+		end:       j,
+		value:     s.total + s.current,
+		ordinal:   s.ordinal,
+		multiword: s.words > 1,
+	}
+	n.pair = s.onlyPairWords && n.value >= 10 && n.value <= 99 && !n.ordinal
+	if !n.ordinal {
+		l.parseFraction(text, tokens, &n)
+	}
+	return n, true
+}
+
+// stopsAtScale reports whether a number ends at a scale word it can't take:
+// one past the supported range, or, inside a number, one that repeats or
+// exceeds the scale before it, as in "тысяча тысяч".
+func (l *lexicon) stopsAtScale(word string, inside bool) bool {
+	if l.largeScales[word] {
+		return true
+	}
+	w, ok := l.numbers[word]
+	return inside && ok && w.kind == kindScale && !w.ordinal
+}
+
+// phraseEnd returns the token after the run of number words starting at j.
+func (l *lexicon) phraseEnd(text string, tokens []token, j int) int {
+	for j++; j < len(tokens) && l.adjacent(text, tokens, j-1, j); j++ {
+		word := tokens[j].lower
+		if _, ok := l.numbers[strings.Split(word, "-")[0]]; !ok && !l.largeScales[word] && !l.and[word] {
+			break
+		}
+	}
+	return j
+}
+
+// parseFraction extends a number with a spoken decimal part: digit by digit
+// ("three point one four") or as a whole number ("три запятая двадцать пять").
+func (l *lexicon) parseFraction(text string, tokens []token, n *number) {
+	j := n.end
+	if j >= len(tokens) || !l.decimal[tokens[j].lower] || !l.adjacent(text, tokens, j-1, j) {
+		return
+	}
+
+	var digits strings.Builder
+	k := j + 1
+	for k < len(tokens) && l.adjacent(text, tokens, k-1, k) {
+		w, ok := l.numbers[tokens[k].lower]
+		if !ok || w.kind != kindUnit || w.value > 9 || w.ordinal {
+			break
+		}
+		digits.WriteString(strconv.FormatInt(w.value, 10))
+		k++
+	}
+	if digits.Len() > 1 {
+		n.fraction, n.end = digits.String(), k
+		return
+	}
+	whole, ok := l.parse(text, tokens, j+1)
+	if !ok || whole.ordinal || whole.fraction != "" {
+		return
+	}
+	n.fraction, n.end = strconv.FormatInt(whole.value, 10), whole.end
+	// "пять сотых" is 0.05, so pad to the named number of places
+	if k := whole.end; k < len(tokens) && l.adjacent(text, tokens, k-1, k) {
+		if places, ok := l.fractions[tokens[k].lower]; ok && len(n.fraction) <= places {
+			n.fraction = strings.Repeat("0", places-len(n.fraction)) + n.fraction
+			n.end = k + 1
+		}
+	}
+}
+
+// year reads a year at token i, right after the words before it.
+func (l *lexicon) year(text string, tokens []token, i int) (number, bool) {
+	if i >= len(tokens) || !l.adjacent(text, tokens, i-1, i) {
+		return number{}, false
+	}
+	return l.yearAt(text, tokens, i)
+}
+
+// yearAt reads a year at token i: a cardinal from 1000 or a spoken pair such
+// as "nineteen ninety" or "twenty oh five".
+func (l *lexicon) yearAt(text string, tokens []token, i int) (number, bool) {
+	first, ok := l.parse(text, tokens, i)
+	if !ok || first.ordinal {
+		return number{}, false
+	}
+	if first.value >= 1000 && first.value <= 2999 && first.fraction == "" {
+		return first, true
+	}
+	if !l.spokenYears || !first.pair || first.end >= len(tokens) || !l.adjacent(text, tokens, first.end-1, first.end) {
+		return number{}, false
+	}
+
+	// "twenty oh five"
+	if tokens[first.end].lower == l.zeroForYears {
+		if unit, ok := l.parse(text, tokens, first.end+1); ok && unit.value >= 1 && unit.value <= 9 && !unit.multiword && l.adjacent(text, tokens, first.end, first.end+1) {
+			return number{start: i, end: unit.end, value: first.value*100 + unit.value}, true
+		}
+		return number{}, false
+	}
+	second, ok := l.parse(text, tokens, first.end)
+	if !ok || !second.pair {
+		return number{}, false
+	}
+	return number{start: i, end: second.end, value: first.value*100 + second.value}, true
+}
+
+// time writes a spoken time: "five thirty pm" as "5:30 PM", "пять часов
+// двадцать минут" as "5:20".
+func (l *lexicon) time(text string, tokens []token, hour number) (edit, int, bool) {
+	if hour.ordinal || hour.fraction != "" || hour.value > 23 {
+		return edit{}, 0, false
+	}
+	end := hour.end
+
+	// Hours and minutes named: "пять часов двадцать минут"
+	if end < len(tokens) && l.hourWords[tokens[end].lower] && l.adjacent(text, tokens, end-1, end) {
+		end++
+		minutes := int64(0)
+		if m, ok := l.parse(text, tokens, end); ok && !m.ordinal && m.value < 60 && m.end < len(tokens) &&
+			l.minuteWords[tokens[m.end].lower] && l.adjacent(text, tokens, end-1, end) && l.adjacent(text, tokens, m.end-1, m.end) {
+			minutes, end = m.value, m.end+1
+		}
+		written := strconv.FormatInt(hour.value, 10) + ":" + twoDigits(minutes)
+		return l.replace(tokens, hour.start, end, written), end, true
+	}
+
+	if l.meridiem == nil || hour.value < 1 || hour.value > 12 {
+		return edit{}, 0, false
+	}
+
+	// "five o'clock"
+	if end < len(tokens) && l.oClock[tokens[end].lower] && l.adjacent(text, tokens, end-1, end) {
+		written := strconv.FormatInt(hour.value, 10) + ":00"
+		return l.replace(tokens, hour.start, end+1, written), end + 1, true
+	}
+
+	// Optional minutes, then AM or PM: "five pm", "five thirty pm", "five oh five am"
+	written := strconv.FormatInt(hour.value, 10)
+	if end < len(tokens) && l.adjacent(text, tokens, end-1, end) {
+		if tokens[end].lower == l.zeroForYears {
+			if m, ok := l.parse(text, tokens, end+1); ok && m.value >= 1 && m.value <= 9 && !m.multiword && l.adjacent(text, tokens, end, end+1) {
+				written, end = written+":0"+strconv.FormatInt(m.value, 10), m.end
+			}
+		} else if m, ok := l.parse(text, tokens, end); ok && !m.ordinal && m.fraction == "" && m.value >= 10 && m.value < 60 {
+			written, end = written+":"+strconv.FormatInt(m.value, 10), m.end
+		}
+	}
+	loc := l.meridiem.FindStringSubmatchIndex(text[tokens[end-1].end:])
+	if loc == nil {
+		return edit{}, 0, false
+	}
+	written += " " + strings.ToUpper(text[tokens[end-1].end+loc[2]:tokens[end-1].end+loc[3]]) + "M"
+	stop := tokens[end-1].end + loc[1]
+	// The dot of "p.m." may also end the sentence
+	if rest := text[stop:]; strings.HasSuffix(text[:stop], ".") && (strings.TrimSpace(rest) == "" || startsSentence(rest)) {
+		written += "."
+	}
+	next := end
+	for next < len(tokens) && tokens[next].start < stop {
+		next++
+	}
+	return edit{tokens[hour.start].start, stop, written}, next, true
+}
+
+// withSuffix writes a number followed by a currency, percent or unit.
+func (l *lexicon) withSuffix(text string, tokens []token, n number) (edit, int, bool) {
+	if n.ordinal {
+		return edit{}, 0, false
+	}
+	for _, s := range l.suffixes {
+		for _, phrase := range s.words {
+			end, ok := l.phraseAt(text, tokens, n.end, phrase)
+			if !ok {
+				continue
+			}
+
+			amount := l.format(n)
+			if s.subs != nil && n.fraction == "" {
+				if cents, centsEnd, ok := l.subunitAt(text, tokens, end, s.subs); ok {
+					amount += l.decimalSep + twoDigits(cents)
+					end = centsEnd
+				}
+			}
+
+			var written string
+			switch {
+			case s.prefix:
+				written = s.symbol + amount
+			case s.space:
+				written = amount + " " + s.symbol
+			default:
+				written = amount + s.symbol
+			}
+			return l.replace(tokens, n.start, end, written), end, true
+		}
+	}
+	return edit{}, 0, false
+}
+
+// subunitAt reads "and twenty cents" or "двадцать копеек" at token i.
+func (l *lexicon) subunitAt(text string, tokens []token, i int, sub *subunit) (int64, int, bool) {
+	j := i
+	if j < len(tokens) && l.and[tokens[j].lower] && l.adjacent(text, tokens, j-1, j) {
+		j++
+	}
+	if !l.adjacent(text, tokens, j-1, j) {
+		return 0, 0, false
+	}
+	cents, ok := l.parse(text, tokens, j)
+	if !ok || cents.ordinal || cents.fraction != "" || cents.value >= 100 {
+		return 0, 0, false
+	}
+	for _, phrase := range sub.words {
+		if end, ok := l.phraseAt(text, tokens, cents.end, phrase); ok {
+			return cents.value, end, true
+		}
+	}
+	return 0, 0, false
+}
+
+// phraseAt matches a space-separated phrase at token i, returning the token after it.
+func (l *lexicon) phraseAt(text string, tokens []token, i int, phrase string) (int, bool) {
+	for _, word := range strings.Fields(phrase) {
+		if i >= len(tokens) || tokens[i].lower != word || !l.adjacent(text, tokens, i-1, i) {
+			return 0, false
+		}
+		i++
+	}
+	return i, true
+}
+
+// startsSentence reports whether text continues with whitespace and a capital letter.
+func startsSentence(text string) bool {
+	trimmed := strings.TrimLeftFunc(text, unicode.IsSpace)
+	if len(trimmed) == len(text) {
+		return false
+	}
+	for _, r := range trimmed {
+		return unicode.IsUpper(r)
+	}
+	return false
+}
+
+// isDigits reports whether s is a non-empty run of ASCII digits.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// twoDigits writes n with a leading zero below ten.
+func twoDigits(n int64) string {
+	if n < 10 {
+		return "0" + strconv.FormatInt(n, 10)
+	}
+	return strconv.FormatInt(n, 10)
+}
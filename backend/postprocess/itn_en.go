@@ -0,0 +1,102 @@
+package postprocess
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// english holds the spoken forms of English.
+var english = &lexicon{
+	numbers: englishNumbers(),
+	and:     map[string]bool{"and": true},
+	one:     map[string]bool{"a": true},
+	largeScales: map[string]bool{
+		"quadrillion": true, "quintillion": true, "sextillion": true, "septillion": true,
+	},
+	decimal: map[string]bool{"point": true},
+	months: map[string]string{
+		"january": "January", "february": "February", "march": "March", "april": "April",
+		"may": "May", "june": "June", "july": "July", "august": "August",
+		"september": "September", "october": "October", "november": "November", "december": "December",
+	},
+	monthFirst:  true,
+	vagueMonths: map[string]bool{"march": true, "may": true},
+	dateJoiner:  "of",
+	oClock:      map[string]bool{"o'clock": true, "o’clock": true},
+	suffixes: []suffix{
+		{words: []string{"dollars", "dollar", "bucks"}, symbol: "$", prefix: true, subs: &subunit{words: []string{"cents", "cent"}}},
+		{words: []string{"euros", "euro"}, symbol: "€", prefix: true, subs: &subunit{words: []string{"cents", "cent"}}},
+		{words: []string{"rubles", "ruble", "roubles", "rouble"}, symbol: "₽", space: true},
+		{words: []string{"percent", "per cent"}, symbol: "%"},
+		{words: []string{"kilometers per hour", "kilometres per hour"}, symbol: "km/h", space: true},
+		{words: []string{"miles per hour"}, symbol: "mph", space: true},
+		{words: []string{"degrees celsius", "degree celsius"}, symbol: "°C"},
+		{words: []string{"degrees fahrenheit", "degree fahrenheit"}, symbol: "°F"},
+		{words: []string{"degrees", "degree"}, symbol: "°"},
+		{words: []string{"kilometers", "kilometres", "kilometer", "kilometre"}, symbol: "km", space: true},
+		{words: []string{"centimeters", "centimetres", "centimeter", "centimetre"}, symbol: "cm", space: true},
+		{words: []string{"millimeters", "millimetres", "millimeter", "millimetre"}, symbol: "mm", space: true},
+		{words: []string{"meters", "metres", "meter", "metre"}, symbol: "m", space: true},
+		{words: []string{"kilograms", "kilogram", "kilos"}, symbol: "kg", space: true},
+		{words: []string{"grams", "gram"}, symbol: "g", space: true},
+		{words: []string{"milliliters", "millilitres", "milliliter", "millilitre"}, symbol: "mL", space: true},
+		{words: []string{"liters", "litres", "liter", "litre"}, symbol: "L", space: true},
+		{words: []string{"miles", "mile"}, symbol: "mi", space: true},
+		{words: []string{"feet", "foot"}, symbol: "ft", space: true},
+		{words: []string{"inches", "inch"}, symbol: "in", space: true},
+		{words: []string{"terabytes", "terabyte"}, symbol: "TB", space: true},
+		{words: []string{"gigabytes", "gigabyte"}, symbol: "GB", space: true},
+		{words: []string{"megabytes", "megabyte"}, symbol: "MB", space: true},
+		{words: []string{"kilobytes", "kilobyte"}, symbol: "KB", space: true},
+	},
+	decimalSep:   ".",
+	groupSep:     ",",
+	ordinalForm:  englishOrdinal,
+	spokenYears:  true,
+	zeroForYears: "oh",
+	meridiem:     regexp.MustCompile(`^\s*(?i)([ap])(?:\.\s?m\.|\s?m\b)`),
+}
+
+// englishNumbers lists English cardinal and ordinal number words.
+func englishNumbers() map[string]numberWord {
+	words := make(map[string]numberWord)
+	units := []string{"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten",
+		"eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen"}
+	unitOrdinals := []string{"zeroth", "first", "second", "third", "fourth", "fifth", "sixth", "seventh", "eighth", "ninth", "tenth",
+		"eleventh", "twelfth", "thirteenth", "fourteenth", "fifteenth", "sixteenth", "seventeenth", "eighteenth", "nineteenth"}
+	for i := range units {
+		words[units[i]] = numberWord{kind: kindUnit, value: int64(i)}
+		words[unitOrdinals[i]] = numberWord{kind: kindUnit, value: int64(i), ordinal: true}
+	}
+
+	tens := []string{"twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+	tenOrdinals := []string{"twentieth", "thirtieth", "fortieth", "fiftieth", "sixtieth", "seventieth", "eightieth", "ninetieth"}
+	for i := range tens {
+		words[tens[i]] = numberWord{kind: kindTen, value: int64(20 + 10*i)}
+		words[tenOrdinals[i]] = numberWord{kind: kindTen, value: int64(20 + 10*i), ordinal: true}
+	}
+
+	words["hundred"] = numberWord{kind: kindHundred, value: 100}
+	words["hundredth"] = numberWord{kind: kindHundred, value: 100, ordinal: true}
+	for name, value := range map[string]int64{"thousand": 1e3, "million": 1e6, "billion": 1e9, "trillion": 1e12} {
+		words[name] = numberWord{kind: kindScale, value: value}
+		words[name+"th"] = numberWord{kind: kindScale, value: value, ordinal: true}
+	}
+	return words
+}
+
+// englishOrdinal writes an ordinal with its suffix, e.g. "21st".
+func englishOrdinal(n int64) string {
+	suffix := "th"
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return strconv.FormatInt(n, 10) + suffix
+}
@@ -0,0 +1,133 @@
+package postprocess
+
+// russian holds the spoken forms of Russian. Numbers are recognized in the
+// cases they are most often dictated in.
+var russian = &lexicon{
+	numbers:    russianNumbers(),
+	bareScales: true,
+	largeScales: map[string]bool{
+		"квадриллион": true, "квадриллиона": true, "квадриллионов": true,
+		"квинтиллион": true, "квинтиллиона": true, "квинтиллионов": true,
+	},
+	decimal: map[string]bool{"запятая": true, "целых": true, "целая": true},
+	fractions: map[string]int{
+		"десятая": 1, "десятых": 1, "сотая": 2, "сотых": 2,
+		"тысячная": 3, "тысячных": 3, "десятитысячных": 4,
+	},
+	months: map[string]string{
+		"января": "января", "февраля": "февраля", "марта": "марта", "апреля": "апреля",
+		"мая": "мая", "июня": "июня", "июля": "июля", "августа": "августа",
+		"сентября": "сентября", "октября": "октября", "ноября": "ноября", "декабря": "декабря",
+	},
+	yearWords:   map[string]bool{"год": true, "года": true, "году": true, "годом": true},
+	hourWords:   map[string]bool{"час": true, "часа": true, "часов": true},
+	minuteWords: map[string]bool{"минута": true, "минуту": true, "минуты": true, "минут": true},
+	suffixes: []suffix{
+		{words: []string{"рублей", "рубля", "рубль"}, symbol: "₽", space: true, subs: &subunit{words: []string{"копеек", "копейки", "копейка", "копейку"}}},
+		{words: []string{"долларов", "доллара", "доллар"}, symbol: "$", prefix: true, subs: &subunit{words: []string{"центов", "цента", "цент"}}},
+		{words: []string{"евро"}, symbol: "€", space: true},
+		{words: []string{"процентов", "процента", "процент"}, symbol: "%"},
+		{words: []string{"километров в час", "километра в час", "километр в час"}, symbol: "км/ч", space: true},
+		{words: []string{"градусов цельсия", "градуса цельсия", "градус цельсия"}, symbol: "°C"},
+		{words: []string{"градусов", "градуса", "градус"}, symbol: "°"},
+		{words: []string{"километров", "километра", "километр"}, symbol: "км", space: true},
+		{words: []string{"сантиметров", "сантиметра", "сантиметр"}, symbol: "см", space: true},
+		{words: []string{"миллиметров", "миллиметра", "миллиметр"}, symbol: "мм", space: true},
+		{words: []string{"метров", "метра", "метр"}, symbol: "м", space: true},
+		{words: []string{"килограммов", "килограмма", "килограмм"}, symbol: "кг", space: true},
+		{words: []string{"граммов", "грамма", "грамм"}, symbol: "г", space: true},
+		{words: []string{"миллилитров", "миллилитра", "миллилитр"}, symbol: "мл", space: true},
+		{words: []string{"литров", "литра", "литр"}, symbol: "л", space: true},
+		{words: []string{"гигабайтов", "гигабайта", "гигабайт"}, symbol: "ГБ", space: true},
+		{words: []string{"мегабайтов", "мегабайта", "мегабайт"}, symbol: "МБ", space: true},
+	},
+	decimalSep: ",",
+	groupSep:   " ",
+}
+
+// russianOrdinalEndings are the adjective endings of ordinals.
+var russianOrdinalEndings = []string{"ый", "ой", "ий", "ая", "ое", "ого", "ому", "ом", "ую", "ые", "ых", "ым", "ыми"}
+
+// russianNumbers lists Russian cardinal and ordinal number words.
+func russianNumbers() map[string]numberWord {
+	words := make(map[string]numberWord)
+	cardinal := func(kind wordKind, value int64, forms ...string) {
+		for _, form := range forms {
+			words[form] = numberWord{kind: kind, value: value}
+		}
+	}
+	ordinal := func(kind wordKind, value int64, stems ...string) {
+		for _, stem := range stems {
+			for _, ending := range russianOrdinalEndings {
+				words[stem+ending] = numberWord{kind: kind, value: value, ordinal: true}
+			}
+		}
+	}
+
+	cardinal(kindUnit, 0, "ноль", "нуль", "ноля", "нуля")
+	cardinal(kindUnit, 1, "один", "одна", "одно", "одну", "одного", "одной", "одному", "одним")
+	cardinal(kindUnit, 2, "два", "две", "двух", "двум", "двумя")
+	cardinal(kindUnit, 3, "три", "трёх", "трех", "трём", "трем", "тремя")
+	cardinal(kindUnit, 4, "четыре", "четырёх", "четырех", "четырём", "четырем", "четырьмя")
+	ordinal(kindUnit, 1, "перв")
+	ordinal(kindUnit, 2, "втор")
+	ordinal(kindUnit, 4, "четвёрт", "четверт")
+	for _, form := range []string{"третий", "третья", "третье", "третьего", "третьему", "третьем", "третью", "третьи", "третьих"} {
+		words[form] = numberWord{kind: kindUnit, value: 3, ordinal: true}
+	}
+
+	// Five to twenty and thirty end in a soft sign, dropped for the oblique cases
+	soft := []struct {
+		kind    wordKind
+		value   int64
+		word    string
+		ordinal string
+	}{
+		{kindUnit, 5, "пять", "пят"}, {kindUnit, 6, "шесть", "шест"}, {kindUnit, 7, "семь", "седьм"},
+		{kindUnit, 8, "восемь", "восьм"}, {kindUnit, 9, "девять", "девят"}, {kindUnit, 10, "десять", "десят"},
+		{kindUnit, 11, "одиннадцать", "одиннадцат"}, {kindUnit, 12, "двенадцать", "двенадцат"},
+		{kindUnit, 13, "тринадцать", "тринадцат"}, {kindUnit, 14, "четырнадцать", "четырнадцат"},
+		{kindUnit, 15, "пятнадцать", "пятнадцат"}, {kindUnit, 16, "шестнадцать", "шестнадцат"},
+		{kindUnit, 17, "семнадцать", "семнадцат"}, {kindUnit, 18, "восемнадцать", "восемнадцат"},
+		{kindUnit, 19, "девятнадцать", "девятнадцат"}, {kindTen, 20, "двадцать", "двадцат"},
+		{kindTen, 30, "тридцать", "тридцат"},
+	}
+	for _, n := range soft {
+		stem := []rune(n.word)
+		stem = stem[:len(stem)-1]
+		cardinal(n.kind, n.value, n.word, string(stem)+"и", string(stem)+"ью")
+		ordinal(n.kind, n.value, n.ordinal)
+	}
+	cardinal(kindUnit, 8, "восьми", "восьмью")
+
+	cardinal(kindTen, 40, "сорок", "сорока")
+	cardinal(kindTen, 50, "пятьдесят", "пятидесяти")
+	cardinal(kindTen, 60, "шестьдесят", "шестидесяти")
+	cardinal(kindTen, 70, "семьдесят", "семидесяти")
+	cardinal(kindTen, 80, "восемьдесят", "восьмидесяти")
+	cardinal(kindTen, 90, "девяносто", "девяноста")
+	ordinal(kindTen, 40, "сороков")
+	ordinal(kindTen, 50, "пятидесят")
+	ordinal(kindTen, 60, "шестидесят")
+	ordinal(kindTen, 70, "семидесят")
+	ordinal(kindTen, 80, "восьмидесят")
+	ordinal(kindTen, 90, "девяност")
+
+	cardinal(kindHundreds, 100, "сто", "ста")
+	cardinal(kindHundreds, 200, "двести", "двухсот")
+	cardinal(kindHundreds, 300, "триста", "трёхсот", "трехсот")
+	cardinal(kindHundreds, 400, "четыреста", "четырёхсот", "четырехсот")
+	cardinal(kindHundreds, 500, "пятьсот", "пятисот")
+	cardinal(kindHundreds, 600, "шестьсот", "шестисот")
+	cardinal(kindHundreds, 700, "семьсот", "семисот")
+	cardinal(kindHundreds, 800, "восемьсот", "восьмисот")
+	cardinal(kindHundreds, 900, "девятьсот", "девятисот")
+	ordinal(kindHundreds, 100, "сот")
+
+	cardinal(kindScale, 1e3, "тысяча", "тысячи", "тысяч", "тысячу", "тысячей", "тысячам")
+	cardinal(kindScale, 1e6, "миллион", "миллиона", "миллионов")
+	cardinal(kindScale, 1e9, "миллиард", "миллиарда", "миллиардов")
+	cardinal(kindScale, 1e12, "триллион", "триллиона", "триллионов")
+	ordinal(kindScale, 1e3, "тысячн")
+	return words
+}
@@ -0,0 +1,55 @@
+package postprocess
+
+import "testing"
+
+func TestITN(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		text     string
+		want     string
+	}{
+		{"money, date and time", "en", "twenty five dollars on march third at five pm", "$25 on March 3 at 5 PM"},
+		{"cents", "en", "five dollars and twenty cents", "$5.20"},
+		{"percent", "en", "ninety nine percent", "99%"},
+		{"units", "en", "forty two kilometers", "42 km"},
+		{"decimal", "en", "three point one four", "3.14"},
+		{"large number", "en", "twelve thousand three hundred and five", "12,305"},
+		{"day of month", "en", "on the third of march", "on March 3"},
+		{"date with year", "en", "march third twenty twenty five", "March 3, 2025"},
+		{"ordinal", "en", "the twenty first floor", "the 21st floor"},
+		{"o'clock", "en", "at five o'clock", "at 5:00"},
+		{"time with minutes", "en", "five thirty p.m.", "5:30 PM."},
+		{"small numbers stay words", "en", "I have two cats", "I have two cats"},
+		{"vague month", "en", "you may go", "you may go"},
+		{"a thousand", "en", "a thousand times", "1000 times"},
+		{"a million", "en", "a million people", "1,000,000 people"},
+		{"a hundred dollars", "en", "a hundred and five dollars", "$105"},
+		{"lone scale word", "en", "the million dollar question", "the million dollar question"},
+		{"trillions", "en", "nine hundred ninety nine trillion nine hundred ninety nine billion", "999,999,000,000,000"},
+		{"repeated scale", "en", "a million million stars", "a million million stars"},
+		{"scale past trillion", "en", "nine hundred quadrillion five hundred trillion", "nine hundred quadrillion five hundred trillion"},
+		{"spoken year after in", "en", "in twenty twenty we moved", "in 2020 we moved"},
+		{"spoken year with oh", "en", "in twenty oh five", "in 2005"},
+		{"spoken year pair elsewhere", "en", "twenty twenty was a hard year", "twenty twenty was a hard year"},
+		{"time without meridiem", "en", "meet at ten fifteen", "meet at ten fifteen"},
+		{"time with meridiem", "en", "meet at ten fifteen pm", "meet at 10:15 PM"},
+		{"rubles", "ru", "двадцать пять рублей", "25 ₽"},
+		{"lone thousand", "ru", "тысяча рублей", "1000 ₽"},
+		{"russian trillion", "ru", "два триллиона рублей", "2 000 000 000 000 ₽"},
+		{"russian repeated scale", "ru", "тысяча тысяч", "тысяча тысяч"},
+		{"russian date", "ru", "третьего марта", "3 марта"},
+		{"russian year", "ru", "в две тысячи двадцать пятом году", "в 2025 году"},
+		{"russian time", "ru", "в пять часов двадцать минут", "в 5:20"},
+		{"russian decimal", "ru", "три целых пять сотых", "3,05"},
+		{"unknown language runs both", "", "twenty five dollars и двадцать пять рублей", "$25 и 25 ₽"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (itn{}).Apply(tt.text, tt.language); got != tt.want {
+				t.Errorf("Apply(%q, %q) = %q, want %q", tt.text, tt.language, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+// Package postprocess runs transcripts through an ordered pipeline of text
+// steps after transcription: cleanup, find-and-replace dictionaries, filler
+// removal, profanity masking, casing rules, inverse text normalization and
+// dictation commands.
+package postprocess
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Step is one text transformation of a pipeline.
+type Step interface {
+	// Name identifies the step in specs and in the applied steps of a run.
+	Name() string
+	// Apply returns the transformed text. Language is the transcript's language
+	// code, empty when unknown; language-specific steps then use all their rules.
+	Apply(text, language string) string
+}
+
+// Pipeline is an ordered list of steps. The zero value does nothing.
+type Pipeline struct {
+	steps []Step
+}
+
+// Applied records the outcome of one step of a run.
+type Applied struct {
+	Step    string `json:"step" example:"fillers"`
+	Changed bool   `json:"changed" example:"true"`
+	Text    string `json:"text,omitempty" example:"So I think we should ship it"` // Text after the step, if it changed
+}
+
+// New creates a pipeline running the steps in order.
+func New(steps ...Step) Pipeline {
+	return Pipeline{steps: steps}
+}
+
+// Enabled reports whether the pipeline has any steps.
+func (p Pipeline) Enabled() bool {
+	return len(p.steps) > 0
+}
+
+// Run applies every step in order and records what each one did.
+func (p Pipeline) Run(text, language string) (string, []Applied) {
+	applied := make([]Applied, 0, len(p.steps))
+	for _, step := range p.steps {
+		out := step.Apply(text, language)
+		a := Applied{Step: step.Name(), Changed: out != text}
+		if a.Changed {
+			a.Text = out
+		}
+		applied = append(applied, a)
+		text = out
+	}
+	return text, applied
+}
+
+// StepSpec configures a step in JSON. Only the options of the named step are used.
+type StepSpec struct {
+	Step          string            `json:"step"`
+	Pairs         map[string]string `json:"pairs,omitempty"`          // replace: spoken or misspelled form to its replacement
+	CaseSensitive bool              `json:"case_sensitive,omitempty"` // replace: match case exactly
+	Words         []string          `json:"words,omitempty"`          // fillers, profanity: extra words on top of the built-in lists
+	Mask          string            `json:"mask,omitempty"`           // profanity: mask character, "*" by default
+	Mode          string            `json:"mode,omitempty"`           // case: "sentence", "lower" or "upper"
+	Commands      map[string]string `json:"commands,omitempty"`       // dictation: extra command phrases and their actions
+}
+
+// builders create steps from their specs, by step name.
+var builders = map[string]func(StepSpec) (Step, error){
+	"cleanup":   newCleanup,
+	"replace":   newReplace,
+	"fillers":   newFillers,
+	"profanity": newProfanity,
+	"case":      newCasing,
+	"itn":       newITN,
+	"dictation": newDictation,
+}
+
+// Parse reads a pipeline from a comma-separated list of steps, for example
+// "cleanup,fillers,profanity=#,case=sentence", or from JSON: an array of step
+// names and StepSpec objects, or a string holding a comma-separated list.
+// "none" or an empty spec is the empty pipeline.
+func Parse(spec string) (Pipeline, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "[") || strings.HasPrefix(spec, `"`) || spec == "null" {
+		return ParseJSON(json.RawMessage(spec))
+	}
+
+	var steps []Step
+	for _, part := range strings.Split(spec, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		stepSpec := StepSpec{Step: strings.ToLower(name)}
+		switch stepSpec.Step {
+		case "", "none":
+			continue
+		case "case":
+			stepSpec.Mode = value
+		case "profanity":
+			stepSpec.Mask = value
+		default:
+			if value != "" {
+				return Pipeline{}, fmt.Errorf("step %q takes no value in a list, configure it as JSON", name)
+			}
+		}
+		step, err := build(stepSpec)
+		if err != nil {
+			return Pipeline{}, err
+		}
+		steps = append(steps, step)
+	}
+	return New(steps...), nil
+}
+
+// ParseJSON reads a pipeline from a JSON array of step names and StepSpec
+// objects, or from a JSON string holding a comma-separated list.
+func ParseJSON(raw json.RawMessage) (Pipeline, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return Pipeline{}, nil
+	}
+
+	var list string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return Parse(list)
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return Pipeline{}, fmt.Errorf("postprocess must be a list of steps: %w", err)
+	}
+
+	steps := make([]Step, 0, len(items))
+	for i, item := range items {
+		var stepSpec StepSpec
+		if err := json.Unmarshal(item, &stepSpec.Step); err != nil {
+			if err := json.Unmarshal(item, &stepSpec); err != nil {
+				return Pipeline{}, fmt.Errorf("step %d must be a name or an object with a \"step\" name: %w", i+1, err)
+			}
+		}
+		stepSpec.Step = strings.ToLower(strings.TrimSpace(stepSpec.Step))
+		step, err := build(stepSpec)
+		if err != nil {
+			return Pipeline{}, fmt.Errorf("step %d: %w", i+1, err)
+		}
+		steps = append(steps, step)
+	}
+	return New(steps...), nil
+}
+
+// build creates the step a spec names.
+func build(spec StepSpec) (Step, error) {
+	builder, ok := builders[spec.Step]
+	if !ok {
+		return nil, fmt.Errorf("unknown postprocessing step: %q", spec.Step)
+	}
+	return builder(spec)
+}
@@ -0,0 +1,268 @@
+package postprocess
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// cleanup normalizes whitespace and the spacing around punctuation.
+type cleanup struct{}
+
+func newCleanup(StepSpec) (Step, error) { return cleanup{}, nil }
+
+func (cleanup) Name() string { return "cleanup" }
+
+var (
+	cleanupSpaces        = regexp.MustCompile(`[ \t\p{Zs}]+`)
+	cleanupLineEdges     = regexp.MustCompile(` *\n *`)
+	cleanupBlankLines    = regexp.MustCompile(`\n{3,}`)
+	cleanupSpaceBefore   = regexp.MustCompile(` +([,.;:!?…%)\]}»])`)
+	cleanupSpaceAfter    = regexp.MustCompile(`([(\[{«]) +`)
+	cleanupMissingSpace  = regexp.MustCompile(`([,;!?])(\pL)`)
+	cleanupRepeatedComma = regexp.MustCompile(`,[ ,]*,`)
+	cleanupDoubleDot     = regexp.MustCompile(`(^|[^.])\.\.([^.]|$)`)
+)
+
+func (cleanup) Apply(text, _ string) string {
+	text = cleanupSpaces.ReplaceAllString(text, " ")
+	text = cleanupLineEdges.ReplaceAllString(text, "\n")
+	text = cleanupBlankLines.ReplaceAllString(text, "\n\n")
+	text = cleanupSpaceBefore.ReplaceAllString(text, "$1")
+	text = cleanupSpaceAfter.ReplaceAllString(text, "$1")
+	text = cleanupMissingSpace.ReplaceAllString(text, "$1 $2")
+	text = cleanupRepeatedComma.ReplaceAllString(text, ",")
+	text = cleanupDoubleDot.ReplaceAllString(text, "$1.$2")
+	return strings.TrimSpace(text)
+}
+
+// replace applies a find-and-replace dictionary to whole words and phrases.
+type replace struct {
+	re            *regexp.Regexp
+	pairs         map[string]string // Keyed by lookupKey of the spoken form
+	caseSensitive bool
+}
+
+func newReplace(spec StepSpec) (Step, error) {
+	if len(spec.Pairs) == 0 {
+		return nil, fmt.Errorf("replace needs a \"pairs\" dictionary")
+	}
+
+	r := &replace{pairs: make(map[string]string, len(spec.Pairs)), caseSensitive: spec.CaseSensitive}
+	var alternatives []string
+	for from, to := range spec.Pairs {
+		if strings.TrimSpace(from) == "" {
+			return nil, fmt.Errorf("replace pairs can't have an empty key")
+		}
+		r.pairs[r.lookupKey(from)] = to
+		alternatives = append(alternatives, literalPattern(from))
+	}
+	// Sorted so equally long matches always resolve the same way
+	slices.SortFunc(alternatives, func(a, b string) int {
+		return cmp.Or(cmp.Compare(len(b), len(a)), cmp.Compare(a, b))
+	})
+
+	pattern := "(?:" + strings.Join(alternatives, "|") + ")"
+	if !r.caseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	r.re = regexp.MustCompile(pattern)
+	r.re.Longest()
+	return r, nil
+}
+
+func (*replace) Name() string { return "replace" }
+
+func (r *replace) Apply(text, _ string) string {
+	return replaceWholeWords(text, r.re, func(match string) string {
+		if to, ok := r.pairs[r.lookupKey(match)]; ok {
+			return to
+		}
+		return match
+	})
+}
+
+// lookupKey normalizes a spoken form for the dictionary lookup.
+func (r *replace) lookupKey(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if !r.caseSensitive {
+		s = strings.ToLower(s)
+	}
+	return s
+}
+
+// fillerPatterns are hesitation sounds per language, as regular expressions.
+var fillerPatterns = map[string][]string{
+	"en": {`u+[hm]+`, `er`, `e+rm+`, `h+m+`, `m{2,}`},
+	"ru": {`э+м*`, `м{2,}`, `х+м+`, `кхм`},
+}
+
+// fillers removes filler words along with the comma or ellipsis after them.
+type fillers struct {
+	patterns map[string]*regexp.Regexp // By language, "" for unknown languages
+}
+
+func newFillers(spec StepSpec) (Step, error) {
+	return &fillers{patterns: languagePatterns(fillerPatterns, spec.Words)}, nil
+}
+
+func (*fillers) Name() string { return "fillers" }
+
+func (f *fillers) Apply(text, language string) string {
+	re := patternFor(f.patterns, language)
+
+	var sb strings.Builder
+	last := 0
+	for _, m := range wholeWordMatches(text, re) {
+		start, end := m[0], m[1]
+		if start < last {
+			continue
+		}
+		// Take the filler's own punctuation and the space after it. A filler
+		// that is a sentence of its own takes its terminator too.
+		own := ",…"
+		if r, _ := utf8.DecodeLastRuneInString(strings.TrimRight(text[:start], " ")); r == utf8.RuneError || strings.ContainsRune(".!?…\n", r) {
+			own = ",….!?"
+		}
+		end += len(text[end:]) - len(strings.TrimLeft(text[end:], own))
+		rest := strings.TrimLeft(text[end:], " ")
+		end = len(text) - len(rest)
+		// Before punctuation or at the end, the space before the filler goes instead
+		if r, _ := utf8.DecodeRuneInString(rest); rest == "" || unicode.IsPunct(r) {
+			start = last + len(strings.TrimRight(text[last:start], " "))
+		}
+		sb.WriteString(text[last:start])
+		last = end
+	}
+	if last == 0 {
+		return text
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
+
+// profanityPatterns are swear word stems per language, as regular expressions.
+var profanityPatterns = map[string][]string{
+	"en": {`(?:mother)?fuck\pL*`, `shit\pL*`, `bitch\pL*`, `asshole\pL*`, `cunt\pL*`, `bastard\pL*`, `dickhead\pL*`},
+	"ru": {`бля(?:д\pL*|ть)?`, `ху[йеёяи]\pL*`, `пизд\pL*`, `[её]ба\pL*`, `[её]бл\pL*`, `муда\pL*`, `сук[аи]`},
+}
+
+// profanity masks swear words, keeping their first letter.
+type profanity struct {
+	patterns map[string]*regexp.Regexp
+	mask     string
+}
+
+func newProfanity(spec StepSpec) (Step, error) {
+	mask := spec.Mask
+	if mask == "" {
+		mask = "*"
+	}
+	if utf8.RuneCountInString(mask) != 1 {
+		return nil, fmt.Errorf("profanity mask must be a single character, got %q", mask)
+	}
+	return &profanity{patterns: languagePatterns(profanityPatterns, spec.Words), mask: mask}, nil
+}
+
+func (*profanity) Name() string { return "profanity" }
+
+func (p *profanity) Apply(text, language string) string {
+	return replaceWholeWords(text, patternFor(p.patterns, language), func(match string) string {
+		first, size := utf8.DecodeRuneInString(match)
+		return string(first) + strings.Repeat(p.mask, utf8.RuneCountInString(match[size:]))
+	})
+}
+
+// languagePatterns compiles a pattern per language, plus one for unknown
+// languages matching every language's words. Extra words apply to all of them.
+func languagePatterns(table map[string][]string, extra []string) map[string]*regexp.Regexp {
+	var literals []string
+	for _, word := range extra {
+		if strings.TrimSpace(word) != "" {
+			literals = append(literals, literalPattern(word))
+		}
+	}
+
+	patterns := map[string]*regexp.Regexp{"": wordPattern(append(forLanguage(table, ""), literals...))}
+	for lang, alternatives := range table {
+		patterns[lang] = wordPattern(append(slices.Clone(alternatives), literals...))
+	}
+	return patterns
+}
+
+// patternFor picks the pattern of a language, falling back to the one for unknown languages.
+func patternFor(patterns map[string]*regexp.Regexp, language string) *regexp.Regexp {
+	if re, ok := patterns[baseLanguage(language)]; ok {
+		return re
+	}
+	return patterns[""]
+}
+
+// Casing modes.
+const (
+	caseSentence = "sentence" // Capitalize the first letter of each sentence
+	caseLower    = "lower"
+	caseUpper    = "upper"
+)
+
+// casing applies a casing rule.
+type casing struct {
+	mode string
+}
+
+func newCasing(spec StepSpec) (Step, error) {
+	switch spec.Mode {
+	case "":
+		return casing{mode: caseSentence}, nil
+	case caseSentence, caseLower, caseUpper:
+		return casing{mode: spec.Mode}, nil
+	}
+	return nil, fmt.Errorf("invalid case mode: %q, valid options: sentence, lower, upper", spec.Mode)
+}
+
+func (casing) Name() string { return "case" }
+
+// englishI matches the pronoun "i" and its contractions.
+var englishI = regexp.MustCompile(`\bi\b('(?:m|d|ll|ve))?`)
+
+func (c casing) Apply(text, language string) string {
+	switch c.mode {
+	case caseLower:
+		return strings.ToLower(text)
+	case caseUpper:
+		return strings.ToUpper(text)
+	}
+
+	if lang := baseLanguage(language); lang == "en" || lang == "" {
+		text = englishI.ReplaceAllStringFunc(text, func(m string) string { return "I" + m[1:] })
+	}
+
+	// A sentence starts after a terminator and whitespace, so "3.5" and
+	// "example.com" stay as they are
+	runes := []rune(text)
+	capitalize, ended := true, false
+	for i, r := range runes {
+		switch {
+		case r == '\n':
+			capitalize = true
+		case r == '.' || r == '!' || r == '?' || r == '…':
+			ended = true
+			continue
+		case unicode.IsSpace(r):
+			capitalize = capitalize || ended
+		case unicode.IsLetter(r):
+			if capitalize {
+				runes[i] = unicode.ToUpper(r)
+			}
+			capitalize = false
+		case unicode.IsDigit(r):
+			capitalize = false
+		}
+		ended = false
+	}
+	return string(runes)
+}
@@ -0,0 +1,98 @@
+package postprocess
+
+import (
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// baseLanguage reduces a language code to its ISO-639-1 base, e.g. "en-US"
+// and "eng" to "en". Unknown three-letter codes are returned lowercased.
+func baseLanguage(code string) string {
+	code = strings.ToLower(code)
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+	switch code {
+	case "eng":
+		return "en"
+	case "rus":
+		return "ru"
+	}
+	return code
+}
+
+// forLanguage returns the entries of a per-language table for a language,
+// or all of them when the language is unknown or has no entry.
+func forLanguage[T any](table map[string][]T, language string) []T {
+	if entries, ok := table[baseLanguage(language)]; ok {
+		return entries
+	}
+	var all []T
+	for _, lang := range slices.Sorted(maps.Keys(table)) {
+		all = append(all, table[lang]...)
+	}
+	return all
+}
+
+// wordPattern compiles alternatives into a case-insensitive pattern that
+// prefers the longest match. Alternatives are regular expressions.
+func wordPattern(alternatives []string) *regexp.Regexp {
+	re := regexp.MustCompile("(?i)(?:" + strings.Join(alternatives, "|") + ")")
+	re.Longest()
+	return re
+}
+
+// literalPattern turns a word or phrase into a pattern that matches it with
+// any whitespace between its words.
+func literalPattern(phrase string) string {
+	words := strings.Fields(phrase)
+	for i, w := range words {
+		words[i] = regexp.QuoteMeta(w)
+	}
+	return strings.Join(words, `\s+`)
+}
+
+// wholeWordMatches finds the matches of re that begin and end at word
+// boundaries, so a pattern for "um" doesn't match inside "umbrella".
+func wholeWordMatches(text string, re *regexp.Regexp) [][]int {
+	var matches [][]int
+	for _, m := range re.FindAllStringIndex(text, -1) {
+		if m[0] == m[1] {
+			continue
+		}
+		before, _ := utf8.DecodeLastRuneInString(text[:m[0]])
+		after, _ := utf8.DecodeRuneInString(text[m[1]:])
+		if isWordRune(before) || isWordRune(after) {
+			continue
+		}
+		matches = append(matches, m)
+	}
+	return matches
+}
+
+// replaceWholeWords replaces the whole-word matches of re with repl of the match.
+func replaceWholeWords(text string, re *regexp.Regexp, repl func(match string) string) string {
+	matches := wholeWordMatches(text, re)
+	if len(matches) == 0 {
+		return text
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		sb.WriteString(text[last:m[0]])
+		sb.WriteString(repl(text[m[0]:m[1]]))
+		last = m[1]
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
+
+// isWordRune reports whether r is part of a word.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
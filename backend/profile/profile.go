@@ -0,0 +1,144 @@
+// Package profile resolves the transcription profile of an app: the defaults
+// /speak applies to the app's requests when they leave an option out.
+package profile
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"silence-backend/audio"
+	"silence-backend/logger"
+	"silence-backend/postprocess"
+	"silence-backend/transcription"
+	"silence-backend/vocabulary"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Output formats of /speak.
+const (
+	OutputJSON = "json" // Full JSON response (default)
+	OutputText = "text" // Transcript only, as text/plain
+)
+
+// Profile is an app's transcription defaults. Empty fields fall back to the
+// server defaults; request parameters always take precedence.
+type Profile struct {
+	Provider         transcription.ProviderName `json:"provider,omitempty"`          // Empty uses the default provider chain
+	Language         string                     `json:"language,omitempty"`          // Empty or "auto" detects the language
+	AllowedLanguages []string                   `json:"allowed_languages,omitempty"` // Languages requests may ask for, empty allows any
+	Vocabulary       []string                   `json:"vocabulary,omitempty"`        // Names and jargon to bias transcription towards
+	Preprocess       string                     `json:"preprocess,omitempty"`        // Audio preprocessing chain, empty for the server default
+	Postprocess      json.RawMessage            `json:"postprocess,omitempty"`       // Text processing steps applied after transcription
+	OutputFormat     string                     `json:"output_format,omitempty"`     // OutputJSON or OutputText
+	DiscardAudio     bool                       `json:"discard_audio,omitempty"`     // Keep only the text of transcripts
+}
+
+// FromApp reads the profile of an app record. A nil record, as for requests
+// made with a user token, has the empty profile.
+func FromApp(record *core.Record) Profile {
+	if record == nil {
+		return Profile{}
+	}
+
+	p := Profile{
+		Provider:     transcription.ProviderName(record.GetString("default_provider")),
+		Language:     record.GetString("default_language"),
+		Preprocess:   record.GetString("preprocess"),
+		OutputFormat: record.GetString("output_format"),
+		DiscardAudio: record.GetBool("discard_audio"),
+	}
+	var err error
+	if p.AllowedLanguages, err = stringList(record, "allowed_languages"); err != nil {
+		logger.Error("Invalid allowed_languages in app profile", "app", record.Id, "error", err)
+	}
+	if p.Vocabulary, err = stringList(record, "vocabulary"); err != nil {
+		logger.Error("Invalid vocabulary in app profile", "app", record.Id, "error", err)
+	}
+	p.Vocabulary = vocabulary.Merge(p.Vocabulary)
+	if raw := strings.TrimSpace(record.GetString("postprocess")); raw != "" && raw != "null" {
+		p.Postprocess = json.RawMessage(raw)
+	}
+	return p
+}
+
+// stringList reads a JSON field holding a list of strings; an empty field is an empty list.
+func stringList(record *core.Record, field string) ([]string, error) {
+	raw := strings.TrimSpace(record.GetString(field))
+	if raw == "" || raw == "null" {
+		return nil, nil
+	}
+	var list []string
+	if err := json.Unmarshal([]byte(raw), &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Load reads the profile of the app with the given ID. An empty ID or a
+// missing app has the empty profile.
+func Load(app core.App, appID string) Profile {
+	if appID == "" {
+		return Profile{}
+	}
+	record, err := app.FindRecordById("apps", appID)
+	if err != nil {
+		logger.Error("Failed to load app profile", "app", appID, "error", err)
+		return Profile{}
+	}
+	return FromApp(record)
+}
+
+// AllowsLanguage reports whether requests may ask for the language code.
+// Auto-detection is always allowed.
+func (p Profile) AllowsLanguage(code string) bool {
+	if len(p.AllowedLanguages) == 0 || code == "" || code == "auto" {
+		return true
+	}
+	return slices.ContainsFunc(p.AllowedLanguages, func(allowed string) bool {
+		return strings.EqualFold(allowed, code)
+	})
+}
+
+// Validate checks the fields that the collection schema can't. Errors are
+// keyed by the apps field they concern.
+func (p Profile) Validate() error {
+	errs := validation.Errors{}
+	if p.Preprocess != "" {
+		if _, err := audio.ParsePreprocessConfig(p.Preprocess); err != nil {
+			errs["preprocess"] = validation.NewError("validation_invalid_preprocess", err.Error())
+		}
+	}
+	if _, err := postprocess.ParseJSON(p.Postprocess); err != nil {
+		errs["postprocess"] = validation.NewError("validation_invalid_postprocess", err.Error())
+	}
+	if !p.AllowsLanguage(p.Language) {
+		errs["default_language"] = validation.NewError("validation_language_not_allowed", "must be one of allowed_languages")
+	}
+	return errs.Filter()
+}
+
+// RegisterValidation rejects apps whose profile can't be applied, so mistakes
+// surface when the app is saved instead of on every request.
+func RegisterValidation(app core.App) {
+	app.OnRecordValidate("apps").BindFunc(func(e *core.RecordEvent) error {
+		errs := validation.Errors{}
+		if _, err := stringList(e.Record, "allowed_languages"); err != nil {
+			errs["allowed_languages"] = validation.NewError("validation_invalid_list", "must be a list of language codes")
+		}
+		if terms, err := stringList(e.Record, "vocabulary"); err != nil {
+			errs["vocabulary"] = validation.NewError("validation_invalid_list", "must be a list of words or phrases")
+		} else if err := vocabulary.Validate(vocabulary.Merge(terms)); err != nil {
+			errs["vocabulary"] = validation.NewError("validation_invalid_vocabulary", err.Error())
+		}
+		if err := errs.Filter(); err != nil {
+			return err
+		}
+		if err := FromApp(e.Record).Validate(); err != nil {
+			return err
+		}
+		return e.Next()
+	})
+}
@@ -0,0 +1,230 @@
+// Package queue runs background jobs on a fixed pool of workers with a bounded
+// backlog, retrying failures with exponential backoff.
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"silence-backend/logger"
+)
+
+var (
+	// ErrFull is returned by Enqueue when the backlog is at capacity.
+	ErrFull = errors.New("queue is full")
+	// ErrClosed is returned by Enqueue after Shutdown has been called.
+	ErrClosed = errors.New("queue is shut down")
+)
+
+// Job is a unit of background work.
+type Job interface {
+	// Name identifies the job in logs.
+	Name() string
+	// Run does the work. Returning an error schedules a retry.
+	Run(ctx context.Context) error
+	// Fail is called once when the job won't be retried any more, either because
+	// it ran out of attempts or because the queue shut down before it could finish.
+	Fail(attempts int, err error)
+}
+
+// Config controls worker count, backlog size and retries.
+type Config struct {
+	Workers     int           // Jobs processed concurrently
+	Capacity    int           // Jobs waiting for a worker before Enqueue fails
+	MaxAttempts int           // Runs per job, including the first
+	Backoff     time.Duration // Delay before the first retry, doubled on each further retry
+	MaxBackoff  time.Duration // Upper bound for the retry delay
+}
+
+// Stats is a snapshot of the queue for monitoring.
+type Stats struct {
+	Depth     int   `json:"depth" example:"3"`      // Jobs waiting for a worker
+	Capacity  int   `json:"capacity" example:"100"` // Maximum depth
+	Workers   int   `json:"workers" example:"2"`
+	Active    int64 `json:"active" example:"1"`       // Jobs being run or waiting for a retry
+	Enqueued  int64 `json:"enqueued" example:"1250"`  // Jobs accepted since start
+	Rejected  int64 `json:"rejected" example:"0"`     // Jobs refused because the queue was full or shut down
+	Completed int64 `json:"completed" example:"1244"` // Jobs that succeeded
+	Retries   int64 `json:"retries" example:"7"`      // Extra runs after failures
+	Failed    int64 `json:"failed" example:"2"`       // Jobs handed to Fail
+	Closed    bool  `json:"closed" example:"false"`   // Shutdown has started
+}
+
+// Queue is a bounded in-memory job queue.
+type Queue struct {
+	config Config
+	jobs   chan Job
+
+	// ctx is cancelled when a shutdown runs out of time; in-flight jobs are then failed
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.RWMutex
+	closed  bool
+	workers sync.WaitGroup
+
+	active    atomic.Int64
+	enqueued  atomic.Int64
+	rejected  atomic.Int64
+	completed atomic.Int64
+	retries   atomic.Int64
+	failed    atomic.Int64
+}
+
+// New creates a queue and starts its workers.
+func New(config Config) *Queue {
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+	if config.Capacity < 0 {
+		config.Capacity = 0
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 1
+	}
+	if config.MaxBackoff < config.Backoff {
+		config.MaxBackoff = config.Backoff
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &Queue{
+		config: config,
+		jobs:   make(chan Job, config.Capacity),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	for i := 0; i < config.Workers; i++ {
+		q.workers.Add(1)
+		go q.work()
+	}
+
+	return q
+}
+
+// Enqueue adds a job without blocking. It returns ErrFull when the backlog is
+// at capacity and ErrClosed after Shutdown; the job is not run in either case.
+func (q *Queue) Enqueue(job Job) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		q.rejected.Add(1)
+		return ErrClosed
+	}
+
+	select {
+	case q.jobs <- job:
+		q.enqueued.Add(1)
+		return nil
+	default:
+		q.rejected.Add(1)
+		return ErrFull
+	}
+}
+
+// Shutdown stops accepting jobs and waits for the backlog to drain. If ctx
+// expires first, running jobs are cancelled and every unfinished job is
+// handed to Fail, so nothing is dropped silently.
+func (q *Queue) Shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// Stats returns the current queue counters.
+func (q *Queue) Stats() Stats {
+	q.mu.RLock()
+	closed := q.closed
+	q.mu.RUnlock()
+
+	return Stats{
+		Depth:     len(q.jobs),
+		Capacity:  q.config.Capacity,
+		Workers:   q.config.Workers,
+		Active:    q.active.Load(),
+		Enqueued:  q.enqueued.Load(),
+		Rejected:  q.rejected.Load(),
+		Completed: q.completed.Load(),
+		Retries:   q.retries.Load(),
+		Failed:    q.failed.Load(),
+		Closed:    closed,
+	}
+}
+
+func (q *Queue) work() {
+	defer q.workers.Done()
+
+	for job := range q.jobs {
+		q.active.Add(1)
+		q.process(job)
+		q.active.Add(-1)
+	}
+}
+
+// process runs a job until it succeeds, runs out of attempts or the queue is cancelled.
+func (q *Queue) process(job Job) {
+	var err error
+	attempts := 0
+
+	for attempts < q.config.MaxAttempts {
+		if ctxErr := q.ctx.Err(); ctxErr != nil {
+			err = errors.Join(err, ctxErr)
+			break
+		}
+
+		attempts++
+		if err = job.Run(q.ctx); err == nil {
+			q.completed.Add(1)
+			return
+		}
+
+		if attempts == q.config.MaxAttempts {
+			break
+		}
+
+		delay := q.backoff(attempts)
+		logger.Error("Background job failed, retrying", "job", job.Name(), "attempt", attempts, "retry_in", delay.String(), "error", err)
+
+		select {
+		case <-time.After(delay):
+			q.retries.Add(1)
+		case <-q.ctx.Done():
+		}
+	}
+
+	q.failed.Add(1)
+	logger.Error("Background job failed permanently", "job", job.Name(), "attempts", attempts, "error", err)
+	job.Fail(attempts, err)
+}
+
+// backoff is the delay after the given number of failed attempts.
+func (q *Queue) backoff(attempts int) time.Duration {
+	delay := q.config.Backoff
+	for i := 1; i < attempts && delay < q.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, q.config.MaxBackoff)
+}
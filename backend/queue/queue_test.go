@@ -0,0 +1,212 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"silence-backend/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.Init()
+	os.Exit(m.Run())
+}
+
+// testJob fails its first failures runs, or blocks until released or cancelled.
+type testJob struct {
+	failures int
+	release  chan struct{} // Block each run until closed, if set
+	started  chan struct{} // Closed when the first run starts, if set
+
+	mu       sync.Mutex
+	runs     int
+	failed   int
+	attempts int
+	err      error
+	done     chan struct{}
+}
+
+func newTestJob(failures int) *testJob {
+	return &testJob{failures: failures, done: make(chan struct{})}
+}
+
+func (j *testJob) Name() string { return "test" }
+
+func (j *testJob) Run(ctx context.Context) error {
+	j.mu.Lock()
+	j.runs++
+	runs := j.runs
+	j.mu.Unlock()
+
+	if j.started != nil && runs == 1 {
+		close(j.started)
+	}
+	if j.release != nil {
+		select {
+		case <-j.release:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if runs <= j.failures {
+		return errors.New("transient")
+	}
+	close(j.done)
+	return nil
+}
+
+func (j *testJob) Fail(attempts int, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.failed++
+	j.attempts, j.err = attempts, err
+	close(j.done)
+}
+
+func (j *testJob) wait(t *testing.T) {
+	t.Helper()
+	select {
+	case <-j.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job didn't finish")
+	}
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		failures   int
+		wantRuns   int
+		wantFailed bool
+	}{
+		{"succeeds first time", 0, 1, false},
+		{"succeeds after retries", 2, 3, false},
+		{"runs out of attempts", 5, 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := New(Config{Workers: 1, Capacity: 1, MaxAttempts: 3, Backoff: time.Millisecond})
+			job := newTestJob(tt.failures)
+			if err := q.Enqueue(job); err != nil {
+				t.Fatal(err)
+			}
+			job.wait(t)
+			if err := q.Shutdown(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			if job.runs != tt.wantRuns {
+				t.Errorf("runs = %d, want %d", job.runs, tt.wantRuns)
+			}
+			if got := job.failed == 1; got != tt.wantFailed {
+				t.Errorf("failed = %d, want failure %v", job.failed, tt.wantFailed)
+			}
+			if tt.wantFailed && job.attempts != 3 {
+				t.Errorf("Fail got %d attempts, want 3", job.attempts)
+			}
+
+			stats := q.Stats()
+			if stats.Retries != int64(tt.wantRuns-1) {
+				t.Errorf("Retries = %d, want %d", stats.Retries, tt.wantRuns-1)
+			}
+			if stats.Completed+stats.Failed != 1 || stats.Active != 0 {
+				t.Errorf("stats = %+v", stats)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	q := &Queue{config: Config{Backoff: time.Second, MaxBackoff: 5 * time.Second}}
+	for attempts, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 10: 5 * time.Second} {
+		if got := q.backoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
+
+func TestEnqueueRejects(t *testing.T) {
+	q := New(Config{Workers: 1, Capacity: 1, MaxAttempts: 1})
+
+	// Occupy the worker, then fill the backlog
+	busy := newTestJob(0)
+	busy.release, busy.started = make(chan struct{}), make(chan struct{})
+	if err := q.Enqueue(busy); err != nil {
+		t.Fatal(err)
+	}
+	<-busy.started
+	waiting := newTestJob(0)
+	if err := q.Enqueue(waiting); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue(newTestJob(0)); !errors.Is(err, ErrFull) {
+		t.Errorf("Enqueue on a full queue = %v, want ErrFull", err)
+	}
+	if depth := q.Stats().Depth; depth != 1 {
+		t.Errorf("Depth = %d, want 1", depth)
+	}
+
+	close(busy.release)
+	if err := q.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	waiting.wait(t)
+	if err := q.Enqueue(newTestJob(0)); !errors.Is(err, ErrClosed) {
+		t.Errorf("Enqueue after Shutdown = %v, want ErrClosed", err)
+	}
+	if stats := q.Stats(); stats.Rejected != 2 || stats.Completed != 2 || !stats.Closed {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestShutdownDrainsBacklog(t *testing.T) {
+	q := New(Config{Workers: 2, Capacity: 10, MaxAttempts: 1})
+	jobs := make([]*testJob, 10)
+	for i := range jobs {
+		jobs[i] = newTestJob(0)
+		if err := q.Enqueue(jobs[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for i, job := range jobs {
+		if job.runs != 1 {
+			t.Errorf("job %d ran %d times, want 1", i, job.runs)
+		}
+	}
+}
+
+func TestShutdownTimeoutFailsUnfinishedJobs(t *testing.T) {
+	q := New(Config{Workers: 1, Capacity: 1, MaxAttempts: 5, Backoff: time.Millisecond})
+	stuck := newTestJob(0)
+	stuck.release, stuck.started = make(chan struct{}), make(chan struct{})
+	waiting := newTestJob(0)
+	if err := q.Enqueue(stuck); err != nil {
+		t.Fatal(err)
+	}
+	<-stuck.started
+	if err := q.Enqueue(waiting); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := q.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown = %v, want DeadlineExceeded", err)
+	}
+
+	for name, job := range map[string]*testJob{"running": stuck, "waiting": waiting} {
+		if job.failed != 1 || !errors.Is(job.err, context.Canceled) {
+			t.Errorf("%s job: failed %d times with %v, want one cancellation", name, job.failed, job.err)
+		}
+	}
+	if stuck.runs != 1 || waiting.runs != 0 {
+		t.Errorf("runs: running %d, waiting %d; cancelled jobs must not be retried", stuck.runs, waiting.runs)
+	}
+}
@@ -0,0 +1,37 @@
+package ratelimit
+
+import "time"
+
+// period is a quota period. Periods follow UTC calendar days and months.
+type period struct {
+	name   string // Stored in usage_counters.period
+	label  string // Used in error messages
+	layout string // Formats the period key, e.g. "2026-10-18"
+	next   func(start time.Time) time.Time
+}
+
+var (
+	day = period{
+		name:   "day",
+		label:  "Daily",
+		layout: time.DateOnly,
+		next:   func(start time.Time) time.Time { return start.AddDate(0, 0, 1) },
+	}
+	month = period{
+		name:   "month",
+		label:  "Monthly",
+		layout: "2006-01",
+		next:   func(start time.Time) time.Time { return start.AddDate(0, 1, 0) },
+	}
+)
+
+// key identifies the period containing t.
+func (p period) key(t time.Time) string {
+	return t.UTC().Format(p.layout)
+}
+
+// reset is when the period containing t ends.
+func (p period) reset(t time.Time) time.Time {
+	start, _ := time.Parse(p.layout, p.key(t))
+	return p.next(start)
+}
@@ -0,0 +1,324 @@
+// Package ratelimit caps how much each app and user can transcribe: requests
+// per minute, concurrent requests, and daily and monthly minutes of audio.
+package ratelimit
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"silence-backend/auth"
+	"silence-backend/logger"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+)
+
+// CountersCollection persists audio usage per caller and period, so quotas survive restarts.
+const CountersCollection = "usage_counters"
+
+// Unlimited disables a limit when set on an app record. Zero inherits the server default.
+const Unlimited = -1
+
+// Limits caps a single caller. Zero means unlimited.
+type Limits struct {
+	RequestsPerMinute   int
+	DailyAudioMinutes   int
+	MonthlyAudioMinutes int
+	MaxConcurrent       int
+}
+
+// LimitError is returned when a caller is over a limit.
+type LimitError struct {
+	Message    string
+	RetryAfter time.Duration // Until the limit resets
+}
+
+func (e *LimitError) Error() string {
+	return e.Message
+}
+
+// RetryAfterSeconds formats RetryAfter for the Retry-After header, rounded up.
+func (e *LimitError) RetryAfterSeconds() string {
+	return strconv.Itoa(int(math.Ceil(e.RetryAfter.Seconds())))
+}
+
+// Limiter enforces Limits per app and per user. Superusers are never limited.
+// Request rates and concurrency are tracked in memory; audio usage is stored
+// in the usage_counters collection.
+type Limiter struct {
+	app      core.App
+	defaults Limits
+
+	mu      sync.Mutex
+	windows map[string]window // Requests in the current minute, by caller
+	swept   time.Time         // Minute whose stale windows were last pruned
+	active  map[string]int    // Requests in flight, by caller
+}
+
+// window counts requests in one fixed minute.
+type window struct {
+	start time.Time
+	count int
+}
+
+// caller is who a request is limited as.
+type caller struct {
+	key    string // "app:<id>" or "user:<id>"
+	limits Limits
+}
+
+// requestCallerKey is where the middleware stores the caller on the request.
+const requestCallerKey = "silenceRateLimitCaller"
+
+// New creates a limiter with the server-wide default limits.
+func New(app core.App, defaults Limits) *Limiter {
+	return &Limiter{
+		app:      app,
+		defaults: defaults,
+		windows:  make(map[string]window),
+		active:   make(map[string]int),
+	}
+}
+
+// Middleware rejects requests over the caller's request rate, concurrency or
+// an exhausted audio quota with 429 and Retry-After. It sets RateLimit-Limit,
+// RateLimit-Remaining and RateLimit-Reset for the request rate. It must run
+// after auth.RequireCaller.
+func (l *Limiter) Middleware() *hook.Handler[*core.RequestEvent] {
+	return &hook.Handler[*core.RequestEvent]{
+		Id: "silenceRateLimit",
+		Func: func(e *core.RequestEvent) error {
+			c := l.callerOf(e)
+			if c == nil {
+				return e.Next()
+			}
+			now := time.Now()
+
+			if limit := c.limits.RequestsPerMinute; limit > 0 {
+				count, reset := l.hit(c.key, now)
+				header := e.Response.Header()
+				header.Set("RateLimit-Limit", strconv.Itoa(limit))
+				header.Set("RateLimit-Remaining", strconv.Itoa(max(limit-count, 0)))
+				header.Set("RateLimit-Reset", strconv.Itoa(int(math.Ceil(reset.Seconds()))))
+				if count > limit {
+					return tooManyRequests(e, &LimitError{
+						Message:    fmt.Sprintf("Rate limit exceeded: %d requests per minute.", limit),
+						RetryAfter: reset,
+					})
+				}
+			}
+
+			if err := l.checkQuota(c, 0, now); err != nil {
+				return tooManyRequests(e, err)
+			}
+
+			if !l.acquire(c) {
+				return tooManyRequests(e, &LimitError{
+					Message:    fmt.Sprintf("Too many concurrent requests: at most %d at a time.", c.limits.MaxConcurrent),
+					RetryAfter: time.Second,
+				})
+			}
+			defer l.release(c)
+
+			e.Set(requestCallerKey, c)
+			return e.Next()
+		},
+	}
+}
+
+// CheckAudio returns a LimitError if transcribing d more audio would take the
+// caller over its daily or monthly quota.
+func (l *Limiter) CheckAudio(e *core.RequestEvent, d time.Duration) *LimitError {
+	c, _ := e.Get(requestCallerKey).(*caller)
+	if c == nil {
+		return nil
+	}
+	return l.checkQuota(c, d, time.Now())
+}
+
+// RecordAudio adds transcribed audio to the caller's daily and monthly usage.
+func (l *Limiter) RecordAudio(e *core.RequestEvent, d time.Duration) {
+	c, _ := e.Get(requestCallerKey).(*caller)
+	if c == nil {
+		return
+	}
+
+	now := time.Now().UTC()
+	for _, period := range []period{day, month} {
+		if err := l.addUsage(c.key, period, period.key(now), d); err != nil {
+			logger.Error("Failed to record audio usage", "caller", c.key, "period", period.name, "error", err)
+		}
+	}
+}
+
+// callerOf identifies the request's caller and resolves its limits.
+// It returns nil for superusers.
+func (l *Limiter) callerOf(e *core.RequestEvent) *caller {
+	if app := auth.RequestApp(e); app != nil {
+		return &caller{
+			key: "app:" + app.Id,
+			limits: Limits{
+				RequestsPerMinute:   override(l.defaults.RequestsPerMinute, app.GetInt("rate_limit_rpm")),
+				DailyAudioMinutes:   override(l.defaults.DailyAudioMinutes, app.GetInt("quota_daily_minutes")),
+				MonthlyAudioMinutes: override(l.defaults.MonthlyAudioMinutes, app.GetInt("quota_monthly_minutes")),
+				MaxConcurrent:       override(l.defaults.MaxConcurrent, app.GetInt("max_concurrent")),
+			},
+		}
+	}
+	if user := auth.RequestUser(e); user != nil {
+		return &caller{key: "user:" + user.Id, limits: l.defaults}
+	}
+	return nil
+}
+
+// override applies an app's setting to a default: 0 inherits, Unlimited disables.
+func override(fallback, value int) int {
+	switch {
+	case value == 0:
+		return fallback
+	case value < 0:
+		return 0
+	default:
+		return value
+	}
+}
+
+// hit counts a request in the caller's current minute and returns the count
+// and the time left until the window resets.
+func (l *Limiter) hit(key string, now time.Time) (int, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	start := now.Truncate(time.Minute)
+	if !l.swept.Equal(start) {
+		l.prune(start)
+	}
+
+	w := l.windows[key]
+	if !w.start.Equal(start) {
+		w = window{start: start}
+	}
+	w.count++
+	l.windows[key] = w
+
+	return w.count, start.Add(time.Minute).Sub(now)
+}
+
+// prune drops the windows of callers with no requests since the minute
+// started, so callers that stop calling don't stay in memory. It runs once
+// a minute, on the first request of the minute.
+func (l *Limiter) prune(start time.Time) {
+	for key, w := range l.windows {
+		if w.start.Before(start) {
+			delete(l.windows, key)
+		}
+	}
+	l.swept = start
+}
+
+// acquire takes a concurrency slot, returning false when all are in use.
+func (l *Limiter) acquire(c *caller) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if c.limits.MaxConcurrent > 0 && l.active[c.key] >= c.limits.MaxConcurrent {
+		return false
+	}
+	l.active[c.key]++
+	return true
+}
+
+// release frees a concurrency slot taken by acquire.
+func (l *Limiter) release(c *caller) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.active[c.key] <= 1 {
+		delete(l.active, c.key)
+		return
+	}
+	l.active[c.key]--
+}
+
+// checkQuota fails if the caller's usage plus d exceeds a daily or monthly quota.
+// Usage that can't be read doesn't block requests.
+func (l *Limiter) checkQuota(c *caller, d time.Duration, now time.Time) *LimitError {
+	now = now.UTC()
+	quotas := []struct {
+		period  period
+		minutes int
+	}{
+		{day, c.limits.DailyAudioMinutes},
+		{month, c.limits.MonthlyAudioMinutes},
+	}
+
+	for _, q := range quotas {
+		if q.minutes <= 0 {
+			continue
+		}
+
+		used, err := l.usage(c.key, q.period, q.period.key(now))
+		if err != nil {
+			logger.Error("Failed to read audio usage", "caller", c.key, "period", q.period.name, "error", err)
+			continue
+		}
+
+		quota := time.Duration(q.minutes) * time.Minute
+		if used >= quota || (d > 0 && used+d > quota) {
+			return &LimitError{
+				Message:    fmt.Sprintf("%s audio quota exceeded: %d minutes, %.1f used.", q.period.label, q.minutes, used.Minutes()),
+				RetryAfter: q.period.reset(now).Sub(now),
+			}
+		}
+	}
+
+	return nil
+}
+
+// usage reads a caller's audio usage for a period.
+func (l *Limiter) usage(key string, p period, periodKey string) (time.Duration, error) {
+	var row struct {
+		AudioMs int64 `db:"audio_ms"`
+	}
+	err := l.app.DB().
+		Select("audio_ms").
+		From(CountersCollection).
+		Where(dbx.HashExp{"caller": key, "period": p.name, "period_key": periodKey}).
+		One(&row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return time.Duration(row.AudioMs) * time.Millisecond, nil
+}
+
+// addUsage adds audio to a caller's usage for a period in a single upsert, so
+// concurrent requests can't lose updates.
+func (l *Limiter) addUsage(key string, p period, periodKey string, d time.Duration) error {
+	_, err := l.app.DB().NewQuery(`
+		INSERT INTO ` + CountersCollection + ` (id, caller, period, period_key, audio_ms)
+		VALUES ({:id}, {:caller}, {:period}, {:periodKey}, {:ms})
+		ON CONFLICT (caller, period, period_key) DO UPDATE SET audio_ms = audio_ms + excluded.audio_ms
+	`).Bind(dbx.Params{
+		"id":        core.GenerateDefaultRandomId(),
+		"caller":    key,
+		"period":    p.name,
+		"periodKey": periodKey,
+		"ms":        d.Milliseconds(),
+	}).Execute()
+	return err
+}
+
+// tooManyRequests responds 429 with a Retry-After header.
+func tooManyRequests(e *core.RequestEvent, err *LimitError) error {
+	e.Response.Header().Set("Retry-After", err.RetryAfterSeconds())
+	return e.TooManyRequestsError(err.Message, nil)
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHit(t *testing.T) {
+	minute := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		hits      []string      // Caller of each earlier request, all in the first minute
+		key       string        // Caller of the checked request
+		at        time.Duration // When the checked request arrives, from the first minute
+		wantCount int
+		wantReset time.Duration
+		wantKeys  []string // Callers with a window afterwards
+	}{
+		{
+			name:      "first request",
+			key:       "app:a",
+			at:        10 * time.Second,
+			wantCount: 1,
+			wantReset: 50 * time.Second,
+			wantKeys:  []string{"app:a"},
+		},
+		{
+			name:      "counts within the minute",
+			hits:      []string{"app:a", "app:a", "user:b"},
+			key:       "app:a",
+			at:        59 * time.Second,
+			wantCount: 3,
+			wantReset: time.Second,
+			wantKeys:  []string{"app:a", "user:b"},
+		},
+		{
+			name:      "resets in the next minute",
+			hits:      []string{"app:a", "app:a"},
+			key:       "app:a",
+			at:        time.Minute,
+			wantCount: 1,
+			wantReset: time.Minute,
+			wantKeys:  []string{"app:a"},
+		},
+		{
+			name:      "prunes callers idle since the last minute",
+			hits:      []string{"app:a", "user:b", "user:c"},
+			key:       "app:d",
+			at:        3 * time.Minute,
+			wantCount: 1,
+			wantReset: time.Minute,
+			wantKeys:  []string{"app:d"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(nil, Limits{})
+			for _, key := range tt.hits {
+				l.hit(key, minute)
+			}
+
+			count, reset := l.hit(tt.key, minute.Add(tt.at))
+			if count != tt.wantCount || reset != tt.wantReset {
+				t.Errorf("hit() = %d, %v, want %d, %v", count, reset, tt.wantCount, tt.wantReset)
+			}
+			if len(l.windows) != len(tt.wantKeys) {
+				t.Errorf("windows = %v, want keys %v", l.windows, tt.wantKeys)
+			}
+			for _, key := range tt.wantKeys {
+				if _, ok := l.windows[key]; !ok {
+					t.Errorf("window of %s was pruned", key)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,363 @@
+// Package retention enforces how long transcripts and their audio are kept,
+// including the raw uploads held by the failures and persist_failures dead letters.
+package retention
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"silence-backend/compression"
+	"silence-backend/logger"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// AudioAction is what happens to audio past its retention period.
+type AudioAction string
+
+const (
+	// AudioDelete removes the audio file and keeps the transcript.
+	AudioDelete AudioAction = "delete"
+	// AudioDownsample re-encodes the audio at a very low bitrate.
+	AudioDownsample AudioAction = "downsample"
+)
+
+// KeepForever disables expiry when used as a retention period in days.
+const KeepForever = -1
+
+// statusExpired is the transcript status once its audio was deleted by the policy.
+const statusExpired = "expired"
+
+// Policy says how many days audio and transcript text are kept.
+// Zero or KeepForever keeps data indefinitely.
+type Policy struct {
+	AudioDays   int
+	TextDays    int
+	AudioAction AudioAction
+}
+
+// ParseAudioAction validates an audio action name.
+func ParseAudioAction(name string) (AudioAction, error) {
+	switch action := AudioAction(name); action {
+	case AudioDelete, AudioDownsample:
+		return action, nil
+	default:
+		return "", fmt.Errorf("invalid audio action: %q, valid options: delete, downsample", name)
+	}
+}
+
+// Item is a transcript affected by a retention run.
+type Item struct {
+	ID      string `json:"id" example:"ead6abyjn82q49r"`
+	App     string `json:"app,omitempty" example:"k2l3m4n5o6p7q8r"`
+	Created string `json:"created" example:"2025-09-01 10:00:00.000Z"`
+}
+
+// Report describes what a retention run removed, or would remove in a dry run.
+// Dead-letter uploads are raw audio kept for replay, so they are always deleted
+// rather than downsampled.
+type Report struct {
+	DryRun                 bool     `json:"dry_run" example:"true"`
+	StartedAt              string   `json:"started_at" example:"2026-10-18 03:30:00.000Z"`
+	TranscriptsDeleted     []Item   `json:"transcripts_deleted"`
+	AudioDeleted           []Item   `json:"audio_deleted"`
+	AudioDownsampled       []Item   `json:"audio_downsampled"`
+	FailureAudioDeleted    []Item   `json:"failure_audio_deleted"`
+	DeadLetterAudioDeleted []Item   `json:"dead_letter_audio_deleted"`
+	Errors                 []string `json:"errors,omitempty"`
+}
+
+// Enforcer applies the global policy and per-app overrides to the silence collection.
+type Enforcer struct {
+	app         core.App
+	global      Policy
+	downsampler *compression.FFmpegCompressor
+}
+
+// NewEnforcer creates an enforcer. downsampler is only used with AudioDownsample
+// and should produce a low-bitrate codec.
+func NewEnforcer(app core.App, global Policy, downsampler *compression.FFmpegCompressor) *Enforcer {
+	return &Enforcer{
+		app:         app,
+		global:      global,
+		downsampler: downsampler,
+	}
+}
+
+// group is a set of transcripts sharing one policy: those of one app, or
+// those of every app not in exclude.
+type group struct {
+	app     string
+	exclude []string
+	policy  Policy
+}
+
+// filter restricts a query to the group, given the field holding the app.
+func (g group) filter(appField string) (string, dbx.Params) {
+	if g.app != "" {
+		return appField + " = {:app}", dbx.Params{"app": g.app}
+	}
+
+	conditions := make([]string, len(g.exclude))
+	params := dbx.Params{}
+	for i, app := range g.exclude {
+		key := fmt.Sprintf("app%d", i)
+		conditions[i] = appField + " != {:" + key + "}"
+		params[key] = app
+	}
+	return strings.Join(conditions, " && "), params
+}
+
+// Run enforces retention. With dryRun set nothing is modified and the report
+// lists what would have been removed. Errors on individual records are
+// collected in the report instead of aborting the run.
+func (e *Enforcer) Run(ctx context.Context, dryRun bool) (*Report, error) {
+	report := &Report{
+		DryRun:                 dryRun,
+		StartedAt:              types.NowDateTime().String(),
+		TranscriptsDeleted:     []Item{},
+		AudioDeleted:           []Item{},
+		AudioDownsampled:       []Item{},
+		FailureAudioDeleted:    []Item{},
+		DeadLetterAudioDeleted: []Item{},
+	}
+
+	groups, err := e.groups()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, g := range groups {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		// Rows created before timestamps were recorded have no age and are never expired
+		if days := g.policy.TextDays; days > 0 {
+			records, err := e.expired("silence", "app", g, "", now.AddDate(0, 0, -days))
+			if err != nil {
+				return nil, err
+			}
+			for _, record := range records {
+				if !dryRun {
+					if err := e.app.Delete(record); err != nil {
+						report.Errors = append(report.Errors, fmt.Sprintf("delete %s: %v", record.Id, err))
+						continue
+					}
+				}
+				report.TranscriptsDeleted = append(report.TranscriptsDeleted, newItem(record))
+			}
+		}
+
+		if days := g.policy.AudioDays; days > 0 {
+			extra := "audio != ''"
+			if g.policy.AudioAction == AudioDownsample {
+				extra += " && audio_downsampled = false"
+			}
+			cutoff := now.AddDate(0, 0, -days)
+			records, err := e.expired("silence", "app", g, extra, cutoff)
+			if err != nil {
+				return nil, err
+			}
+			for _, record := range records {
+				if g.policy.AudioAction == AudioDownsample {
+					if !dryRun {
+						if err := e.downsample(ctx, record); err != nil {
+							report.Errors = append(report.Errors, fmt.Sprintf("downsample %s: %v", record.Id, err))
+							continue
+						}
+					}
+					report.AudioDownsampled = append(report.AudioDownsampled, newItem(record))
+					continue
+				}
+
+				if !dryRun {
+					// Clearing the file field makes PocketBase delete the file on save
+					record.Set("audio", nil)
+					record.Set("status", statusExpired)
+					if err := e.app.Save(record); err != nil {
+						report.Errors = append(report.Errors, fmt.Sprintf("delete audio %s: %v", record.Id, err))
+						continue
+					}
+				}
+				report.AudioDeleted = append(report.AudioDeleted, newItem(record))
+			}
+
+			items, err := e.expireDeadLetters("failures", "app", g, cutoff, dryRun, report)
+			if err != nil {
+				return nil, err
+			}
+			report.FailureAudioDeleted = append(report.FailureAudioDeleted, items...)
+
+			items, err = e.expireDeadLetters("persist_failures", "transcript.app", g, cutoff, dryRun, report)
+			if err != nil {
+				return nil, err
+			}
+			report.DeadLetterAudioDeleted = append(report.DeadLetterAudioDeleted, items...)
+		}
+	}
+
+	return report, nil
+}
+
+// expireDeadLetters deletes the uploads of a dead-letter collection older than
+// cutoff, keeping the rest of each record for debugging. A transcript still
+// waiting on a deleted persist_failures upload is marked expired, since its
+// audio can no longer be backfilled.
+func (e *Enforcer) expireDeadLetters(collection, appField string, g group, cutoff time.Time, dryRun bool, report *Report) ([]Item, error) {
+	records, err := e.expired(collection, appField, g, "audio != ''", cutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []Item{}
+	for _, record := range records {
+		item := newItem(record)
+		if !dryRun {
+			record.Set("audio", nil)
+			if err := e.app.Save(record); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("delete %s audio %s: %v", collection, record.Id, err))
+				continue
+			}
+			if id := record.GetString("transcript"); id != "" && collection == "persist_failures" {
+				e.expireTranscript(id, report)
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// expireTranscript marks a transcript whose audio failed to store as expired.
+func (e *Enforcer) expireTranscript(id string, report *Report) {
+	transcript, err := e.app.FindRecordById("silence", id)
+	if err != nil || transcript.GetString("status") != "audio_failed" {
+		return
+	}
+	transcript.Set("status", statusExpired)
+	if err := e.app.Save(transcript); err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("expire transcript %s: %v", id, err))
+	}
+}
+
+// groups splits transcripts by policy: one group per app that overrides the
+// global policy, and one for everything else.
+func (e *Enforcer) groups() ([]group, error) {
+	apps, err := e.app.FindRecordsByFilter("apps", "audio_retention_days != 0 || text_retention_days != 0", "", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []group
+	var exclude []string
+	for _, app := range apps {
+		policy := e.global
+		if days := app.GetInt("audio_retention_days"); days != 0 {
+			policy.AudioDays = days
+		}
+		if days := app.GetInt("text_retention_days"); days != 0 {
+			policy.TextDays = days
+		}
+		groups = append(groups, group{app: app.Id, policy: policy})
+		exclude = append(exclude, app.Id)
+	}
+
+	groups = append(groups, group{exclude: exclude, policy: e.global})
+
+	return groups, nil
+}
+
+// expired finds records of the collection in the group created before cutoff
+// that match extra. appField is the field holding the record's app.
+func (e *Enforcer) expired(collection, appField string, g group, extra string, cutoff time.Time) ([]*core.Record, error) {
+	filter := "created != '' && created < {:cutoff}"
+	groupFilter, params := g.filter(appField)
+	if groupFilter != "" {
+		filter += " && (" + groupFilter + ")"
+	}
+	if extra != "" {
+		filter += " && " + extra
+	}
+	params["cutoff"] = cutoff.UTC().Format(types.DefaultDateLayout)
+
+	return e.app.FindRecordsByFilter(collection, filter, "created", 0, 0, params)
+}
+
+// downsample replaces a record's audio with a low-bitrate re-encode.
+func (e *Enforcer) downsample(ctx context.Context, record *core.Record) error {
+	if e.downsampler == nil {
+		return fmt.Errorf("no downsampler configured")
+	}
+
+	fsys, err := e.app.NewFilesystem()
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+
+	reader, err := fsys.GetReader(record.BaseFilesPath() + "/" + record.GetString("audio"))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	var out bytes.Buffer
+	if err := e.downsampler.Transcode(ctx, reader, &out); err != nil {
+		return err
+	}
+
+	codec := e.downsampler.Codec()
+	file, err := filesystem.NewFileFromBytes(out.Bytes(), "audio"+codec.Extension())
+	if err != nil {
+		return err
+	}
+
+	record.Set("audio", file)
+	record.Set("codec", string(codec))
+	record.Set("audio_downsampled", true)
+	return e.app.Save(record)
+}
+
+// LogReport writes a summary of a retention run, including affected ids.
+func LogReport(report *Report) {
+	logger.Info("Retention run finished",
+		"dry_run", report.DryRun,
+		"transcripts_deleted", len(report.TranscriptsDeleted),
+		"audio_deleted", len(report.AudioDeleted),
+		"audio_downsampled", len(report.AudioDownsampled),
+		"failure_audio_deleted", len(report.FailureAudioDeleted),
+		"dead_letter_audio_deleted", len(report.DeadLetterAudioDeleted),
+		"errors", len(report.Errors),
+		"transcript_ids", itemIDs(report.TranscriptsDeleted),
+		"audio_deleted_ids", itemIDs(report.AudioDeleted),
+		"audio_downsampled_ids", itemIDs(report.AudioDownsampled),
+		"failure_audio_ids", itemIDs(report.FailureAudioDeleted),
+		"dead_letter_audio_ids", itemIDs(report.DeadLetterAudioDeleted),
+	)
+	for _, msg := range report.Errors {
+		logger.Error("Retention error", "error", msg)
+	}
+}
+
+func newItem(record *core.Record) Item {
+	return Item{
+		ID:      record.Id,
+		App:     record.GetString("app"),
+		Created: record.GetString("created"),
+	}
+}
+
+func itemIDs(items []Item) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	return ids
+}
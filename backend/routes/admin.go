@@ -0,0 +1,62 @@
+package routes
+
+import (
+	"silence-backend/handlers"
+	"silence-backend/transcription"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// setupAdmin registers the superuser-only administration endpoints under /admin.
+// They act across all apps and users, so neither user tokens nor API keys are accepted.
+//   - GET /admin/retention: Dry run of the retention policy
+//   - GET /admin/queue: Persistence queue depth and counters
+//   - POST /admin/audio/backfill: Retry storing audio of transcripts in audio_failed status
+//   - POST /admin/failures/{id}/replay: Replay a failed transcription against a chosen provider
+//   - GET, POST /admin/apps/{id}/keys: List and create app API keys
+//   - POST /admin/apps/{id}/keys/{keyId}/rotate, DELETE /admin/apps/{id}/keys/{keyId}: Rotate and revoke API keys
+func setupAdmin(se *core.ServeEvent, app core.App, config handlers.Config, defaultProvider transcription.TranscriptionProvider, providers map[transcription.ProviderName]transcription.TranscriptionProvider) {
+	admin := se.Router.Group("/admin")
+	admin.Bind(apis.RequireSuperuserAuth())
+
+	admin.GET("/retention", func(re *core.RequestEvent) error {
+		SetCORSHeaders(re)
+		return handlers.HandleRetentionPreview(re, config)
+	})
+
+	admin.GET("/queue", func(re *core.RequestEvent) error {
+		SetCORSHeaders(re)
+		return handlers.HandleQueueStats(re, config)
+	})
+
+	admin.POST("/audio/backfill", func(re *core.RequestEvent) error {
+		SetCORSHeaders(re)
+		return handlers.HandleAudioBackfill(re, app, config)
+	})
+
+	admin.POST("/failures/{id}/replay", func(re *core.RequestEvent) error {
+		SetCORSHeaders(re)
+		return handlers.HandleReplayFailure(re, app, config, defaultProvider, providers)
+	})
+
+	admin.GET("/apps/{id}/keys", func(re *core.RequestEvent) error {
+		SetCORSHeaders(re)
+		return handlers.HandleListAPIKeys(re, app)
+	})
+
+	admin.POST("/apps/{id}/keys", func(re *core.RequestEvent) error {
+		SetCORSHeaders(re)
+		return handlers.HandleCreateAPIKey(re, app)
+	})
+
+	admin.POST("/apps/{id}/keys/{keyId}/rotate", func(re *core.RequestEvent) error {
+		SetCORSHeaders(re)
+		return handlers.HandleRotateAPIKey(re, app)
+	})
+
+	admin.DELETE("/apps/{id}/keys/{keyId}", func(re *core.RequestEvent) error {
+		SetCORSHeaders(re)
+		return handlers.HandleRevokeAPIKey(re, app)
+	})
+}
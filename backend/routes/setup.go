@@ -4,6 +4,7 @@ package routes
 import (
 	"net/http"
 
+	"silence-backend/auth"
 	_ "silence-backend/docs" // Swagger docs
 	"silence-backend/handlers"
 	"silence-backend/transcription"
@@ -13,28 +14,82 @@ import (
 )
 
 // SetCORSHeaders configures Cross-Origin Resource Sharing (CORS) headers for API responses.
-// Allows all origins, POST and OPTIONS methods, and Content-Type header.
+// Allows all origins, GET, POST, DELETE and OPTIONS methods, and Content-Type, Authorization and Range headers.
 func SetCORSHeaders(re *core.RequestEvent) {
 	re.Response.Header().Set("Access-Control-Allow-Origin", "*")
-	re.Response.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
-	re.Response.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	re.Response.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+	re.Response.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Range")
 }
 
 // Setup registers all HTTP routes for the Silence backend API.
 // Configures the following endpoints:
-//   - POST /speak: Audio transcription (multipart or JSON)
+//   - POST /speak: Audio transcription (user token, or API key with 'speak' scope; rate limited)
 //   - OPTIONS /speak: CORS preflight handling
-func Setup(se *core.ServeEvent, app core.App, defaultProvider transcription.TranscriptionProvider, providers map[transcription.ProviderName]transcription.TranscriptionProvider) {
+//   - GET /transcripts: Transcript history with filters and cursor pagination (user, API key or superuser)
+//   - GET /transcripts/search: Full-text search with the same filters (user, API key or superuser)
+//   - GET /transcripts/{id}: Single transcript with request details (user, API key or superuser)
+//   - DELETE /transcripts/{id}: Transcript and audio deletion (user, API key or superuser)
+//   - GET /transcripts/{id}/audio: Stored audio download (user, API key or superuser)
+//   - OPTIONS /transcripts/...: CORS preflight handling
+//   - GET /usage: Usage and estimated cost, grouped by day, app, user or provider (user, API key or superuser)
+//   - /admin/...: Superuser-only administration, see setupAdmin
+func Setup(se *core.ServeEvent, app core.App, config handlers.Config, defaultProvider transcription.TranscriptionProvider, providers map[transcription.ProviderName]transcription.TranscriptionProvider) {
 	se.Router.POST("/speak", func(re *core.RequestEvent) error {
 		SetCORSHeaders(re)
-		return handlers.HandleSpeak(re, app, defaultProvider, providers)
-	})
+		return handlers.HandleSpeak(re, app, config, defaultProvider, providers)
+	}).Bind(auth.RequireCaller(auth.ScopeSpeak), config.Limiter.Middleware())
 
 	se.Router.OPTIONS("/speak", func(re *core.RequestEvent) error {
 		SetCORSHeaders(re)
 		return re.NoContent(200)
 	})
 
+	// Users see their own transcripts, API keys their app's, superusers all of them
+	transcripts := se.Router.Group("/transcripts")
+	readTranscripts := auth.RequireCaller(auth.ScopeTranscriptsRead)
+
+	transcripts.GET("", func(re *core.RequestEvent) error {
+		SetCORSHeaders(re)
+		return handlers.HandleListTranscripts(re, app)
+	}).Bind(readTranscripts)
+
+	transcripts.GET("/search", func(re *core.RequestEvent) error {
+		SetCORSHeaders(re)
+		return handlers.HandleSearchTranscripts(re, app)
+	}).Bind(readTranscripts)
+
+	transcripts.GET("/{id}", func(re *core.RequestEvent) error {
+		SetCORSHeaders(re)
+		return handlers.HandleGetTranscript(re, app)
+	}).Bind(readTranscripts)
+
+	transcripts.DELETE("/{id}", func(re *core.RequestEvent) error {
+		SetCORSHeaders(re)
+		return handlers.HandleDeleteTranscript(re, app)
+	}).Bind(auth.RequireCaller(auth.ScopeTranscriptsDelete))
+
+	transcripts.GET("/{id}/audio", func(re *core.RequestEvent) error {
+		SetCORSHeaders(re)
+		return handlers.HandleTranscriptAudio(re, app)
+	}).Bind(readTranscripts)
+
+	se.Router.GET("/usage", func(re *core.RequestEvent) error {
+		SetCORSHeaders(re)
+		return handlers.HandleUsage(re, app)
+	}).Bind(auth.RequireCaller(auth.ScopeUsageRead))
+
+	setupAdmin(se, app, config, defaultProvider, providers)
+
+	// Preflight requests carry no credentials, so they are registered outside the group
+	preflight := func(re *core.RequestEvent) error {
+		SetCORSHeaders(re)
+		return re.NoContent(200)
+	}
+	se.Router.OPTIONS("/transcripts", preflight)
+	se.Router.OPTIONS("/transcripts/{path...}", preflight)
+	se.Router.OPTIONS("/usage", preflight)
+	se.Router.OPTIONS("/admin/{path...}", preflight)
+
 	// Swagger UI - redirect /swagger to /swagger/index.html
 	se.Router.GET("/swagger", func(re *core.RequestEvent) error {
 		http.Redirect(re.Response, re.Request, "/swagger/index.html", http.StatusMovedPermanently)
@@ -0,0 +1,214 @@
+package transcription
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"silence-backend/audio"
+)
+
+// maxOverlapWords bounds the text-based overlap search for providers without timestamps.
+const maxOverlapWords = 20
+
+// ChunkingConfig controls how long recordings are split before transcription.
+type ChunkingConfig struct {
+	MaxDuration time.Duration // Chunks never exceed this length. Zero disables chunking.
+	Overlap     time.Duration // Audio repeated across a hard cut when no pause is found
+	Concurrency int           // Maximum number of chunks transcribed at the same time
+}
+
+// ChunkedProvider splits long recordings at pauses and transcribes the pieces in parallel.
+// It wraps another provider, so it can sit in front of a single provider or a ProviderChain.
+// Recordings shorter than MaxDuration are passed through untouched.
+type ChunkedProvider struct {
+	provider TranscriptionProvider
+	config   ChunkingConfig
+}
+
+// NewChunkedProvider creates a provider that chunks long audio before delegating to provider.
+func NewChunkedProvider(provider TranscriptionProvider, config ChunkingConfig) *ChunkedProvider {
+	if config.Concurrency <= 0 {
+		config.Concurrency = 1
+	}
+	return &ChunkedProvider{
+		provider: provider,
+		config:   config,
+	}
+}
+
+// Name reports the wrapped provider's name, so chains of chunked providers
+// attribute their attempts.
+func (cp *ChunkedProvider) Name() ProviderName {
+	return nameOf(cp.provider)
+}
+
+// Transcribe splits audio into chunks, transcribes them with bounded concurrency
+// and stitches the results back into one transcript on the original timeline.
+// Each chunk is listed in the result's Calls with the provider that served it.
+// Returns an error if any chunk fails, since a transcript with holes is misleading.
+func (cp *ChunkedProvider) Transcribe(audioData []byte, opts TranscriptionOptions) (*TranscriptionResult, error) {
+	if cp.config.MaxDuration <= 0 {
+		return cp.provider.Transcribe(audioData, opts)
+	}
+
+	pcm := opts.PCM
+	if pcm == nil {
+		var err error
+		if pcm, err = DecodeAudio(audioData, opts.Metadata); err != nil {
+			// Leave undecodable audio to the provider, it reports a better error
+			return cp.provider.Transcribe(audioData, opts)
+		}
+	}
+	if pcm.Duration() <= cp.config.MaxDuration {
+		return cp.provider.Transcribe(audioData, opts)
+	}
+
+	chunks := audio.Split(pcm, audio.SplitOptions{
+		MaxDuration: cp.config.MaxDuration,
+		Overlap:     cp.config.Overlap,
+		VAD:         audio.DefaultVADOptions(),
+	})
+	if len(chunks) == 1 {
+		return cp.provider.Transcribe(audioData, opts)
+	}
+
+	results := make([]*TranscriptionResult, len(chunks))
+	errs := make([]error, len(chunks))
+	sem := make(chan struct{}, cp.config.Concurrency)
+	var wg sync.WaitGroup
+
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			chunkOpts := opts
+			chunkData, metadata, err := EncodeAudio(chunk.PCM, opts.Metadata.Format)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			chunkOpts.Metadata = metadata
+			chunkOpts.PCM = chunk.PCM
+			results[i], errs[i] = cp.provider.Transcribe(chunkData, chunkOpts)
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("chunk %d of %d failed: %w", i+1, len(chunks), err)
+		}
+	}
+
+	stitched := stitchChunks(chunks, results)
+	for i, chunk := range chunks {
+		stitched.Calls = append(stitched.Calls, results[i].ProviderCalls(chunk.PCM.Duration())...)
+	}
+	return stitched, nil
+}
+
+// stitchChunks merges chunk results into a single transcript.
+// Segment timestamps are shifted by each chunk's offset. Where chunks overlap,
+// segments are assigned to the chunk whose own region contains their midpoint;
+// providers without timestamps fall back to removing repeated words.
+func stitchChunks(chunks []audio.Chunk, results []*TranscriptionResult) *TranscriptionResult {
+	stitched := &TranscriptionResult{Biased: true}
+	var texts []string
+	languages := make(map[string]int)
+	providers := make(map[string]int)
+
+	for i, chunk := range chunks {
+		result := results[i]
+		if result.LanguageCode != "" {
+			languages[result.LanguageCode]++
+		}
+		providers[string(result.Provider)]++
+		stitched.Biased = stitched.Biased && result.Biased
+		stitched.FailedAttempts = append(stitched.FailedAttempts, result.FailedAttempts...)
+
+		offset := chunk.Offset.Seconds()
+		from := chunk.Cut.Seconds()
+		to := math.Inf(1)
+		if i+1 < len(chunks) {
+			to = chunks[i+1].Cut.Seconds()
+		}
+
+		var text string
+		if len(result.Segments) > 0 {
+			var sb strings.Builder
+			for _, seg := range result.Segments {
+				seg.Start += offset
+				seg.End += offset
+				if mid := (seg.Start + seg.End) / 2; mid < from || mid >= to {
+					continue
+				}
+				stitched.Segments = append(stitched.Segments, seg)
+				sb.WriteString(seg.Text)
+			}
+			text = strings.TrimSpace(sb.String())
+		} else {
+			text = strings.TrimSpace(result.Text)
+			if chunk.Offset < chunk.Cut && len(texts) > 0 {
+				text = dropRepeatedPrefix(texts[len(texts)-1], text)
+			}
+		}
+
+		if text != "" {
+			texts = append(texts, text)
+		}
+	}
+
+	stitched.Text = strings.Join(texts, " ")
+	stitched.LanguageCode = mostCommon(languages)
+	stitched.Provider = ProviderName(mostCommon(providers))
+	return stitched
+}
+
+// dropRepeatedPrefix removes the longest run of leading words in next that
+// repeats the trailing words of prev, comparing case- and punctuation-insensitively.
+func dropRepeatedPrefix(prev, next string) string {
+	prevWords := strings.Fields(prev)
+	nextWords := strings.Fields(next)
+
+	limit := min(maxOverlapWords, len(prevWords), len(nextWords))
+	for n := limit; n > 0; n-- {
+		match := true
+		tail := prevWords[len(prevWords)-n:]
+		for j := range n {
+			if normalizeWord(tail[j]) != normalizeWord(nextWords[j]) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return strings.Join(nextWords[n:], " ")
+		}
+	}
+
+	return next
+}
+
+// normalizeWord lowercases a word and strips punctuation for overlap comparison.
+func normalizeWord(w string) string {
+	return strings.ToLower(strings.TrimFunc(w, func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSymbol(r)
+	}))
+}
+
+// mostCommon returns the key with the highest count, or "" for an empty map.
+func mostCommon(counts map[string]int) string {
+	var best string
+	for key, count := range counts {
+		if count > counts[best] || (count == counts[best] && key < best) {
+			best = key
+		}
+	}
+	return best
+}
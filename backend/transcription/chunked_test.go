@@ -0,0 +1,124 @@
+package transcription
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"silence-backend/audio"
+)
+
+func TestStitchChunksShiftsSegments(t *testing.T) {
+	// The second chunk repeats one second before its cut at 10s
+	chunks := []audio.Chunk{
+		{Offset: 0, Cut: 0},
+		{Offset: 9 * time.Second, Cut: 10 * time.Second},
+	}
+	results := []*TranscriptionResult{
+		{Provider: ProviderElevenLabs, LanguageCode: "en", Biased: true, Segments: []Segment{
+			{Start: 0, End: 4, Text: "Hello there."},
+			{Start: 8.5, End: 9.8, Text: " How are"},
+		}},
+		{Provider: ProviderElevenLabs, LanguageCode: "en", Biased: false, Segments: []Segment{
+			{Start: 0, End: 0.6, Text: "are"}, // Midpoint 9.3s, belongs to the first chunk
+			{Start: 1.1, End: 2, Text: " you?"},
+		}},
+	}
+
+	got := stitchChunks(chunks, results)
+	if got.Text != "Hello there. How are you?" {
+		t.Errorf("text = %q", got.Text)
+	}
+	wantSegments := []Segment{
+		{Start: 0, End: 4, Text: "Hello there."},
+		{Start: 8.5, End: 9.8, Text: " How are"},
+		{Start: 10.1, End: 11, Text: " you?"},
+	}
+	if !reflect.DeepEqual(got.Segments, wantSegments) {
+		t.Errorf("segments = %+v, want %+v", got.Segments, wantSegments)
+	}
+	if got.Biased {
+		t.Error("stitched result is biased although a chunk wasn't")
+	}
+	if got.LanguageCode != "en" || got.Provider != ProviderElevenLabs {
+		t.Errorf("language %q, provider %q", got.LanguageCode, got.Provider)
+	}
+}
+
+// lengthProvider answers short audio as Chutes and the rest as ElevenLabs.
+type lengthProvider struct{}
+
+func (lengthProvider) Transcribe(audioData []byte, opts TranscriptionOptions) (*TranscriptionResult, error) {
+	provider := ProviderElevenLabs
+	if opts.PCM.Duration() < 8*time.Second {
+		provider = ProviderChutes
+	}
+	return &TranscriptionResult{Text: "words", Provider: provider}, nil
+}
+
+func TestChunkedProviderListsCalls(t *testing.T) {
+	pcm := &audio.PCM{Samples: make([]int16, 25*16000), SampleRate: 16000, Channels: 1}
+	config := ChunkingConfig{MaxDuration: 10 * time.Second, Overlap: time.Second}
+	chunks := audio.Split(pcm, audio.SplitOptions{MaxDuration: config.MaxDuration, Overlap: config.Overlap, VAD: audio.DefaultVADOptions()})
+
+	result, err := NewChunkedProvider(lengthProvider{}, config).Transcribe(nil, TranscriptionOptions{
+		Metadata: AudioMetadata{Format: AudioFormatPCMLE16, SampleRate: 16000, Channels: 1, BitsPerSample: 16},
+		PCM:      pcm,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want []Call
+	for _, chunk := range chunks {
+		provider := ProviderElevenLabs
+		if chunk.PCM.Duration() < 8*time.Second {
+			provider = ProviderChutes
+		}
+		want = append(want, Call{Provider: provider, Duration: chunk.PCM.Duration()})
+	}
+	if len(want) < 2 || want[len(want)-1].Provider != ProviderChutes {
+		t.Fatalf("test audio should end with a short chunk, got %+v", want)
+	}
+	if !reflect.DeepEqual(result.Calls, want) {
+		t.Errorf("calls = %+v, want %+v", result.Calls, want)
+	}
+}
+
+func TestStitchChunksWithoutTimestamps(t *testing.T) {
+	chunks := []audio.Chunk{
+		{Offset: 0, Cut: 0},
+		{Offset: 9 * time.Second, Cut: 10 * time.Second},  // Hard cut, overlapping
+		{Offset: 20 * time.Second, Cut: 20 * time.Second}, // Cut at a pause
+	}
+	results := []*TranscriptionResult{
+		{Provider: ProviderChutes, LanguageCode: "ru", Text: "we should ship the release"},
+		{Provider: ProviderChutes, LanguageCode: "ru", Text: "The release, tomorrow morning"},
+		{Provider: ProviderChutes, LanguageCode: "en", Text: "morning coffee first"},
+	}
+
+	got := stitchChunks(chunks, results)
+	if want := "we should ship the release tomorrow morning morning coffee first"; got.Text != want {
+		t.Errorf("text = %q, want %q", got.Text, want)
+	}
+	if got.LanguageCode != "ru" {
+		t.Errorf("language = %q, want the most common one", got.LanguageCode)
+	}
+}
+
+func TestDropRepeatedPrefix(t *testing.T) {
+	tests := []struct {
+		prev, next, want string
+	}{
+		{"we should ship the release", "the release tomorrow", "tomorrow"},
+		{"we should ship the release.", "The Release, tomorrow", "tomorrow"},
+		{"nothing in common", "entirely new words", "entirely new words"},
+		{"same", "same", ""},
+		{"", "next", "next"},
+	}
+	for _, tt := range tests {
+		if got := dropRepeatedPrefix(tt.prev, tt.next); got != tt.want {
+			t.Errorf("dropRepeatedPrefix(%q, %q) = %q, want %q", tt.prev, tt.next, got, tt.want)
+		}
+	}
+}
@@ -36,8 +36,15 @@ func NewChutesProvider(apiKey string) *ChutesProvider {
 	}
 }
 
+// Name returns the provider identifier.
+func (p *ChutesProvider) Name() ProviderName {
+	return ProviderChutes
+}
+
 // Transcribe processes audio data using the Chutes AI API.
 // Returns transcribed text. Language detection is not supported by this provider.
+// The endpoint takes only the audio and its language, so results are never
+// biased towards opts.Vocabulary; the caller corrects them afterwards.
 func (p *ChutesProvider) Transcribe(audioData []byte, opts TranscriptionOptions) (*TranscriptionResult, error) {
 	// Convert PCM to WAV if needed (Chutes API expects WAV format)
 	if opts.Metadata.Format == AudioFormatPCMLE16 {
@@ -100,12 +107,16 @@ func (p *ChutesProvider) Transcribe(audioData []byte, opts TranscriptionOptions)
 
 	// Concatenate all segment texts
 	var fullText string
+	resultSegments := make([]Segment, 0, len(segments))
 	for _, seg := range segments {
 		fullText += seg.Text
+		resultSegments = append(resultSegments, Segment{Start: seg.Start, End: seg.End, Text: seg.Text})
 	}
 
 	return &TranscriptionResult{
 		Text:         strings.TrimSpace(fullText),
 		LanguageCode: "", // Chutes API doesn't return language code
+		Segments:     resultSegments,
+		Provider:     ProviderChutes,
 	}, nil
 }
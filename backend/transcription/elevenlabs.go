@@ -7,7 +7,17 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http"
+	"strings"
 	"time"
+	"unicode/utf8"
+)
+
+// Limits of ElevenLabs keyterm prompting: at most 100 keyterms, each shorter
+// than 50 characters and at most 5 words long.
+const (
+	maxKeyterms      = 100
+	maxKeytermLength = 49
+	maxKeytermWords  = 5
 )
 
 // elevenLabsResponse represents the API response from ElevenLabs speech-to-text.
@@ -20,10 +30,10 @@ type elevenLabsResponse struct {
 
 // word represents timestamped word data from ElevenLabs response.
 type word struct {
-	Word      string  `json:"word"`
-	Start     float64 `json:"start"`
-	End       float64 `json:"end"`
-	Punctuate bool    `json:"punctuate"`
+	Word  string  `json:"word"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Type  string  `json:"type"` // "word", "spacing" or "audio_event"
 }
 
 // ElevenLabsProvider implements transcription using the ElevenLabs API.
@@ -38,6 +48,11 @@ func NewElevenLabsProvider(apiKey string) *ElevenLabsProvider {
 	}
 }
 
+// Name returns the provider identifier.
+func (p *ElevenLabsProvider) Name() ProviderName {
+	return ProviderElevenLabs
+}
+
 // Transcribe processes audio data using the ElevenLabs API.
 // Returns transcribed text and detected language code.
 func (p *ElevenLabsProvider) Transcribe(audioData []byte, opts TranscriptionOptions) (*TranscriptionResult, error) {
@@ -78,6 +93,16 @@ func (p *ElevenLabsProvider) Transcribe(audioData []byte, opts TranscriptionOpti
 		}
 	}
 
+	// Bias recognition towards the vocabulary with keyterm prompting. Terms
+	// the API would reject are left to the correction pass.
+	terms, biased := keyterms(opts.Vocabulary)
+	for _, term := range terms {
+		err = writer.WriteField("keyterms", term)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write keyterms field: %v", err)
+		}
+	}
+
 	// Disable audio event tagging (e.g., [music], [applause])
 	err = writer.WriteField("tag_audio_events", "false")
 	if err != nil {
@@ -130,8 +155,29 @@ func (p *ElevenLabsProvider) Transcribe(audioData []byte, opts TranscriptionOpti
 	}
 
 	// Map to generic result
+	segments := make([]Segment, 0, len(elevenLabsResp.Words))
+	for _, w := range elevenLabsResp.Words {
+		segments = append(segments, Segment{Start: w.Start, End: w.End, Text: w.Word})
+	}
+
 	return &TranscriptionResult{
 		Text:         elevenLabsResp.Text,
 		LanguageCode: elevenLabsResp.LanguageCode,
+		Segments:     segments,
+		Provider:     ProviderElevenLabs,
+		Biased:       biased && len(terms) > 0,
 	}, nil
 }
+
+// keyterms picks the vocabulary terms ElevenLabs accepts as keyterms, and
+// reports whether that's all of them.
+func keyterms(vocabulary []string) ([]string, bool) {
+	terms := make([]string, 0, min(len(vocabulary), maxKeyterms))
+	for _, term := range vocabulary {
+		if len(terms) == maxKeyterms || utf8.RuneCountInString(term) > maxKeytermLength || len(strings.Fields(term)) > maxKeytermWords {
+			continue
+		}
+		terms = append(terms, term)
+	}
+	return terms, len(terms) == len(vocabulary)
+}
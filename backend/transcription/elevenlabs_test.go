@@ -0,0 +1,37 @@
+package transcription
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestKeyterms(t *testing.T) {
+	many := make([]string, maxKeyterms+1)
+	for i := range many {
+		many[i] = "term" + strings.Repeat("x", i%10)
+	}
+
+	tests := []struct {
+		name       string
+		vocabulary []string
+		want       []string
+		all        bool
+	}{
+		{"all accepted", []string{"Kubernetes", "ACME Cloud"}, []string{"Kubernetes", "ACME Cloud"}, true},
+		{"too long", []string{"Kubernetes", strings.Repeat("a", 50)}, []string{"Kubernetes"}, false},
+		{"longest accepted", []string{strings.Repeat("a", 49)}, []string{strings.Repeat("a", 49)}, true},
+		{"too many words", []string{"one two three four five six", "one two three four five"}, []string{"one two three four five"}, false},
+		{"too many terms", many, many[:maxKeyterms], false},
+		{"none", nil, []string{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, all := keyterms(tt.vocabulary)
+			if !slices.Equal(got, tt.want) || all != tt.all {
+				t.Errorf("keyterms() = %v, %v, want %v, %v", got, all, tt.want, tt.all)
+			}
+		})
+	}
+}
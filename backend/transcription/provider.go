@@ -2,12 +2,49 @@ package transcription
 
 import (
 	"fmt"
+	"time"
+
+	"silence-backend/audio"
 )
 
 // TranscriptionResult represents a generic transcription response from any provider.
 type TranscriptionResult struct {
-	Text         string // Transcribed text
-	LanguageCode string // Detected language code (e.g., "en", "es")
+	Text           string       // Transcribed text
+	LanguageCode   string       // Detected language code (e.g., "en", "es")
+	Segments       []Segment    // Timestamped pieces of Text, if the provider returns them
+	Provider       ProviderName // Provider that produced the result
+	FailedAttempts []Attempt    // Providers that failed before Provider answered
+	Biased         bool         // Provider was biased towards TranscriptionOptions.Vocabulary
+	Calls          []Call       // Successful provider requests behind the result, if there was more than one
+}
+
+// Call is a successful request to a provider, as it is billed.
+type Call struct {
+	Provider ProviderName
+	Duration time.Duration // Audio sent with the request
+}
+
+// ProviderCalls returns the provider requests behind the result. A result
+// without Calls came from a single request to Provider with all of duration.
+func (r *TranscriptionResult) ProviderCalls(duration time.Duration) []Call {
+	if len(r.Calls) > 0 {
+		return r.Calls
+	}
+	return []Call{{Provider: r.Provider, Duration: duration}}
+}
+
+// Attempt records a failed call to a provider.
+type Attempt struct {
+	Provider ProviderName `json:"provider" example:"elevenlabs"`
+	Error    string       `json:"error" example:"ElevenLabs API error (status 429): rate limited"`
+}
+
+// Segment is a timestamped piece of a transcript.
+// Concatenating the Text of all segments reproduces the full transcript.
+type Segment struct {
+	Start float64 // Start time in seconds from the beginning of the audio
+	End   float64 // End time in seconds from the beginning of the audio
+	Text  string  // Text spoken in this interval, including surrounding whitespace
 }
 
 // AudioFormat represents the format of audio data.
@@ -42,6 +79,8 @@ type AudioMetadata struct {
 type TranscriptionOptions struct {
 	LanguageCode string        // ISO-639-1 or ISO-639-3 language code. Use "auto" or empty string for auto-detection.
 	Metadata     AudioMetadata // Audio format metadata
+	Vocabulary   []string      // Names and jargon to bias recognition towards, for providers that support it
+	PCM          *audio.PCM    // The audio already decoded, if the caller has it; spares wrappers decoding it again
 }
 
 // TranscriptionProvider defines the interface for audio transcription providers.
@@ -54,6 +93,36 @@ type TranscriptionProvider interface {
 	Transcribe(audioData []byte, opts TranscriptionOptions) (*TranscriptionResult, error)
 }
 
+// NamedProvider is implemented by providers that can report their identifier.
+// Wrappers such as ProviderChain don't implement it, they report the provider
+// that answered through TranscriptionResult.Provider instead.
+type NamedProvider interface {
+	Name() ProviderName
+}
+
+// nameOf returns the provider's identifier, or "unknown" if it doesn't report one.
+func nameOf(provider TranscriptionProvider) ProviderName {
+	if named, ok := provider.(NamedProvider); ok {
+		return named.Name()
+	}
+	return "unknown"
+}
+
+// ChainError is returned when every provider in a chain fails.
+// It carries each provider's error for debugging and replay.
+type ChainError struct {
+	Attempts []Attempt // One entry per provider, in the order they were tried
+	Err      error     // Last provider's error
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("all providers failed, last error: %v", e.Err)
+}
+
+func (e *ChainError) Unwrap() error {
+	return e.Err
+}
+
 // ProviderChain implements a fallback mechanism for multiple transcription providers.
 // It attempts transcription with each provider in sequence until one succeeds.
 type ProviderChain struct {
@@ -69,21 +138,28 @@ func NewProviderChain(providers ...TranscriptionProvider) *ProviderChain {
 }
 
 // Transcribe attempts transcription with each provider until one succeeds.
-// Returns the result from the first successful provider.
-// Returns an error only if all providers fail.
+// Returns the result from the first successful provider, with the failures before it
+// recorded in FailedAttempts.
+// Returns a *ChainError only if all providers fail.
 func (pc *ProviderChain) Transcribe(audioData []byte, opts TranscriptionOptions) (*TranscriptionResult, error) {
 	if len(pc.providers) == 0 {
 		return nil, fmt.Errorf("no transcription providers configured")
 	}
 
+	var attempts []Attempt
 	var lastErr error
 	for i, provider := range pc.providers {
 		result, err := provider.Transcribe(audioData, opts)
 		if err == nil {
+			if result.Provider == "" {
+				result.Provider = nameOf(provider)
+			}
+			result.FailedAttempts = append(attempts, result.FailedAttempts...)
 			return result, nil
 		}
+		attempts = append(attempts, Attempt{Provider: nameOf(provider), Error: err.Error()})
 		lastErr = fmt.Errorf("provider %d failed: %w", i+1, err)
 	}
 
-	return nil, fmt.Errorf("all providers failed, last error: %w", lastErr)
+	return nil, &ChainError{Attempts: attempts, Err: lastErr}
 }
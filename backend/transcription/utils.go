@@ -3,6 +3,9 @@ package transcription
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
+
+	"silence-backend/audio"
 )
 
 // PcmToWav converts PCM S16LE data to WAV format.
@@ -40,3 +43,41 @@ func PcmToWav(pcmData []byte, sampleRate, channels, bitsPerSample int) ([]byte,
 
 	return buf.Bytes(), nil
 }
+
+// DecodeAudio decodes audio data into 16-bit PCM samples based on its metadata.
+// Raw PCM uses the sample rate and channel count from metadata, WAV files
+// carry their own format in the header.
+func DecodeAudio(audioData []byte, metadata AudioMetadata) (*audio.PCM, error) {
+	switch metadata.Format {
+	case AudioFormatPCMLE16:
+		return audio.DecodePCM16LE(audioData, metadata.SampleRate, metadata.Channels)
+	case AudioFormatWAV:
+		return audio.ParseWAV(audioData)
+	default:
+		return nil, fmt.Errorf("unsupported audio format: %s", metadata.Format)
+	}
+}
+
+// EncodeAudio encodes PCM samples in the given container format
+// and returns metadata describing the encoded data.
+func EncodeAudio(pcm *audio.PCM, format AudioFormat) ([]byte, AudioMetadata, error) {
+	metadata := AudioMetadata{
+		Format:        format,
+		SampleRate:    pcm.SampleRate,
+		Channels:      pcm.Channels,
+		BitsPerSample: 16,
+	}
+
+	switch format {
+	case AudioFormatPCMLE16:
+		return pcm.Bytes(), metadata, nil
+	case AudioFormatWAV:
+		wavData, err := PcmToWav(pcm.Bytes(), pcm.SampleRate, pcm.Channels, 16)
+		if err != nil {
+			return nil, metadata, fmt.Errorf("failed to encode WAV: %v", err)
+		}
+		return wavData, metadata, nil
+	default:
+		return nil, metadata, fmt.Errorf("unsupported audio format: %s", format)
+	}
+}
@@ -0,0 +1,181 @@
+package usage
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"silence-backend/logger"
+	"silence-backend/transcription"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// Collection holds one usage record per successful provider request.
+const Collection = "usage"
+
+// Budget is the USD spend that triggers an alert and routes requests to the cheapest
+// provider first. Zero disables a threshold.
+type Budget struct {
+	DailyUSD   float64
+	MonthlyUSD float64
+}
+
+// Entry is a billable request.
+type Entry struct {
+	Provider   transcription.ProviderName
+	Duration   time.Duration // Audio sent to the provider
+	Transcript string        // Stored transcript, if any
+	App        string
+	Owner      string
+	Replay     bool // Replay of a recorded failure
+}
+
+// Meter records usage and tracks spend against the budget.
+type Meter struct {
+	app    core.App
+	prices PriceTable
+	budget Budget
+
+	mu     sync.Mutex
+	state  budgetState
+	loaded bool
+}
+
+// budgetState caches whether the budget of the current day and month is spent.
+type budgetState struct {
+	day        string
+	month      string
+	dayOver    bool
+	monthOver  bool
+	dailySpend float64
+	monthSpend float64
+}
+
+// NewMeter creates a meter with the given prices and budget.
+func NewMeter(app core.App, prices PriceTable, budget Budget) *Meter {
+	return &Meter{app: app, prices: prices, budget: budget}
+}
+
+// Prices returns the meter's price table.
+func (m *Meter) Prices() PriceTable {
+	return m.prices
+}
+
+// Record stores a usage record with billable seconds and estimated cost, then
+// checks the budget. The first time spend crosses a threshold in a period an
+// alert is logged.
+func (m *Meter) Record(entry Entry) error {
+	collection, err := m.app.FindCollectionByNameOrId(Collection)
+	if err != nil {
+		return err
+	}
+
+	price := m.prices[entry.Provider]
+	billable := price.BillableSeconds(entry.Duration)
+	cost := price.Cost(billable)
+
+	record := core.NewRecord(collection)
+	record.Set("provider", string(entry.Provider))
+	record.Set("audio_ms", entry.Duration.Milliseconds())
+	record.Set("billable_seconds", billable)
+	record.Set("cost_usd", cost)
+	record.Set("transcript", entry.Transcript)
+	record.Set("app", entry.App)
+	record.Set("owner", entry.Owner)
+	record.Set("replay", entry.Replay)
+	if err := m.app.Save(record); err != nil {
+		return fmt.Errorf("save usage: %w", err)
+	}
+
+	m.addSpend(cost, time.Now())
+	return nil
+}
+
+// OverBudget reports whether the daily or monthly budget is spent.
+func (m *Meter) OverBudget() bool {
+	if m.budget.DailyUSD <= 0 && m.budget.MonthlyUSD <= 0 {
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.refresh(time.Now())
+	return m.state.dayOver || m.state.monthOver
+}
+
+// addSpend adds a request's cost to the cached spend and raises alerts.
+func (m *Meter) addSpend(cost float64, now time.Time) {
+	if m.budget.DailyUSD <= 0 && m.budget.MonthlyUSD <= 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// A fresh period or first use reloads spend from the database, which already includes this request
+	if m.refresh(now) {
+		return
+	}
+	m.state.dailySpend += cost
+	m.state.monthSpend += cost
+	m.checkThresholds()
+}
+
+// refresh reloads spend when the day or month changed since the last check.
+// It reports whether spend was reloaded. The caller must hold m.mu.
+func (m *Meter) refresh(now time.Time) bool {
+	now = now.UTC()
+	day, month := now.Format(time.DateOnly), now.Format("2006-01")
+	if m.loaded && m.state.day == day && m.state.month == month {
+		return false
+	}
+
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	dailySpend, err := m.spendSince(dayStart)
+	if err != nil {
+		logger.Error("Failed to load daily spend", "error", err)
+	}
+	monthSpend, err := m.spendSince(monthStart)
+	if err != nil {
+		logger.Error("Failed to load monthly spend", "error", err)
+	}
+
+	m.state = budgetState{day: day, month: month, dailySpend: dailySpend, monthSpend: monthSpend}
+	m.loaded = true
+	m.checkThresholds()
+	return true
+}
+
+// checkThresholds flags spent budgets and logs when one is first crossed.
+// The caller must hold m.mu.
+func (m *Meter) checkThresholds() {
+	if m.budget.DailyUSD > 0 && !m.state.dayOver && m.state.dailySpend >= m.budget.DailyUSD {
+		m.state.dayOver = true
+		logger.Error("Daily transcription budget exceeded, trying the cheapest provider first",
+			"spend_usd", m.state.dailySpend, "budget_usd", m.budget.DailyUSD, "day", m.state.day)
+	}
+	if m.budget.MonthlyUSD > 0 && !m.state.monthOver && m.state.monthSpend >= m.budget.MonthlyUSD {
+		m.state.monthOver = true
+		logger.Error("Monthly transcription budget exceeded, trying the cheapest provider first",
+			"spend_usd", m.state.monthSpend, "budget_usd", m.budget.MonthlyUSD, "month", m.state.month)
+	}
+}
+
+// spendSince sums the estimated cost of usage created at or after t.
+func (m *Meter) spendSince(t time.Time) (float64, error) {
+	var row struct {
+		Total float64 `db:"total"`
+	}
+	err := m.app.DB().
+		Select("COALESCE(SUM(cost_usd), 0) AS total").
+		From(Collection).
+		Where(dbx.NewExp("created >= {:since}", dbx.Params{"since": t.UTC().Format(types.DefaultDateLayout)})).
+		One(&row)
+	return row.Total, err
+}
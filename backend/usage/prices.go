@@ -0,0 +1,85 @@
+// Package usage meters billable audio per request, estimates what each
+// provider charges for it and watches spend against optional budgets.
+package usage
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"silence-backend/transcription"
+)
+
+// Price is how a provider bills one request. Audio is rounded up to
+// IncrementSeconds and to at least MinimumSeconds before PerMinute applies.
+type Price struct {
+	PerMinute        float64 `json:"per_minute"`        // USD per minute of billable audio
+	PerRequest       float64 `json:"per_request"`       // USD per request, on top of PerMinute
+	MinimumSeconds   float64 `json:"minimum_seconds"`   // Shortest billable request
+	IncrementSeconds float64 `json:"increment_seconds"` // Billing granularity, 0 for exact
+}
+
+// PriceTable maps providers to their prices. Providers missing from the table cost nothing.
+type PriceTable map[transcription.ProviderName]Price
+
+// DefaultPrices are rough list prices. ElevenLabs bills audio by the hour;
+// Chutes bills compute, estimated here per minute of audio.
+func DefaultPrices() PriceTable {
+	return PriceTable{
+		transcription.ProviderElevenLabs: {PerMinute: 0.40 / 60, IncrementSeconds: 1},
+		transcription.ProviderChutes:     {PerMinute: 0.0005},
+	}
+}
+
+// ParsePriceTable reads a JSON object of prices by provider, e.g.
+// {"elevenlabs": {"per_minute": 0.0067}, "chutes": {"per_request": 0.001}}.
+// Listed providers replace their default price; an empty spec keeps the defaults.
+func ParsePriceTable(spec string) (PriceTable, error) {
+	prices := DefaultPrices()
+	if spec == "" {
+		return prices, nil
+	}
+
+	var overrides map[transcription.ProviderName]Price
+	if err := json.Unmarshal([]byte(spec), &overrides); err != nil {
+		return nil, fmt.Errorf("invalid price table: %w", err)
+	}
+	for name, price := range overrides {
+		if price.PerMinute < 0 || price.PerRequest < 0 || price.MinimumSeconds < 0 || price.IncrementSeconds < 0 {
+			return nil, fmt.Errorf("invalid price for %s: values must not be negative", name)
+		}
+		prices[name] = price
+	}
+	return prices, nil
+}
+
+// BillableSeconds rounds audio the way the provider bills it.
+func (p Price) BillableSeconds(d time.Duration) float64 {
+	seconds := max(d.Seconds(), p.MinimumSeconds)
+	if p.IncrementSeconds > 0 {
+		seconds = math.Ceil(seconds/p.IncrementSeconds) * p.IncrementSeconds
+	}
+	return seconds
+}
+
+// Cost estimates the USD cost of one request with the given billable audio.
+func (p Price) Cost(billableSeconds float64) float64 {
+	return p.PerRequest + p.PerMinute*billableSeconds/60
+}
+
+// ByPrice orders providers from the lowest cost for a minute of audio to the
+// highest. Providers that cost the same keep their order.
+func (t PriceTable) ByPrice(candidates []transcription.ProviderName) []transcription.ProviderName {
+	cost := func(name transcription.ProviderName) float64 {
+		price := t[name]
+		return price.Cost(price.BillableSeconds(time.Minute))
+	}
+	sorted := slices.Clone(candidates)
+	slices.SortStableFunc(sorted, func(a, b transcription.ProviderName) int {
+		return cmp.Compare(cost(a), cost(b))
+	})
+	return sorted
+}
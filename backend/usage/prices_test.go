@@ -0,0 +1,72 @@
+package usage
+
+import (
+	"slices"
+	"testing"
+
+	"silence-backend/transcription"
+)
+
+func TestByPrice(t *testing.T) {
+	const (
+		elevenlabs = transcription.ProviderElevenLabs
+		chutes     = transcription.ProviderChutes
+		other      = transcription.ProviderName("other")
+	)
+
+	tests := []struct {
+		name       string
+		prices     PriceTable
+		candidates []transcription.ProviderName
+		want       []transcription.ProviderName
+	}{
+		{
+			name:       "default prices put chutes first",
+			prices:     DefaultPrices(),
+			candidates: []transcription.ProviderName{chutes, elevenlabs},
+			want:       []transcription.ProviderName{chutes, elevenlabs},
+		},
+		{
+			name:       "keeps every provider as a fallback",
+			prices:     DefaultPrices(),
+			candidates: []transcription.ProviderName{elevenlabs, chutes},
+			want:       []transcription.ProviderName{chutes, elevenlabs},
+		},
+		{
+			name:       "per-request fees count",
+			prices:     PriceTable{elevenlabs: {PerMinute: 0.001}, chutes: {PerMinute: 0.0005, PerRequest: 0.01}},
+			candidates: []transcription.ProviderName{chutes, elevenlabs},
+			want:       []transcription.ProviderName{elevenlabs, chutes},
+		},
+		{
+			name:       "minimum billing counts",
+			prices:     PriceTable{elevenlabs: {PerMinute: 0.01}, chutes: {PerMinute: 0.005, MinimumSeconds: 300}},
+			candidates: []transcription.ProviderName{chutes, elevenlabs},
+			want:       []transcription.ProviderName{elevenlabs, chutes},
+		},
+		{
+			name:       "unpriced providers are free",
+			prices:     DefaultPrices(),
+			candidates: []transcription.ProviderName{elevenlabs, chutes, other},
+			want:       []transcription.ProviderName{other, chutes, elevenlabs},
+		},
+		{
+			name:       "ties keep their order",
+			prices:     PriceTable{},
+			candidates: []transcription.ProviderName{elevenlabs, chutes},
+			want:       []transcription.ProviderName{elevenlabs, chutes},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates := slices.Clone(tt.candidates)
+			if got := tt.prices.ByPrice(candidates); !slices.Equal(got, tt.want) {
+				t.Errorf("ByPrice(%v) = %v, want %v", tt.candidates, got, tt.want)
+			}
+			if !slices.Equal(candidates, tt.candidates) {
+				t.Errorf("ByPrice modified its argument: %v", candidates)
+			}
+		})
+	}
+}
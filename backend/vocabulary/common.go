@@ -0,0 +1,77 @@
+package vocabulary
+
+import "strings"
+
+// commonWords are everyday English and Russian words. A provider spelling one
+// of them most likely heard it right, so they are never snapped to a term that
+// merely sounds or looks like them: "cloud" stays "cloud" next to "Claude".
+var commonWords = wordSet(`
+	a about above across act action actually add after again against age ago agree ahead air
+	all allow almost alone along already also although always am among amount and animal
+	another answer any anyone anything appear apply area arm around arrive art as ask at
+	attack away baby back bad bag ball bank bar base be bear beat beautiful because become bed
+	been before begin behind believe best better between big bill bit black blood blue board
+	boat body book born both bottom box boy break bring brother build building business but
+	buy by call came can car card care carry case cat catch cause cell center central certain
+	chair chance change charge check child choice choose church city class clear close cloud
+	clouds coat code cold college color come common company compare complete computer consider
+	continue control cook cool copy corner cost could country couple course court cover create
+	crowd cup cut dark data date daughter day dead deal dear death decide deep degree design
+	desk detail develop did die difference different dinner direction discuss do doctor does
+	dog dollar done door down draw dream dress drink drive drop during each early earth east
+	easy eat edge effect eight either else end energy enjoy enough enter even evening event
+	ever every everyone everything exactly example eye face fact fail fall family far farm
+	fast father fear feel feet few field fight figure file fill film final find fine finger
+	finish fire first fish five floor fly follow food foot for force forget form forward four
+	free friend from front full fun game garden gas gave get girl give glass go god gold gone
+	good got government great green ground group grow guess gun guy hair half hall hand hang
+	happen happy hard has hat have he head health hear heart heat heavy held hello help her
+	here herself high hill him himself his history hit hold hole home hope horse hospital hot
+	hotel hour house how however huge human hundred husband i idea if image important in
+	inside instead interest into is issue it item its job join just keep key kid kill kind
+	king kitchen knew know land language large last late later laugh law lay lead learn least
+	leave left leg less let letter level lie life light like line list listen little live load
+	local long look lose lost lot loud love low machine made mail main make man many map mark
+	market matter may maybe me mean meet meeting member men message middle might mile milk
+	mind minute miss model moment money month more morning most mother mouth move movie much
+	music must my myself name near need never new news next nice night nine no none noon nor
+	north not note nothing notice now number of off office often oh oil ok okay old on once
+	one only open or order other our out outside over own page pain paper parent park part
+	party pass past path pay people per perhaps person phone pick picture piece place plan
+	plant play please point police poor power present pretty price print problem program pull
+	push put question quick quite race rain raise ran rather reach read ready real really
+	reason receive record red remember report rest result return right ring rise river road
+	rock role room round rule run safe said same save saw say school score sea season seat
+	second see seem sell send sense serve set seven several shall share she ship shop short
+	should show side sign simple since sing sister sit six size skin sky sleep slow small
+	smile so social some someone something sometimes son song soon sorry sort sound south
+	space speak special stage stand star start state stay step still stock stop store story
+	street strong student study stuff such summer sun sure system table take talk task tasks
+	team tell ten test than thank that the their them then there these they thing things think
+	third this those though thought thousand three through time to today together told
+	tomorrow tonight too took top total touch toward town track trade train travel tree trip
+	true try turn two type under understand until up upon us use used usual very voice wait
+	walk wall want war warm was wash watch water way we wear weather week weight well went
+	were west what when where whether which while white who whole whom whose why wide wife
+	will win wind window winter wish with within without woman women wonder word words work
+	world worry would write wrong yard yeah year yes yesterday yet you young your yourself
+
+	а без большой брат бы был была были было быть в весь вечер во вода вопрос вот время все
+	всё вчера вы где глаз говорить год голова город да даже два дверь дело день деньги для до
+	должен дом дорога дочь друг другой его если ещё её же жена жизнь за завтра здесь знать и
+	идти из или иметь их к как какой книга когда который кто ладно ли лицо люди мама машина
+	место месяц минута мир можно мой мочь муж мы на надо наш не неделя нет ничего но новый
+	ночь ну о один окно он она они от ответ очень папа первый письмо по под пожалуйста после
+	потом при работа раз рука с сам самый свой себя сегодня сейчас сестра сказать слово со
+	спасибо стать стол страна сын так такой там то только тот тут ты у уже утро хорошо хотеть
+	час человек что чтобы это этот я
+`)
+
+// wordSet builds a set of the whitespace-separated words of s.
+func wordSet(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(s) {
+		set[w] = true
+	}
+	return set
+}
@@ -0,0 +1,319 @@
+// Package vocabulary biases transcripts towards custom terms: product names,
+// colleagues and jargon that providers tend to misspell. Providers that support
+// biasing get the terms with the request; text from the others is corrected
+// afterwards by snapping close spellings to the terms.
+package vocabulary
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Limits of a vocabulary, the strictest of the providers'.
+const (
+	MaxTerms      = 100
+	MaxTermLength = 50
+)
+
+// Parse reads terms from form values. Each value may hold several terms
+// separated by commas or newlines.
+func Parse(values []string) []string {
+	var terms []string
+	for _, value := range values {
+		terms = append(terms, strings.FieldsFunc(value, func(r rune) bool {
+			return r == ',' || r == '\n' || r == '\r'
+		})...)
+	}
+	return Merge(terms)
+}
+
+// Merge combines vocabularies, trimming terms and dropping blanks and
+// case-insensitive duplicates. The first spelling of a term wins.
+func Merge(lists ...[]string) []string {
+	var merged []string
+	seen := make(map[string]bool)
+	for _, list := range lists {
+		for _, term := range list {
+			term = strings.Join(strings.Fields(term), " ")
+			key := strings.ToLower(term)
+			if term == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, term)
+		}
+	}
+	return merged
+}
+
+// Validate checks a vocabulary against the limits.
+func Validate(terms []string) error {
+	if len(terms) > MaxTerms {
+		return fmt.Errorf("at most %d vocabulary terms are allowed, got %d", MaxTerms, len(terms))
+	}
+	for _, term := range terms {
+		if utf8.RuneCountInString(term) > MaxTermLength {
+			return fmt.Errorf("vocabulary term %q is longer than %d characters", term, MaxTermLength)
+		}
+	}
+	return nil
+}
+
+// Correction is a span of a transcript that was replaced by a vocabulary term.
+type Correction struct {
+	From string `json:"from" example:"cooper netties"`
+	To   string `json:"to" example:"Kubernetes"`
+}
+
+// Thresholds for snapping a span to a term.
+const (
+	minFuzzyLength   = 4    // Shorter terms only get their spelling and casing fixed
+	minSimilarity    = 0.8  // Edit similarity that alone is close enough
+	minSoundsLikeSim = 0.5  // Edit similarity still needed when the spans sound alike
+	soundsLikeScore  = 0.85 // Score of a span that sounds like the term
+)
+
+// Correct replaces spans of text that are spelled or sound like a vocabulary
+// term with the term. Spans of one word more or less than the term are
+// considered too, as providers often split or join unfamiliar words. Spans
+// never cross punctuation.
+func Correct(text string, terms []string) (string, []Correction) {
+	if len(terms) == 0 {
+		return text, nil
+	}
+
+	type candidate struct {
+		term  string
+		words int
+		norm  string
+		sound string
+	}
+	candidates := make([]candidate, 0, len(terms))
+	for _, term := range terms {
+		norm := normalize(term)
+		if norm == "" {
+			continue
+		}
+		candidates = append(candidates, candidate{term, len(strings.Fields(term)), norm, soundKey(norm)})
+	}
+
+	words := splitWords(text)
+	var sb strings.Builder
+	var corrections []Correction
+	last := 0
+
+	for i := 0; i < len(words); {
+		bestScore, bestEnd, bestTerm := 0.0, 0, ""
+		for _, c := range candidates {
+			for n := max(1, c.words-1); n <= c.words+1 && i+n <= len(words); n++ {
+				if !adjacent(text, words[i:i+n]) {
+					break
+				}
+				score := spanScore(text, words[i:i+n], c.norm, c.sound)
+				// A span whose first or last word doesn't help the match leaves that word alone
+				if n > 1 && (spanScore(text, words[i+1:i+n], c.norm, c.sound) >= score ||
+					spanScore(text, words[i:i+n-1], c.norm, c.sound) >= score) {
+					continue
+				}
+				if score > bestScore {
+					bestScore, bestEnd, bestTerm = score, i+n, c.term
+				}
+			}
+		}
+
+		if bestScore == 0 {
+			i++
+			continue
+		}
+
+		start, end := words[i].start, words[bestEnd-1].end
+		span := text[start:end]
+		_, suffix := splitPossessive(span)
+		if replacement := bestTerm + suffix; replacement != span {
+			sb.WriteString(text[last:start])
+			sb.WriteString(replacement)
+			last = end
+			corrections = append(corrections, Correction{From: span, To: replacement})
+		}
+		i = bestEnd
+	}
+
+	if len(corrections) == 0 {
+		return text, nil
+	}
+	sb.WriteString(text[last:])
+	return sb.String(), corrections
+}
+
+// spanScore is the similarity of the text spanned by words to a term. Spans
+// of common words only match a term spelled the same way.
+func spanScore(text string, words []word, term, termSound string) float64 {
+	stem, _ := splitPossessive(text[words[0].start:words[len(words)-1].end])
+	score := similarity(normalize(stem), term, termSound)
+	if score < 1 && allCommon(text, words) {
+		return 0
+	}
+	return score
+}
+
+// allCommon reports whether every word spanned is a common word.
+func allCommon(text string, words []word) bool {
+	for _, w := range words {
+		stem, _ := splitPossessive(text[w.start:w.end])
+		if !commonWords[strings.ToLower(stem)] {
+			return false
+		}
+	}
+	return true
+}
+
+// Similarity scores how close a word or phrase is to a term, ignoring case,
+// spacing and punctuation: 1 for the same spelling, 0 when it isn't close
+// enough to be corrected to the term.
+func Similarity(span, term string) float64 {
+	norm := normalize(term)
+	return similarity(normalize(span), norm, soundKey(norm))
+}
+
+// similarity scores how close a normalized span is to a normalized term, from
+// 0 (no match) to 1 (same spelling up to case, spacing and punctuation).
+func similarity(span, term, termSound string) float64 {
+	if span == "" {
+		return 0
+	}
+	if span == term {
+		return 1
+	}
+	if utf8.RuneCountInString(term) < minFuzzyLength {
+		return 0
+	}
+
+	a, b := []rune(span), []rune(term)
+	sim := 1 - float64(levenshtein(a, b))/float64(max(len(a), len(b)))
+	switch {
+	case sim >= minSimilarity:
+		return sim
+	case sim >= minSoundsLikeSim && soundKey(span) == termSound:
+		return soundsLikeScore
+	}
+	return 0
+}
+
+// word is the byte range of a word in a text.
+type word struct{ start, end int }
+
+// splitWords finds the words of text: runs of letters and digits, with
+// apostrophes and hyphens inside them.
+func splitWords(text string) []word {
+	var words []word
+	start := -1
+	for i, r := range text {
+		inner := r == '\'' || r == '’' || r == '-'
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || (inner && start >= 0) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			words = append(words, word{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		words = append(words, word{start, len(text)})
+	}
+
+	// Trailing apostrophes and hyphens belong to the punctuation, not the word
+	for i := range words {
+		words[i].end = words[i].start + len(strings.TrimRight(text[words[i].start:words[i].end], "'’-"))
+	}
+	return words
+}
+
+// adjacent reports whether consecutive words are only separated by whitespace.
+func adjacent(text string, words []word) bool {
+	for i := 1; i < len(words); i++ {
+		if strings.TrimSpace(text[words[i-1].end:words[i].start]) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// splitPossessive separates an English possessive ending from a span, so
+// "acme's" can become "ACME's".
+func splitPossessive(span string) (stem, suffix string) {
+	for _, s := range []string{"'s", "’s"} {
+		if len(span) > len(s) && strings.HasSuffix(strings.ToLower(span), s) {
+			return span[:len(span)-len(s)], span[len(span)-len(s):]
+		}
+	}
+	return span, ""
+}
+
+// normalize lowercases s and drops everything but letters and digits.
+func normalize(s string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// soundSpellings folds spellings of the same sound, for English and Russian.
+var soundSpellings = strings.NewReplacer(
+	"sch", "sk", "ph", "f", "ck", "k", "gh", "g", "kn", "n", "wr", "r",
+	"sh", "s", "ch", "k", "th", "t", "qu", "kv", "x", "ks",
+)
+
+// soundFolds merges letters that sound alike, voiced consonants with their
+// voiceless pairs included.
+var soundFolds = map[rune]rune{
+	'c': 'k', 'q': 'k', 'g': 'k', 'z': 's', 'b': 'p', 'd': 't', 'v': 'f', 'w': 'f', 'j': 'i', 'y': 'i',
+	'б': 'п', 'в': 'ф', 'г': 'к', 'д': 'т', 'ж': 'ш', 'з': 'с', 'щ': 'ш', 'ъ': 0, 'ь': 0,
+}
+
+// soundKey is a rough phonetic key of a normalized word: alike-sounding
+// spellings and consonants are folded, vowels after the first letter and
+// repeated letters are dropped.
+func soundKey(norm string) string {
+	var sb strings.Builder
+	var prev rune
+	for i, r := range []rune(soundSpellings.Replace(norm)) {
+		if folded, ok := soundFolds[r]; ok {
+			r = folded
+		}
+		if r == 0 || r == prev || (i > 0 && strings.ContainsRune("aeiouаеёиоуыэюя", r)) {
+			continue
+		}
+		sb.WriteRune(r)
+		prev = r
+	}
+	return sb.String()
+}
+
+// levenshtein is the edit distance between two rune slices.
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,109 @@
+package vocabulary
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCorrect(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		terms []string
+		want  string
+		fixes []Correction
+	}{
+		{
+			name:  "split words that sound like a term",
+			text:  "we deploy it on cooper netties now",
+			terms: []string{"Kubernetes"},
+			want:  "we deploy it on Kubernetes now",
+			fixes: []Correction{{From: "cooper netties", To: "Kubernetes"}},
+		},
+		{
+			name:  "misspelling",
+			text:  "ask Grigoriy about it",
+			terms: []string{"Grigory"},
+			want:  "ask Grigory about it",
+			fixes: []Correction{{From: "Grigoriy", To: "Grigory"}},
+		},
+		{
+			name:  "casing and possessive",
+			text:  "acme's roadmap",
+			terms: []string{"ACME"},
+			want:  "ACME's roadmap",
+			fixes: []Correction{{From: "acme's", To: "ACME's"}},
+		},
+		{
+			name:  "common word that sounds like a term",
+			text:  "we talked about the cloud",
+			terms: []string{"Claude"},
+			want:  "we talked about the cloud",
+		},
+		{
+			name:  "common word that is spelled like a term",
+			text:  "the claude model",
+			terms: []string{"Claude"},
+			want:  "the Claude model",
+			fixes: []Correction{{From: "claude", To: "Claude"}},
+		},
+		{
+			name:  "common Russian word",
+			text:  "завтра в городе",
+			terms: []string{"Завтраков"},
+			want:  "завтра в городе",
+		},
+		{
+			name:  "short terms only fix spelling",
+			text:  "the api and the app",
+			terms: []string{"API"},
+			want:  "the API and the app",
+			fixes: []Correction{{From: "api", To: "API"}},
+		},
+		{
+			name:  "spans don't cross punctuation",
+			text:  "cooper, netties",
+			terms: []string{"Kubernetes"},
+			want:  "cooper, netties",
+		},
+		{
+			name: "no terms",
+			text: "nothing to do",
+			want: "nothing to do",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, fixes := Correct(tt.text, tt.terms)
+			if got != tt.want {
+				t.Errorf("Correct(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+			if !slices.Equal(fixes, tt.fixes) {
+				t.Errorf("corrections = %v, want %v", fixes, tt.fixes)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   []string
+	}{
+		{"commas and newlines", []string{"Kubernetes, ACME\nGrigory"}, []string{"Kubernetes", "ACME", "Grigory"}},
+		{"several values", []string{"ACME", "Kubernetes"}, []string{"ACME", "Kubernetes"}},
+		{"blanks and spaces", []string{" , New   York ,,"}, []string{"New York"}},
+		{"first spelling wins", []string{"ACME, acme, Acme"}, []string{"ACME"}},
+		{"nothing", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.values); !slices.Equal(got, tt.want) {
+				t.Errorf("Parse(%q) = %q, want %q", tt.values, got, tt.want)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import 'package:record/record.dart';
 import 'package:permission_handler/permission_handler.dart';
 import 'package:path_provider/path_provider.dart';
 import 'package:http/http.dart' as http;
+import 'package:pocketbase/pocketbase.dart';
 
 void main() {
   runApp(const SilenceApp());
@@ -41,8 +42,17 @@ class _AudioRecorderScreenState extends State<AudioRecorderScreen> {
   bool _isProcessing = false;
   String? _transcriptionResult;
   final String _backendUrl = const String.fromEnvironment('BACKEND_URL', defaultValue: 'http://localhost:8090');
+  // Users sign in with their PocketBase account; app API keys are for
+  // headless clients such as silence-cli and are never built into the app.
+  late final PocketBase _pb = PocketBase(_backendUrl);
+  final _emailController = TextEditingController();
+  final _passwordController = TextEditingController();
+  bool _isSigningIn = false;
+  String? _signInError;
   List<String> _exampleFiles = [];
 
+  bool get _isSignedIn => _pb.authStore.isValid;
+
   @override
   void initState() {
     super.initState();
@@ -76,9 +86,56 @@ class _AudioRecorderScreenState extends State<AudioRecorderScreen> {
   @override
   void dispose() {
     _audioRecorder.dispose();
+    _emailController.dispose();
+    _passwordController.dispose();
     super.dispose();
   }
 
+  Future<void> _signIn() async {
+    setState(() {
+      _isSigningIn = true;
+      _signInError = null;
+    });
+
+    try {
+      await _pb.collection('users').authWithPassword(
+        _emailController.text.trim(),
+        _passwordController.text,
+      );
+      _passwordController.clear();
+      setState(() {
+        _isSigningIn = false;
+      });
+    } on ClientException catch (e) {
+      setState(() {
+        _isSigningIn = false;
+        _signInError = e.response['message']?.toString() ?? 'Sign-in failed';
+      });
+    }
+  }
+
+  void _signOut() {
+    _pb.authStore.clear();
+    setState(() {
+      _transcriptionResult = null;
+    });
+  }
+
+  // Sends the signed-in user's token, which the backend checks like any
+  // PocketBase auth token.
+  void _authorize(http.BaseRequest request) {
+    request.headers['Authorization'] = 'Bearer ${_pb.authStore.token}';
+  }
+
+  // Describes a failed request. A rejected token signs the user out.
+  String _httpError(int statusCode) {
+    if (statusCode == 401) {
+      _pb.authStore.clear();
+      return 'Error: session expired, sign in again';
+    }
+    return 'Error: HTTP $statusCode';
+  }
+
   Future<void> _requestPermissions() async {
     if (!kIsWeb && Platform.isLinux) {
       return;
@@ -131,6 +188,7 @@ class _AudioRecorderScreenState extends State<AudioRecorderScreen> {
         'POST',
         Uri.parse('$_backendUrl/speak'),
       );
+      _authorize(request);
 
       request.files.add(
         await http.MultipartFile.fromPath('audio', filePath),
@@ -148,7 +206,7 @@ class _AudioRecorderScreenState extends State<AudioRecorderScreen> {
       } else {
         setState(() {
           _isProcessing = false;
-          _transcriptionResult = 'Error: HTTP ${streamedResponse.statusCode}';
+          _transcriptionResult = _httpError(streamedResponse.statusCode);
         });
       }
     } catch (e) {
@@ -174,6 +232,7 @@ class _AudioRecorderScreenState extends State<AudioRecorderScreen> {
         'POST',
         Uri.parse('$_backendUrl/speak'),
       );
+      _authorize(request);
 
       request.files.add(
         http.MultipartFile.fromBytes(
@@ -195,7 +254,7 @@ class _AudioRecorderScreenState extends State<AudioRecorderScreen> {
       } else {
         setState(() {
           _isProcessing = false;
-          _transcriptionResult = 'Error: HTTP ${streamedResponse.statusCode}';
+          _transcriptionResult = _httpError(streamedResponse.statusCode);
         });
       }
     } catch (e) {
@@ -212,11 +271,63 @@ class _AudioRecorderScreenState extends State<AudioRecorderScreen> {
       appBar: AppBar(
         title: const Text('Silence Audio Recorder'),
         backgroundColor: Theme.of(context).colorScheme.inversePrimary,
+        actions: [
+          if (_isSignedIn)
+            IconButton(
+              icon: const Icon(Icons.logout),
+              tooltip: 'Sign out',
+              onPressed: _signOut,
+            ),
+        ],
       ),
       body: Padding(
         padding: const EdgeInsets.all(16.0),
         child: Column(
           children: [
+            if (!_isSignedIn) ...[
+              Card(
+                child: Padding(
+                  padding: const EdgeInsets.all(16.0),
+                  child: Column(
+                    crossAxisAlignment: CrossAxisAlignment.stretch,
+                    children: [
+                      Text(
+                        'Sign in to transcribe',
+                        style: Theme.of(context).textTheme.titleLarge,
+                      ),
+                      const SizedBox(height: 16),
+                      TextField(
+                        controller: _emailController,
+                        decoration: const InputDecoration(labelText: 'Email'),
+                        keyboardType: TextInputType.emailAddress,
+                        autofillHints: const [AutofillHints.email],
+                      ),
+                      TextField(
+                        controller: _passwordController,
+                        decoration: const InputDecoration(labelText: 'Password'),
+                        obscureText: true,
+                        autofillHints: const [AutofillHints.password],
+                        onSubmitted: (_) => _signIn(),
+                      ),
+                      if (_signInError != null)
+                        Padding(
+                          padding: const EdgeInsets.only(top: 8.0),
+                          child: Text(
+                            _signInError!,
+                            style: TextStyle(color: Theme.of(context).colorScheme.error),
+                          ),
+                        ),
+                      const SizedBox(height: 16),
+                      ElevatedButton(
+                        onPressed: _isSigningIn ? null : _signIn,
+                        child: const Text('Sign In'),
+                      ),
+                    ],
+                  ),
+                ),
+              ),
+              const SizedBox(height: 16),
+            ],
             Card(
               child: Padding(
                 padding: const EdgeInsets.all(16.0),
@@ -238,7 +349,7 @@ class _AudioRecorderScreenState extends State<AudioRecorderScreen> {
                     ),
                     const SizedBox(height: 16),
                     ElevatedButton(
-                      onPressed: _isProcessing
+                      onPressed: _isProcessing || !_isSignedIn
                           ? null
                           : _isRecording
                               ? _stopRecording
@@ -280,7 +391,7 @@ class _AudioRecorderScreenState extends State<AudioRecorderScreen> {
                               child: CircularProgressIndicator(strokeWidth: 2),
                             )
                           : const Icon(Icons.play_arrow),
-                      onTap: _isProcessing ? null : () => _sendExampleToBackend(fileName),
+                      onTap: _isProcessing || !_isSignedIn ? null : () => _sendExampleToBackend(fileName),
                     )),
                 ],
               ),
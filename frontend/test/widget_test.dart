@@ -17,5 +17,8 @@ void main() {
     // Verify that the app loads with correct title
     expect(find.text('Silence Audio Recorder'), findsOneWidget);
     expect(find.text('Ready to record'), findsOneWidget);
+
+    // Recording needs a signed-in user
+    expect(find.text('Sign in to transcribe'), findsOneWidget);
   });
 }